@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/auth"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/repository"
+)
+
+const apiKeyUsage = "usage: apikey create [-roles editor,admin] <name> | list | revoke <name>"
+
+func runAPIKey(ctx context.Context, db *sql.DB, args []string) error {
+	if len(args) == 0 {
+		return errors.New(apiKeyUsage)
+	}
+
+	repo := &repository.APIKeyRepositoryDB{DB: db}
+
+	switch args[0] {
+	case "create":
+		return createAPIKey(ctx, repo, args[1:])
+	case "list":
+		return printAPIKeys(ctx, repo)
+	case "revoke":
+		if len(args) != 2 {
+			return errors.New(apiKeyUsage)
+		}
+		apiKey, err := repo.RevokeAPIKey(ctx, args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("revoked %s (%s...)\n", apiKey.Name, apiKey.Prefix)
+		return nil
+	default:
+		return errors.New(apiKeyUsage)
+	}
+}
+
+func createAPIKey(ctx context.Context, repo *repository.APIKeyRepositoryDB, args []string) error {
+	flags := flag.NewFlagSet("apikey create", flag.ContinueOnError)
+	roles := flags.String("roles", "", "comma-separated roles of the caller: editor, admin")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New(apiKeyUsage)
+	}
+
+	var parsedRoles []auth.Role
+	for _, role := range strings.Split(*roles, ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			parsedRoles = append(parsedRoles, auth.Role(role))
+		}
+	}
+
+	key, err := auth.NewAPIKey()
+	if err != nil {
+		return err
+	}
+
+	apiKey, err := repo.CreateAPIKey(ctx, flags.Arg(0), key, parsedRoles)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("created a key for %s, send it in the X-API-Key header; it is not shown again:\n%s\n", apiKey.Name, key)
+	return nil
+}
+
+func printAPIKeys(ctx context.Context, repo *repository.APIKeyRepositoryDB) error {
+	apiKeys, err := repo.GetAPIKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tKEY\tROLES\tCREATED AT\tLAST USED AT\tREVOKED AT")
+	for _, apiKey := range apiKeys {
+		roles := make([]string, len(apiKey.Roles))
+		for i, role := range apiKey.Roles {
+			roles[i] = string(role)
+		}
+		fmt.Fprintf(w, "%s\t%s...\t%s\t%s\t%s\t%s\n", apiKey.Name, apiKey.Prefix, strings.Join(roles, ","),
+			apiKey.CreatedAt.Format("2006-01-02 15:04:05"), formatOptionalTime(apiKey.LastUsedAt), formatOptionalTime(apiKey.RevokedAt))
+	}
+
+	return w.Flush()
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/auth"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/exporter"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/repository"
+)
+
+func runExport(ctx context.Context, db *sql.DB, args []string) (err error) {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := flags.String("format", string(exporter.FormatJSONL), "output format: jsonl, csv or anki")
+	output := flags.String("o", "-", "output file, - for stdout")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return errors.New("usage: export [-format jsonl|csv|anki] [-o file]")
+	}
+
+	exportFormat := exporter.Format(*format)
+	if !exportFormat.IsValid() {
+		return fmt.Errorf("unknown format %q, use jsonl, csv or anki", *format)
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "-" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+		out = file
+	}
+
+	// Whoever runs the command has the database at hand anyway, so it dumps the
+	// whole dictionary the way an admin's export does.
+	ctx = auth.WithPrincipal(ctx, &auth.Principal{Name: "export", Roles: []auth.Role{auth.RoleAdmin}})
+
+	return exporter.Export(ctx, &repository.ExportRepositoryDB{DB: db}, out, exportFormat)
+}
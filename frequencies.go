@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/frequency"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/repository"
+)
+
+func runFrequencies(ctx context.Context, db *sql.DB, args []string) error {
+	flags := flag.NewFlagSet("frequencies", flag.ContinueOnError)
+	language := flags.String("language", "pl", "language of the list; lexemes and translations in it are ranked")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("usage: frequencies [-language pl] <file|->")
+	}
+
+	var input io.Reader = os.Stdin
+	if path := flags.Arg(0); path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+
+	words, err := frequency.Load(input)
+	if err != nil {
+		return err
+	}
+
+	report, err := (&repository.FrequencyRepositoryDB{DB: db}).LoadFrequencyRanks(ctx, *language, words)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("ranked %d lexemes and %d translations from %d words\n", report.Lexemes, report.Translations, len(words))
+	return nil
+}
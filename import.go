@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/importer"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/pronunciation"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/repository"
+)
+
+func runImport(ctx context.Context, db *sql.DB, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := flags.String("format", "", "input format: jsonl or csv (defaults to the file extension)")
+	batchSize := flags.Int("batch-size", importer.DefaultBatchSize, "records per transaction")
+	owner := flags.String("owner", "", "user who owns the imported entries; private entries need one")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("usage: import [-format jsonl|csv] [-batch-size n] [-owner name] <file|->")
+	}
+	if *owner != "" {
+		ctx = repository.WithOwner(ctx, *owner)
+	}
+
+	path := flags.Arg(0)
+	if *format == "" {
+		*format = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+
+	importFormat := model.ImportFormat(strings.ToUpper(*format))
+	if !importFormat.IsValid() {
+		return fmt.Errorf("unknown format %q, use -format jsonl or -format csv", *format)
+	}
+
+	var input io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+
+	importRepo := &repository.ImportRepositoryDB{DB: db}
+	if os.Getenv("GENERATE_PRONUNCIATION") != "false" {
+		importRepo.Pronunciation = pronunciation.Rules{}
+	}
+
+	report, err := importer.Import(ctx, importRepo, input, importFormat, *batchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range report.Rows {
+		if row.Status == model.ImportStatusFailed {
+			fmt.Printf("line %d: %s\n", row.Line, *row.Error)
+		}
+	}
+	fmt.Printf("created: %d, merged: %d, skipped: %d, failed: %d\n", report.Created, report.Merged, report.Skipped, report.Failed)
+
+	return nil
+}
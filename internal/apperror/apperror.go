@@ -0,0 +1,142 @@
+package apperror
+
+import "fmt"
+
+type Code string
+
+const (
+	CodeNotFound        Code = "NOT_FOUND"
+	CodeVersionConflict Code = "VERSION_CONFLICT"
+	CodeValidation      Code = "VALIDATION"
+	CodeDuplicate       Code = "DUPLICATE"
+	CodeForbidden       Code = "FORBIDDEN"
+	CodeInternal        Code = "INTERNAL"
+)
+
+// Error is implemented by every domain error. Extensions are exposed to
+// GraphQL clients next to the code, so they must never carry internal details.
+type Error interface {
+	error
+	Code() Code
+	Extensions() map[string]any
+}
+
+type NotFoundError struct {
+	Entity string
+	Field  string
+	Value  string
+}
+
+func (e *NotFoundError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("%s not found", e.Entity)
+	}
+	return fmt.Sprintf("%s with %s %q not found", e.Entity, e.Field, e.Value)
+}
+
+func (e *NotFoundError) Code() Code { return CodeNotFound }
+
+func (e *NotFoundError) Extensions() map[string]any {
+	extensions := map[string]any{"entity": e.Entity}
+	if e.Field != "" {
+		extensions["field"] = e.Field
+		extensions["value"] = e.Value
+	}
+	return extensions
+}
+
+type VersionConflictError struct {
+	Entity          string
+	ID              string
+	ExpectedVersion int
+	CurrentVersion  int
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("this %s has been modified by a different process", e.Entity)
+}
+
+func (e *VersionConflictError) Code() Code { return CodeVersionConflict }
+
+func (e *VersionConflictError) Extensions() map[string]any {
+	return map[string]any{
+		"entity":          e.Entity,
+		"id":              e.ID,
+		"expectedVersion": e.ExpectedVersion,
+		"currentVersion":  e.CurrentVersion,
+	}
+}
+
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string { return e.Message }
+
+func (e *ValidationError) Code() Code { return CodeValidation }
+
+func (e *ValidationError) Extensions() map[string]any {
+	if e.Field == "" {
+		return map[string]any{}
+	}
+	return map[string]any{"field": e.Field}
+}
+
+type DuplicateError struct {
+	Entity     string
+	Constraint string
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("%s already exists", e.Entity)
+}
+
+func (e *DuplicateError) Code() Code { return CodeDuplicate }
+
+func (e *DuplicateError) Extensions() map[string]any {
+	return map[string]any{"entity": e.Entity, "constraint": e.Constraint}
+}
+
+// ForbiddenError is returned when the caller may not do what it asked for.
+// RequiredRole is set when holding that role would have allowed it.
+type ForbiddenError struct {
+	Action       string
+	RequiredRole string
+}
+
+func (e *ForbiddenError) Error() string {
+	if e.RequiredRole != "" {
+		return fmt.Sprintf("not allowed to %s without the %s role", e.Action, e.RequiredRole)
+	}
+	return fmt.Sprintf("not allowed to %s", e.Action)
+}
+
+func (e *ForbiddenError) Code() Code { return CodeForbidden }
+
+func (e *ForbiddenError) Extensions() map[string]any {
+	extensions := map[string]any{"action": e.Action}
+	if e.RequiredRole != "" {
+		extensions["requiredRole"] = e.RequiredRole
+	}
+	return extensions
+}
+
+// InternalError wraps failures the client cannot act on. Its message is
+// replaced before it reaches the client; the wrapped error is only logged.
+type InternalError struct {
+	Err error
+}
+
+func (e *InternalError) Error() string {
+	if e.Err == nil {
+		return "internal error"
+	}
+	return e.Err.Error()
+}
+
+func (e *InternalError) Unwrap() error { return e.Err }
+
+func (e *InternalError) Code() Code { return CodeInternal }
+
+func (e *InternalError) Extensions() map[string]any { return map[string]any{} }
@@ -0,0 +1,60 @@
+package apperror
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const internalErrorMessage = "internal server error"
+
+// Presenter is a gqlgen ErrorPresenter that adds extensions.code to domain
+// errors and masks everything the repositories did not classify.
+func Presenter(ctx context.Context, err error) *gqlerror.Error {
+	presented := graphql.DefaultErrorPresenter(ctx, err)
+
+	var domainErr Error
+	if errors.As(err, &domainErr) {
+		if domainErr.Code() == CodeInternal {
+			return maskInternal(presented, err)
+		}
+
+		presented.Message = domainErr.Error()
+		presented.Extensions = domainErr.Extensions()
+		presented.Extensions["code"] = domainErr.Code()
+		return presented
+	}
+
+	// Errors raised by gqlgen itself (parsing, validation, null checks) do
+	// not wrap anything and are safe to show as they are.
+	var gqlErr *gqlerror.Error
+	if errors.As(err, &gqlErr) && gqlErr.Unwrap() == nil {
+		return presented
+	}
+
+	return maskInternal(presented, err)
+}
+
+func maskInternal(presented *gqlerror.Error, err error) *gqlerror.Error {
+	log.Printf("internal error at %v: %v", presented.Path, err)
+
+	presented.Message = internalErrorMessage
+	presented.Extensions = map[string]any{"code": CodeInternal}
+	return presented
+}
+
+// PublicMessage returns a message for err that is safe to show to clients
+// outside of the GraphQL error list, e.g. in a per-row report. Internal and
+// unclassified errors are logged and masked.
+func PublicMessage(err error) string {
+	var domainErr Error
+	if errors.As(err, &domainErr) && domainErr.Code() != CodeInternal {
+		return domainErr.Error()
+	}
+
+	log.Printf("internal error: %v", err)
+	return internalErrorMessage
+}
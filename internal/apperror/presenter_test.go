@@ -0,0 +1,43 @@
+package apperror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+func TestPresenterAddsCodeAndExtensions(t *testing.T) {
+
+	err := &VersionConflictError{Entity: "polish word", ID: "1", ExpectedVersion: 1, CurrentVersion: 3}
+
+	presented := Presenter(context.Background(), fmt.Errorf("update failed: %w", err))
+
+	assert.Equal(t, "this polish word has been modified by a different process", presented.Message)
+	assert.Equal(t, CodeVersionConflict, presented.Extensions["code"])
+	assert.Equal(t, 3, presented.Extensions["currentVersion"])
+}
+
+func TestPresenterMasksInternalErrors(t *testing.T) {
+
+	for _, err := range []error{
+		&InternalError{Err: errors.New("pq: password authentication failed")},
+		errors.New("sql: no rows in result set"),
+	} {
+		presented := Presenter(context.Background(), err)
+
+		assert.Equal(t, internalErrorMessage, presented.Message)
+		assert.Equal(t, CodeInternal, presented.Extensions["code"])
+	}
+}
+
+func TestPresenterKeepsGraphQLErrors(t *testing.T) {
+
+	presented := Presenter(context.Background(), gqlerror.Errorf("must not be null"))
+
+	assert.Equal(t, "must not be null", presented.Message)
+	assert.Nil(t, presented.Extensions)
+}
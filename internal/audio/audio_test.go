@@ -0,0 +1,156 @@
+package audio
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/apperror"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/blobstore"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const mp3 = "ID3\x04\x00\x00\x00\x00\x00\x00 audio frames"
+
+func TestSniff(t *testing.T) {
+
+	tests := map[string]string{
+		mp3:                                    "audio/mpeg",
+		"\xFF\xFB\x90\x64 frame":               "audio/mpeg",
+		"RIFF\x24\x08\x00\x00WAVEfmt ":         "audio/wav",
+		"OggS\x00\x02":                         "audio/ogg",
+		"fLaC\x00\x00\x00\x22":                 "audio/flac",
+		"\x00\x00\x00\x20ftypM4A \x00\x00\x00": "audio/mp4",
+		"\x1A\x45\xDF\xA3\x9F\x42\x86\x81\x01": "audio/webm",
+	}
+
+	for head, expected := range tests {
+		contentType, _, ok := Sniff([]byte(head))
+		assert.True(t, ok, head)
+		assert.Equal(t, expected, contentType)
+	}
+
+	for _, head := range []string{"", "<html><script>", "RIFF\x24\x08\x00\x00AVI ", "\x00\x00\x00\x20ftypisom"} {
+		_, _, ok := Sniff([]byte(head))
+		assert.False(t, ok, head)
+	}
+}
+
+func TestURLSignerVerify(t *testing.T) {
+
+	now := time.Unix(1_700_000_000, 0)
+	signer := &URLSigner{Secret: []byte("secret"), TTL: time.Minute, now: func() time.Time { return now }}
+
+	link, err := url.Parse(signer.URL("a1.mp3"))
+	require.NoError(t, err)
+	assert.Equal(t, "/audio/a1.mp3", link.Path)
+
+	expires, signature := link.Query().Get("expires"), link.Query().Get("signature")
+	assert.True(t, signer.Verify("a1.mp3", expires, signature))
+	assert.False(t, signer.Verify("a2.mp3", expires, signature))
+	assert.False(t, signer.Verify("a1.mp3", "1700000120", signature))
+	assert.False(t, (&URLSigner{Secret: []byte("other"), now: signer.now}).Verify("a1.mp3", expires, signature))
+
+	now = now.Add(2 * time.Minute)
+	assert.False(t, signer.Verify("a1.mp3", expires, signature))
+}
+
+func TestHandlerStreamsSignedLinks(t *testing.T) {
+
+	store := &blobstore.FS{Root: t.TempDir()}
+	require.NoError(t, store.Put(context.Background(), "a1.mp3", strings.NewReader(mp3), int64(len(mp3)), "audio/mpeg"))
+
+	signer := &URLSigner{Secret: []byte("secret")}
+	handler := Handler(store, signer)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, signer.URL("a1.mp3"), nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "audio/mpeg", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "nosniff", recorder.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, mp3, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/audio/a1.mp3", nil))
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, signer.URL("missing.mp3"), nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestRecorderReplacesPreviousRecording(t *testing.T) {
+
+	store := &blobstore.FS{Root: t.TempDir()}
+	require.NoError(t, store.Put(context.Background(), "old.mp3", strings.NewReader(mp3), int64(len(mp3)), "audio/mpeg"))
+
+	repo := new(mocks.MockAudioRepository)
+	rec := &Recorder{Store: store, Repo: repo}
+
+	id := "1"
+	var stored *model.Audio
+	repo.On("AttachPolishWordAudio", mock.Anything, &id, (*string)(nil), mock.Anything).
+		Run(func(args mock.Arguments) { stored = args.Get(3).(*model.Audio) }).
+		Return(&model.PolishWord{ID: id, Word: "zamek"}, "old.mp3", nil).Once()
+
+	_, err := rec.AttachToPolishWord(context.Background(), &id, nil, strings.NewReader(mp3), int64(len(mp3)))
+	require.NoError(t, err)
+
+	require.NotNil(t, stored)
+	assert.Equal(t, "audio/mpeg", stored.ContentType)
+	assert.Equal(t, len(mp3), stored.Size)
+	assert.True(t, strings.HasSuffix(stored.Key, ".mp3"))
+
+	assert.FileExists(t, filepath.Join(store.Root, stored.Key))
+	assert.NoFileExists(t, filepath.Join(store.Root, "old.mp3"))
+
+	repo.AssertExpectations(t)
+}
+
+func TestRecorderRemovesBlobWhenAttachFails(t *testing.T) {
+
+	store := &blobstore.FS{Root: t.TempDir()}
+	repo := new(mocks.MockAudioRepository)
+	rec := &Recorder{Store: store, Repo: repo}
+
+	notFound := &apperror.NotFoundError{Entity: "example sentence", Field: "id", Value: "7"}
+	repo.On("AttachExampleSentenceAudio", mock.Anything, "7", mock.Anything).Return(nil, "", notFound).Once()
+
+	_, err := rec.AttachToExampleSentence(context.Background(), "7", strings.NewReader(mp3), int64(len(mp3)))
+	require.ErrorAs(t, err, &notFound)
+
+	entries, err := os.ReadDir(store.Root)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestRecorderRejectsInvalidUploads(t *testing.T) {
+
+	rec := &Recorder{Store: &blobstore.FS{Root: t.TempDir()}, Repo: new(mocks.MockAudioRepository), MaxSize: 64}
+
+	tests := map[string]string{
+		"unsupported audio format": "<html></html>",
+		"larger than the limit":    mp3 + strings.Repeat("x", 64),
+		"empty":                    "",
+	}
+
+	for message, content := range tests {
+		_, err := rec.AttachToExampleSentence(context.Background(), "7", strings.NewReader(content), int64(len(content)))
+
+		var validation *apperror.ValidationError
+		require.ErrorAs(t, err, &validation, message)
+		assert.Equal(t, "file", validation.Field)
+		assert.Contains(t, err.Error(), message)
+	}
+}
@@ -0,0 +1,69 @@
+package audio
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/blobstore"
+)
+
+// Handler streams recordings for links made by signer, e.g.
+// GET /audio/<key>?expires=...&signature=...
+func Handler(store blobstore.BlobStore, signer *URLSigner) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		key := strings.TrimPrefix(r.URL.Path, RoutePrefix)
+		query := r.URL.Query()
+		if !signer.Verify(key, query.Get("expires"), query.Get("signature")) {
+			http.Error(w, "invalid or expired link", http.StatusForbidden)
+			return
+		}
+
+		body, size, err := store.Get(r.Context(), key)
+		if errors.Is(err, blobstore.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			log.Printf("failed to read audio %q: %v", key, err)
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+		defer body.Close()
+
+		// The type is sniffed again rather than trusted from the upload, and
+		// browsers are told not to second-guess it.
+		reader := bufio.NewReaderSize(body, sniffLength)
+		head, _ := reader.Peek(sniffLength)
+		contentType, _, ok := Sniff(head)
+		if !ok {
+			contentType = "application/octet-stream"
+		}
+
+		expires, _ := strconv.ParseInt(query.Get("expires"), 10, 64)
+		maxAge := max(expires-signer.clock().Unix(), 0)
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Cache-Control", "private, max-age="+strconv.FormatInt(maxAge, 10))
+
+		if r.Method == http.MethodHead {
+			return
+		}
+
+		if _, err := io.Copy(w, reader); err != nil {
+			log.Printf("failed to stream audio %q: %v", key, err)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package audio
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/apperror"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/blobstore"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/repository"
+)
+
+const DefaultMaxSize = 10 << 20
+
+// Recorder checks uploaded recordings, stores them in Store and links them to
+// dictionary entries through Repo. The blob is written before the database
+// row, so a failed attach removes the new blob and a successful one removes
+// the blob it replaced.
+type Recorder struct {
+	Store blobstore.BlobStore
+	Repo  repository.AudioRepositoryInterface
+	// MaxSize is in bytes and defaults to DefaultMaxSize.
+	MaxSize int64
+}
+
+func (rec *Recorder) AttachToPolishWord(ctx context.Context, polishWordID *string, polishWord *string, file io.Reader, size int64) (*model.PolishWord, error) {
+	audio, err := rec.save(ctx, file, size)
+	if err != nil {
+		return nil, err
+	}
+
+	pw, replaced, err := rec.Repo.AttachPolishWordAudio(ctx, polishWordID, polishWord, audio)
+	if err != nil {
+		rec.discard(ctx, audio.Key)
+		return nil, err
+	}
+
+	rec.discard(ctx, replaced)
+	return pw, nil
+}
+
+func (rec *Recorder) AttachToExampleSentence(ctx context.Context, exampleSentenceID string, file io.Reader, size int64) (*model.ExampleSentence, error) {
+	audio, err := rec.save(ctx, file, size)
+	if err != nil {
+		return nil, err
+	}
+
+	es, replaced, err := rec.Repo.AttachExampleSentenceAudio(ctx, exampleSentenceID, audio)
+	if err != nil {
+		rec.discard(ctx, audio.Key)
+		return nil, err
+	}
+
+	rec.discard(ctx, replaced)
+	return es, nil
+}
+
+func (rec *Recorder) save(ctx context.Context, file io.Reader, size int64) (*model.Audio, error) {
+	maxSize := rec.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+
+	if size <= 0 {
+		return nil, &apperror.ValidationError{Field: "file", Message: "the file is empty"}
+	}
+	if size > maxSize {
+		return nil, &apperror.ValidationError{Field: "file", Message: fmt.Sprintf("the file is larger than the limit of %d bytes", maxSize)}
+	}
+
+	reader := bufio.NewReaderSize(io.LimitReader(file, size), sniffLength)
+	head, _ := reader.Peek(sniffLength)
+
+	contentType, extension, ok := Sniff(head)
+	if !ok {
+		return nil, &apperror.ValidationError{Field: "file", Message: "unsupported audio format, use MP3, WAV, Ogg, FLAC, M4A or WebM"}
+	}
+
+	key, err := newKey(extension)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rec.Store.Put(ctx, key, reader, size, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store audio: %w", err)
+	}
+
+	return &model.Audio{Key: key, ContentType: contentType, Size: int(size)}, nil
+}
+
+// discard removes a blob that is no longer referenced. It runs even when the
+// request was cancelled, and a failure only leaves an orphaned file behind.
+func (rec *Recorder) discard(ctx context.Context, key string) {
+	if key == "" {
+		return
+	}
+
+	if err := rec.Store.Delete(context.WithoutCancel(ctx), key); err != nil {
+		log.Printf("failed to delete audio %q: %v", key, err)
+	}
+}
+
+func newKey(extension string) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(random) + extension, nil
+}
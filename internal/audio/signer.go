@@ -0,0 +1,63 @@
+package audio
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// RoutePrefix is where Handler is mounted.
+const RoutePrefix = "/audio/"
+
+const DefaultURLTTL = time.Hour
+
+// URLSigner creates and checks expiring links to recordings. A link carries
+// its expiry time and an HMAC-SHA256 of the key and that time, so recordings
+// cannot be listed or fetched without a link from the API.
+type URLSigner struct {
+	Secret []byte
+	// TTL defaults to DefaultURLTTL.
+	TTL time.Duration
+
+	now func() time.Time
+}
+
+func (s *URLSigner) URL(key string) string {
+	ttl := s.TTL
+	if ttl <= 0 {
+		ttl = DefaultURLTTL
+	}
+
+	expires := strconv.FormatInt(s.clock().Add(ttl).Unix(), 10)
+	query := url.Values{"expires": {expires}, "signature": {s.signature(key, expires)}}
+
+	return RoutePrefix + url.PathEscape(key) + "?" + query.Encode()
+}
+
+// Verify reports whether signature is valid for key and expires, and the
+// link has not expired yet.
+func (s *URLSigner) Verify(key string, expires string, signature string) bool {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || s.clock().Unix() > expiresAt {
+		return false
+	}
+
+	expected := s.signature(key, expires)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+func (s *URLSigner) signature(key string, expires string) string {
+	mac := hmac.New(sha256.New, s.Secret)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *URLSigner) clock() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
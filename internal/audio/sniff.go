@@ -0,0 +1,47 @@
+package audio
+
+import "bytes"
+
+// sniffLength is how much of a file Sniff needs to see.
+const sniffLength = 512
+
+type format struct {
+	contentType string
+	extension   string
+	matches     func(head []byte) bool
+}
+
+// formats are the audio containers browsers play natively. The content type
+// sent by the client is ignored; only the bytes decide.
+var formats = []format{
+	{"audio/mpeg", ".mp3", func(head []byte) bool {
+		// An ID3 tag or the sync word of an MPEG audio frame.
+		return bytes.HasPrefix(head, []byte("ID3")) || (len(head) >= 2 && head[0] == 0xFF && head[1]&0xE0 == 0xE0)
+	}},
+	{"audio/wav", ".wav", func(head []byte) bool {
+		return len(head) >= 12 && bytes.Equal(head[:4], []byte("RIFF")) && bytes.Equal(head[8:12], []byte("WAVE"))
+	}},
+	{"audio/ogg", ".ogg", func(head []byte) bool {
+		return bytes.HasPrefix(head, []byte("OggS"))
+	}},
+	{"audio/flac", ".flac", func(head []byte) bool {
+		return bytes.HasPrefix(head, []byte("fLaC"))
+	}},
+	{"audio/mp4", ".m4a", func(head []byte) bool {
+		return len(head) >= 12 && bytes.Equal(head[4:8], []byte("ftyp")) && bytes.Equal(head[8:12], []byte("M4A "))
+	}},
+	{"audio/webm", ".webm", func(head []byte) bool {
+		return bytes.HasPrefix(head, []byte{0x1A, 0x45, 0xDF, 0xA3})
+	}},
+}
+
+// Sniff detects the audio format from the first bytes of a file and returns
+// its content type and file extension.
+func Sniff(head []byte) (contentType string, extension string, ok bool) {
+	for _, f := range formats {
+		if f.matches(head) {
+			return f.contentType, f.extension, true
+		}
+	}
+	return "", "", false
+}
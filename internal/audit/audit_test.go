@@ -0,0 +1,154 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/auth"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/mocks"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func mutationContext(alias string, name string, args map[string]any) context.Context {
+	ctx := context.WithValue(context.Background(), contextKey{}, Request{ID: "req-1", ClientIP: "10.0.0.7"})
+	ctx = auth.WithPrincipal(ctx, &auth.Principal{Name: "ania"})
+	ctx = graphql.WithOperationContext(ctx, &graphql.OperationContext{OperationName: "Fix"})
+	return graphql.WithFieldContext(ctx, &graphql.FieldContext{
+		Object: "Mutation",
+		Field:  graphql.CollectedField{Field: &ast.Field{Alias: alias, Name: name}},
+		Args:   args,
+	})
+}
+
+func TestRecorderLogsMutationWithItsChanges(t *testing.T) {
+	repo := new(mocks.MockAuditRepository)
+	rec := &Recorder{Repo: repo}
+
+	changes := &repository.Changes{
+		Before: map[string]json.RawMessage{"polish_word:12": []byte(`{"word": "zamak"}`)},
+		After:  map[string]json.RawMessage{"polish_word:12": []byte(`{"word": "zamek"}`)},
+	}
+	var changeID string
+	repo.On("GetChanges", mock.Anything, mock.MatchedBy(func(id string) bool {
+		changeID = id
+		return true
+	})).Return(changes, nil).Once()
+	repo.On("RecordAudit", mock.Anything, mock.MatchedBy(func(record *repository.AuditRecord) bool {
+		return record.Operation == "updatePolishWord" &&
+			*record.OperationName == "Fix" &&
+			string(record.Arguments) == `{"id":"12"}` &&
+			assert.ObjectsAreEqual([]string{"polish_word:12", "translation:3"}, record.AffectedIDs) &&
+			record.Changes == changes &&
+			*record.Actor == "ania" &&
+			*record.ClientIP == "10.0.0.7" &&
+			record.RequestID == "req-1" &&
+			record.Error == nil
+	})).Return(nil).Once()
+
+	ctx := mutationContext("fix", "updatePolishWord", map[string]any{"id": "12"})
+	result := &model.PolishWord{ID: "12", Translations: []*model.Translation{{ID: "3"}}}
+
+	res, err := rec.AroundFields(ctx, func(ctx context.Context) (any, error) {
+		return []any{result, &model.Translation{ID: "3"}}, nil
+	})
+	require.NoError(t, err)
+	assert.Len(t, res, 2)
+	assert.Len(t, changeID, 32)
+	assert.NotContains(t, changeID, "req-1")
+
+	repo.AssertExpectations(t)
+}
+
+func TestRecorderLogsFailedMutationAndKeepsItsError(t *testing.T) {
+	repo := new(mocks.MockAuditRepository)
+	rec := &Recorder{Repo: repo}
+
+	repo.On("GetChanges", mock.Anything, mock.Anything).Return(&repository.Changes{}, nil).Once()
+	repo.On("RecordAudit", mock.Anything, mock.MatchedBy(func(record *repository.AuditRecord) bool {
+		return *record.Error == "broken file" &&
+			string(record.Arguments) == `{"file":{"contentType":"text/csv","filename":"words.csv","size":42}}`
+	})).Return(errors.New("audit_log is unavailable")).Once()
+
+	ctx := mutationContext("importDictionary", "importDictionary", map[string]any{
+		"file": graphql.Upload{File: strings.NewReader("secret"), Filename: "words.csv", Size: 42, ContentType: "text/csv"},
+	})
+
+	_, err := rec.AroundFields(ctx, func(ctx context.Context) (any, error) {
+		return nil, errors.New("broken file")
+	})
+	assert.EqualError(t, err, "broken file")
+
+	repo.AssertExpectations(t)
+}
+
+func TestRecorderGivesEveryMutationItsOwnChangeID(t *testing.T) {
+	repo := new(mocks.MockAuditRepository)
+	rec := &Recorder{Repo: repo}
+
+	var changeIDs []string
+	repo.On("GetChanges", mock.Anything, mock.MatchedBy(func(id string) bool {
+		changeIDs = append(changeIDs, id)
+		return true
+	})).Return(&repository.Changes{}, nil).Twice()
+	repo.On("RecordAudit", mock.Anything, mock.MatchedBy(func(record *repository.AuditRecord) bool {
+		return record.RequestID == "req-1"
+	})).Return(nil).Twice()
+
+	// Both contexts carry the same client-chosen request ID and alias.
+	for range 2 {
+		_, err := rec.AroundFields(mutationContext("fix", "updatePolishWord", nil), func(ctx context.Context) (any, error) {
+			return nil, nil
+		})
+		require.NoError(t, err)
+	}
+
+	require.Len(t, changeIDs, 2)
+	assert.NotEqual(t, changeIDs[0], changeIDs[1])
+
+	repo.AssertExpectations(t)
+}
+
+func TestRecorderSkipsQueries(t *testing.T) {
+	repo := new(mocks.MockAuditRepository)
+	rec := &Recorder{Repo: repo}
+
+	ctx := graphql.WithFieldContext(context.Background(), &graphql.FieldContext{Object: "Query"})
+	_, err := rec.AroundFields(ctx, func(ctx context.Context) (any, error) { return nil, nil })
+	require.NoError(t, err)
+
+	repo.AssertNotCalled(t, "RecordAudit", mock.Anything, mock.Anything)
+}
+
+func TestMiddlewareKeepsSafeRequestIDs(t *testing.T) {
+	var seen Request
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestFrom(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/query", nil)
+	req.RemoteAddr = "192.0.2.1:5123"
+	req.Header.Set("X-Request-ID", "abc-123")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, Request{ID: "abc-123", ClientIP: "192.0.2.1"}, seen)
+	assert.Equal(t, "abc-123", rec.Header().Get("X-Request-ID"))
+
+	req.Header.Set("X-Request-ID", "bad id\n")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Len(t, seen.ID, 32)
+	assert.Equal(t, seen.ID, rec.Header().Get("X-Request-ID"))
+}
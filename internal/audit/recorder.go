@@ -0,0 +1,168 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/99designs/gqlgen/graphql"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/auth"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/repository"
+)
+
+// Recorder writes an entry to the audit log for every mutation, whether it
+// succeeded or not. The entry is written after the mutation committed, and a
+// failure to write it is logged without failing the mutation.
+type Recorder struct {
+	Repo repository.AuditRepositoryInterface
+}
+
+// AroundFields is a gqlgen field middleware. Each mutation field runs under
+// its own change id, which the history triggers store with the revisions it
+// writes so that the entry can show them. The change id is always generated
+// here: request IDs may come from the client, and two requests sending the
+// same one must not read each other's changes.
+func (rec *Recorder) AroundFields(ctx context.Context, next graphql.Resolver) (any, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || fc.Object != "Mutation" {
+		return next(ctx)
+	}
+
+	request := RequestFrom(ctx)
+	if request.ID == "" {
+		request.ID = newID()
+	}
+	changeID := newID()
+
+	res, err := next(repository.WithChangeID(ctx, changeID))
+
+	// The entry is written even when the client has gone away.
+	rec.record(context.WithoutCancel(ctx), fc, request, changeID, res, err)
+	return res, err
+}
+
+func (rec *Recorder) record(ctx context.Context, fc *graphql.FieldContext, request Request, changeID string, res any, resErr error) {
+	record := &repository.AuditRecord{
+		Operation:   fc.Field.Name,
+		AffectedIDs: []string{},
+		RequestID:   request.ID,
+	}
+
+	if graphql.HasOperationContext(ctx) {
+		if name := graphql.GetOperationContext(ctx).OperationName; name != "" {
+			record.OperationName = &name
+		}
+	}
+	if p := auth.PrincipalFrom(ctx); p != nil {
+		record.Actor = &p.Name
+	}
+	if request.ClientIP != "" {
+		record.ClientIP = &request.ClientIP
+	}
+	if resErr != nil {
+		message := resErr.Error()
+		record.Error = &message
+	}
+
+	arguments, err := json.Marshal(describeUploads(fc.Args))
+	if err != nil {
+		log.Printf("audit: could not encode the arguments of %s: %v", record.Operation, err)
+		arguments = []byte("{}")
+	}
+	record.Arguments = arguments
+
+	changes, err := rec.Repo.GetChanges(ctx, changeID)
+	if err != nil {
+		log.Printf("audit: could not read the changes of %s: %v", record.Operation, err)
+	} else {
+		record.Changes = changes
+		record.AffectedIDs = slices.Sorted(maps.Keys(changes.After))
+	}
+	for _, key := range resultIDs(res) {
+		if !slices.Contains(record.AffectedIDs, key) {
+			record.AffectedIDs = append(record.AffectedIDs, key)
+		}
+	}
+
+	if err := rec.Repo.RecordAudit(ctx, record); err != nil {
+		log.Printf("audit: could not record %s in request %s: %v", record.Operation, request.ID, err)
+	}
+}
+
+// describeUploads replaces uploaded files, which are kept out of the log,
+// with their name, size and content type.
+func describeUploads(args map[string]any) map[string]any {
+	described := make(map[string]any, len(args))
+	for name, value := range args {
+		switch upload := value.(type) {
+		case graphql.Upload:
+			described[name] = uploadDescription(upload)
+		case *graphql.Upload:
+			if upload != nil {
+				described[name] = uploadDescription(*upload)
+			}
+		default:
+			described[name] = value
+		}
+	}
+	return described
+}
+
+func uploadDescription(upload graphql.Upload) map[string]any {
+	return map[string]any{
+		"filename":    upload.Filename,
+		"size":        upload.Size,
+		"contentType": upload.ContentType,
+	}
+}
+
+// resultIDs keys the entries a mutation returned like the history does, so a
+// returned *model.PolishWord with ID 12 becomes polish_word:12.
+func resultIDs(res any) []string {
+	var ids []string
+
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return
+			}
+			v = v.Elem()
+		}
+
+		switch v.Kind() {
+		case reflect.Slice:
+			for i := 0; i < v.Len(); i++ {
+				walk(v.Index(i))
+			}
+		case reflect.Struct:
+			id := v.FieldByName("ID")
+			if id.Kind() == reflect.String && id.String() != "" {
+				ids = append(ids, snakeCase(v.Type().Name())+":"+id.String())
+			}
+		}
+	}
+	walk(reflect.ValueOf(res))
+
+	return ids
+}
+
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,50 @@
+package audit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"regexp"
+)
+
+// Request identifies the HTTP request a mutation came in.
+type Request struct {
+	ID       string
+	ClientIP string
+}
+
+type contextKey struct{}
+
+var safeRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// Middleware gives every request an ID, taken from the X-Request-ID header
+// when the client sent a usable one, and echoes it back in that header.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := Request{ID: r.Header.Get("X-Request-ID"), ClientIP: r.RemoteAddr}
+		if !safeRequestID.MatchString(request.ID) {
+			request.ID = newID()
+		}
+		if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+			request.ClientIP = host
+		}
+
+		w.Header().Set("X-Request-ID", request.ID)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, request)))
+	})
+}
+
+// RequestFrom returns the request ctx belongs to. Outside of the middleware
+// its fields are empty.
+func RequestFrom(ctx context.Context) Request {
+	request, _ := ctx.Value(contextKey{}).(Request)
+	return request
+}
+
+func newID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+)
+
+const (
+	apiKeyPrefix = "dict_"
+	// APIKeyPrefixLength is how much of a key is kept in the clear to tell
+	// keys apart.
+	APIKeyPrefixLength = len(apiKeyPrefix) + 6
+)
+
+// APIKeyStore finds the caller an API key was issued to by the key's hash,
+// returning nil when no live key has that hash.
+type APIKeyStore interface {
+	PrincipalForAPIKey(ctx context.Context, keyHash string) (*Principal, error)
+}
+
+// APIKeys authenticates requests by the key in their X-API-Key header.
+type APIKeys struct {
+	Store APIKeyStore
+}
+
+func (a *APIKeys) Authenticate(r *http.Request) (*Principal, error) {
+	key := r.Header.Get("X-API-Key")
+	if key == "" {
+		return nil, nil
+	}
+
+	principal, err := a.Store.PrincipalForAPIKey(r.Context(), HashAPIKey(key))
+	if err != nil {
+		return nil, err
+	}
+	if principal == nil {
+		return nil, ErrInvalidCredentials
+	}
+	return principal, nil
+}
+
+// NewAPIKey generates a random key. Only its hash is meant to be stored.
+func NewAPIKey() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// HashAPIKey returns the hex SHA-256 of key. Keys are random, so a fast hash
+// is enough to keep them from being usable if the table leaks.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
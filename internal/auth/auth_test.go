@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var hmacSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func signedToken(t *testing.T, alg string, claims map[string]any, sign func(signed []byte) []byte) string {
+	header, err := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sign([]byte(signed)))
+}
+
+func hs256(signed []byte) []byte {
+	mac := hmac.New(sha256.New, hmacSecret)
+	mac.Write(signed)
+	return mac.Sum(nil)
+}
+
+func bearer(token string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/query", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	return r
+}
+
+func TestJWTAcceptsHS256Token(t *testing.T) {
+	authenticator := &JWT{HMACSecret: hmacSecret, Audience: "dictionary"}
+
+	token := signedToken(t, "HS256", map[string]any{
+		"sub": "ania", "roles": []string{"editor"}, "aud": "dictionary", "exp": time.Now().Add(time.Hour).Unix(),
+	}, hs256)
+
+	principal, err := authenticator.Authenticate(bearer(token))
+	require.NoError(t, err)
+	assert.Equal(t, &Principal{Name: "ania", Roles: []Role{RoleEditor}}, principal)
+}
+
+func TestJWTAcceptsRS256Token(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	authenticator := &JWT{RSAPublicKey: &key.PublicKey}
+
+	token := signedToken(t, "RS256", map[string]any{"sub": "ci", "exp": time.Now().Add(time.Hour).Unix()}, func(signed []byte) []byte {
+		digest := sha256.Sum256(signed)
+		signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+		return signature
+	})
+
+	principal, err := authenticator.Authenticate(bearer(token))
+	require.NoError(t, err)
+	assert.Equal(t, "ci", principal.Name)
+	assert.False(t, principal.HasRole(RoleAdmin))
+}
+
+func TestJWTRejectsInvalidTokens(t *testing.T) {
+	authenticator := &JWT{HMACSecret: hmacSecret, Issuer: "https://login.example"}
+	valid := map[string]any{"sub": "ania", "iss": "https://login.example", "exp": time.Now().Add(time.Hour).Unix()}
+
+	with := func(key string, value any) map[string]any {
+		claims := map[string]any{}
+		for k, v := range valid {
+			claims[k] = v
+		}
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+
+	tokens := map[string]string{
+		"expired":          signedToken(t, "HS256", with("exp", time.Now().Add(-time.Hour).Unix()), hs256),
+		"without expiry":   signedToken(t, "HS256", with("exp", nil), hs256),
+		"not yet valid":    signedToken(t, "HS256", with("nbf", time.Now().Add(time.Hour).Unix()), hs256),
+		"wrong issuer":     signedToken(t, "HS256", with("iss", "https://elsewhere.example"), hs256),
+		"without subject":  signedToken(t, "HS256", with("sub", nil), hs256),
+		"unsigned":         signedToken(t, "none", valid, func([]byte) []byte { return nil }),
+		"wrong signature":  signedToken(t, "HS256", valid, func(signed []byte) []byte { return hs256(append(signed, 'x')) }),
+		"unconfigured alg": signedToken(t, "RS256", valid, hs256),
+		"malformed":        "not.a.token",
+	}
+
+	for name, token := range tokens {
+		_, err := authenticator.Authenticate(bearer(token))
+		assert.ErrorIs(t, err, ErrInvalidCredentials, name)
+	}
+}
+
+type apiKeyStore map[string]*Principal
+
+func (s apiKeyStore) PrincipalForAPIKey(ctx context.Context, keyHash string) (*Principal, error) {
+	return s[keyHash], nil
+}
+
+func TestMiddlewareAuthenticatesAPIKeys(t *testing.T) {
+	key, err := NewAPIKey()
+	require.NoError(t, err)
+
+	store := apiKeyStore{HashAPIKey(key): {Name: "importer", Roles: []Role{RoleEditor}}}
+
+	var seen *Principal
+	handler := Middleware([]Authenticator{&APIKeys{Store: store}, &JWT{HMACSecret: hmacSecret}}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = PrincipalFrom(r.Context())
+	}))
+
+	r := httptest.NewRequest(http.MethodPost, "/query", nil)
+	r.Header.Set("X-API-Key", key)
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	assert.Equal(t, "importer", seen.Name)
+
+	seen = nil
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/query", nil))
+	assert.Nil(t, seen, "a request without credentials is anonymous")
+
+	r.Header.Set("X-API-Key", key+"x")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.JSONEq(t, `{"errors":[{"message":"invalid credentials","extensions":{"code":"UNAUTHENTICATED"}}]}`, w.Body.String())
+}
@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// clockSkew is how far the clocks of the token issuer and this server may
+// disagree when checking exp and nbf.
+const clockSkew = time.Minute
+
+// JWT authenticates requests by a bearer token signed with HS256 or RS256.
+// The sub claim names the caller and the roles claim lists its roles. Tokens
+// must expire.
+type JWT struct {
+	// HMACSecret verifies HS256 tokens; without it they are rejected.
+	HMACSecret []byte
+	// RSAPublicKey verifies RS256 tokens; without it they are rejected.
+	RSAPublicKey *rsa.PublicKey
+	// Issuer and Audience, when set, must match the iss and aud claims.
+	Issuer   string
+	Audience string
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+}
+
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *float64 `json:"exp"`
+	NotBefore *float64 `json:"nbf"`
+	Roles     []Role   `json:"roles"`
+}
+
+// audience is the aud claim, which may be a single string or a list.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(a))
+}
+
+func (j *JWT) Authenticate(r *http.Request) (*Principal, error) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return nil, nil
+	}
+
+	claims, err := j.verify(token, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	return &Principal{Name: claims.Subject, Roles: claims.Roles}, nil
+}
+
+func (j *JWT) verify(token string, now time.Time) (*jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("malformed token signature")
+	}
+	if err := j.verifySignature(header.Alg, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case claims.ExpiresAt == nil:
+		return nil, errors.New("token does not expire")
+	case now.Add(-clockSkew).After(numericDate(*claims.ExpiresAt)):
+		return nil, errors.New("token has expired")
+	case claims.NotBefore != nil && now.Add(clockSkew).Before(numericDate(*claims.NotBefore)):
+		return nil, errors.New("token is not valid yet")
+	case j.Issuer != "" && claims.Issuer != j.Issuer:
+		return nil, errors.New("token has the wrong issuer")
+	case j.Audience != "" && !slices.Contains(claims.Audience, j.Audience):
+		return nil, errors.New("token has the wrong audience")
+	case claims.Subject == "":
+		return nil, errors.New("token has no subject")
+	}
+
+	return &claims, nil
+}
+
+// verifySignature checks signature with the key for alg. Each algorithm has
+// its own key, so a token cannot pick which key it is verified with.
+func (j *JWT) verifySignature(alg string, signed []byte, signature []byte) error {
+	switch {
+	case alg == "HS256" && j.HMACSecret != nil:
+		mac := hmac.New(sha256.New, j.HMACSecret)
+		mac.Write(signed)
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return errors.New("invalid token signature")
+		}
+	case alg == "RS256" && j.RSAPublicKey != nil:
+		digest := sha256.Sum256(signed)
+		if rsa.VerifyPKCS1v15(j.RSAPublicKey, crypto.SHA256, digest[:], signature) != nil {
+			return errors.New("invalid token signature")
+		}
+	default:
+		return fmt.Errorf("unsupported token algorithm %q", alg)
+	}
+	return nil
+}
+
+func decodeSegment(segment string, dest any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return errors.New("malformed token")
+	}
+	if err := json.Unmarshal(raw, dest); err != nil {
+		return errors.New("malformed token")
+	}
+	return nil
+}
+
+func numericDate(seconds float64) time.Time {
+	return time.Unix(int64(seconds), 0)
+}
+
+// ReadHMACSecret reads an HS256 secret from path, without surrounding
+// whitespace. It must be at least 32 bytes long, as RFC 7518 requires.
+func ReadHMACSecret(path string) ([]byte, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := bytes.TrimSpace(raw)
+	if len(secret) < 32 {
+		return nil, fmt.Errorf("%s: the secret must be at least 32 bytes long", path)
+	}
+	return secret, nil
+}
+
+// ReadRSAPublicKey reads a PEM-encoded RSA public key from path, in either
+// PKIX ("PUBLIC KEY") or PKCS #1 ("RSA PUBLIC KEY") form.
+func ReadRSAPublicKey(path string) (*rsa.PublicKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", path)
+	}
+
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("%s: not an RSA public key", path)
+		}
+		return rsaKey, nil
+	default:
+		return nil, fmt.Errorf("%s: unexpected PEM block %q", path, block.Type)
+	}
+}
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// ErrInvalidCredentials is returned by an Authenticator when a request
+// carries credentials that it does not accept.
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// Authenticator identifies the caller of a request from the credentials it
+// carries. It returns a nil principal without an error when the request has
+// no credentials of its kind.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// Middleware puts the caller in the request context. The first authenticator
+// that finds credentials decides; a request without any is anonymous, and one
+// whose credentials are rejected gets 401.
+func Middleware(authenticators []Authenticator, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, authenticator := range authenticators {
+			principal, err := authenticator.Authenticate(r)
+			if errors.Is(err, ErrInvalidCredentials) {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeError(w, http.StatusUnauthorized, "UNAUTHENTICATED", err.Error())
+				return
+			}
+			if err != nil {
+				log.Printf("authentication failed: %v", err)
+				writeError(w, http.StatusInternalServerError, "INTERNAL", "internal server error")
+				return
+			}
+			if principal != nil {
+				r = r.WithContext(WithPrincipal(r.Context(), principal))
+				break
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeError answers in the shape of a GraphQL error so that clients can
+// handle it like any other.
+func writeError(w http.ResponseWriter, status int, code string, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"errors": []map[string]any{{"message": message, "extensions": map[string]any{"code": code}}},
+	})
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"context"
+	"slices"
+)
+
+// Role is what a caller may do. Roles are ordered: an admin may do whatever
+// an editor may, and an editor whatever a reader may.
+type Role string
+
+const (
+	// RoleReader is held by every identified caller and is never assigned.
+	RoleReader Role = "reader"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+)
+
+// roleImplies lists for each assignable role the roles it includes.
+var roleImplies = map[Role][]Role{
+	RoleEditor: {RoleReader, RoleEditor},
+	RoleAdmin:  {RoleReader, RoleEditor, RoleAdmin},
+}
+
+// IsValid reports whether r can be assigned to a caller.
+func (r Role) IsValid() bool {
+	return r == RoleEditor || r == RoleAdmin
+}
+
+// Principal is the caller a request acts for.
+type Principal struct {
+	Name  string
+	Roles []Role
+}
+
+// HasRole reports whether p has role or a role that includes it. A nil
+// principal, an anonymous caller, has no roles.
+func (p *Principal) HasRole(role Role) bool {
+	if p == nil {
+		return false
+	}
+	if role == RoleReader {
+		return true
+	}
+	for _, held := range p.Roles {
+		if slices.Contains(roleImplies[held], role) {
+			return true
+		}
+	}
+	return false
+}
+
+type contextKey struct{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// PrincipalFrom returns the caller of the request ctx belongs to, or nil when
+// it is anonymous.
+func PrincipalFrom(ctx context.Context) *Principal {
+	p, _ := ctx.Value(contextKey{}).(*Principal)
+	return p
+}
@@ -0,0 +1,28 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"regexp"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+var keyPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// BlobStore keeps binary files, such as audio recordings, outside the
+// database. Keys are flat names made of letters, digits, dots, dashes and
+// underscores.
+type BlobStore interface {
+	// Put stores exactly size bytes from r under key, replacing any existing blob.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get returns ErrNotFound when there is no blob under key.
+	Get(ctx context.Context, key string) (io.ReadCloser, int64, error)
+	// Delete succeeds when there is no blob under key.
+	Delete(ctx context.Context, key string) error
+}
+
+func ValidKey(key string) bool {
+	return keyPattern.MatchString(key)
+}
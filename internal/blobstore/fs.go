@@ -0,0 +1,87 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FS stores blobs as files in a single directory. Files are written under a
+// temporary name and renamed, so readers never see a partial blob.
+type FS struct {
+	Root string
+}
+
+func (s *FS) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (err error) {
+	if !ValidKey(key) {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+
+	if err := os.MkdirAll(s.Root, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.Root, ".upload-*")
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	written, err := io.Copy(tmp, io.LimitReader(r, size+1))
+	if err != nil {
+		return err
+	}
+	if written != size {
+		return fmt.Errorf("blob %q: expected %d bytes, got %d", key, size, written)
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filepath.Join(s.Root, key))
+}
+
+func (s *FS) Get(ctx context.Context, key string) (io.ReadCloser, int64, error) {
+	if !ValidKey(key) {
+		return nil, 0, ErrNotFound
+	}
+
+	file, err := os.Open(filepath.Join(s.Root, key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, 0, ErrNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, err
+	}
+
+	return file, info.Size(), nil
+}
+
+func (s *FS) Delete(ctx context.Context, key string) error {
+	if !ValidKey(key) {
+		return nil
+	}
+
+	err := os.Remove(filepath.Join(s.Root, key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,253 @@
+package exporter
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/importer"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/repository"
+)
+
+type Format string
+
+const (
+	FormatJSONL Format = "jsonl"
+	FormatCSV   Format = "csv"
+	// FormatAnki is a tab-separated deck for Anki's "Import File" dialog.
+	FormatAnki Format = "anki"
+)
+
+func (f Format) IsValid() bool {
+	switch f {
+	case FormatJSONL, FormatCSV, FormatAnki:
+		return true
+	}
+	return false
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatJSONL:
+		return "application/x-ndjson; charset=utf-8"
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	default:
+		return "text/tab-separated-values; charset=utf-8"
+	}
+}
+
+func (f Format) FileName() string {
+	switch f {
+	case FormatJSONL:
+		return "dictionary.jsonl"
+	case FormatCSV:
+		return "dictionary.csv"
+	default:
+		return "dictionary-anki.txt"
+	}
+}
+
+type entryWriter interface {
+	Write(entry *model.AddPolishWordInput) error
+	Flush() error
+}
+
+// Export streams the whole dictionary from repo to w. JSONL and CSV use the
+// same shape as the importer, so an export can be imported again unchanged.
+func Export(ctx context.Context, repo repository.ExportRepositoryInterface, w io.Writer, format Format) error {
+	buffered := bufio.NewWriter(w)
+
+	var writer entryWriter
+	switch format {
+	case FormatJSONL:
+		encoder := json.NewEncoder(buffered)
+		encoder.SetEscapeHTML(false)
+		writer = &jsonlWriter{encoder: encoder, w: buffered}
+	case FormatCSV:
+		writer = newCSVWriter(buffered)
+	case FormatAnki:
+		writer = newAnkiWriter(buffered)
+	default:
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+
+	if err := repo.ForEachEntry(ctx, writer.Write); err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
+
+type jsonlWriter struct {
+	encoder *json.Encoder
+	w       *bufio.Writer
+}
+
+func (jw *jsonlWriter) Write(entry *model.AddPolishWordInput) error {
+	return jw.encoder.Encode(entry)
+}
+
+func (jw *jsonlWriter) Flush() error {
+	return jw.w.Flush()
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+	w      *bufio.Writer
+	header bool
+}
+
+func newCSVWriter(w *bufio.Writer) *csvWriter {
+	return &csvWriter{writer: csv.NewWriter(w), w: w}
+}
+
+// Write emits one row per example sentence and further language of it, one
+// per translation without sentences and one for a word without translations.
+func (cw *csvWriter) Write(entry *model.AddPolishWordInput) error {
+	if !cw.header {
+		if err := cw.writer.Write(importer.CSVColumns); err != nil {
+			return err
+		}
+		cw.header = true
+	}
+
+	if len(entry.Translations) == 0 {
+		return cw.writer.Write([]string{entry.Word, "", "", "", "", "", "", "", "", "", "", "",
+			stringOrEmpty(entry.CefrLevel), rankOrEmpty(entry.FrequencyRank), "", "",
+			stringOrEmpty(entry.Visibility), "", ""})
+	}
+
+	for _, t := range entry.Translations {
+		translation := []string{
+			entry.Word, t.EnglishWord,
+			stringOrEmpty(t.PartOfSpeech), stringOrEmpty(t.Gender), stringOrEmpty(t.Aspect),
+			stringOrEmpty(t.AspectPartner), stringOrEmpty(t.UsageNote),
+		}
+		levels := []string{
+			stringOrEmpty(entry.CefrLevel), rankOrEmpty(entry.FrequencyRank),
+			stringOrEmpty(t.CefrLevel), rankOrEmpty(t.FrequencyRank),
+		}
+		visibilities := []string{stringOrEmpty(entry.Visibility), stringOrEmpty(t.Visibility)}
+
+		if len(t.ExampleSentences) == 0 {
+			if err := cw.writer.Write(slices.Concat(translation, []string{"", "", stringOrEmpty(t.Language), "", ""}, levels, visibilities, []string{""})); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, es := range t.ExampleSentences {
+			sentence := append(slices.Clip(translation), es.SentencePl, es.SentenceEn, stringOrEmpty(t.Language))
+			sentenceVisibility := []string{stringOrEmpty(es.Visibility)}
+
+			if len(es.Texts) == 0 {
+				if err := cw.writer.Write(slices.Concat(sentence, []string{"", ""}, levels, visibilities, sentenceVisibility)); err != nil {
+					return err
+				}
+				continue
+			}
+
+			for _, text := range es.Texts {
+				if err := cw.writer.Write(slices.Concat(sentence, []string{text.Language, text.Text}, levels, visibilities, sentenceVisibility)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (cw *csvWriter) Flush() error {
+	if !cw.header {
+		if err := cw.writer.Write(importer.CSVColumns); err != nil {
+			return err
+		}
+	}
+
+	cw.writer.Flush()
+	if err := cw.writer.Error(); err != nil {
+		return err
+	}
+	return cw.w.Flush()
+}
+
+// ankiWriter writes one note per Polish word: the word on the front, the
+// translations on the back and the example sentences in a notes field.
+// Words without translations are left out because they would make empty cards.
+type ankiWriter struct {
+	w      *bufio.Writer
+	header bool
+}
+
+func newAnkiWriter(w *bufio.Writer) *ankiWriter {
+	return &ankiWriter{w: w}
+}
+
+func (aw *ankiWriter) writeHeader() error {
+	if aw.header {
+		return nil
+	}
+	aw.header = true
+
+	_, err := aw.w.WriteString("#separator:tab\n#html:true\n#columns:Front\tBack\tNotes\n")
+	return err
+}
+
+func (aw *ankiWriter) Write(entry *model.AddPolishWordInput) error {
+	if err := aw.writeHeader(); err != nil {
+		return err
+	}
+
+	if len(entry.Translations) == 0 {
+		return nil
+	}
+
+	var translations []string
+	var sentences []string
+	for _, t := range entry.Translations {
+		translations = append(translations, ankiField(t.EnglishWord))
+		for _, es := range t.ExampleSentences {
+			sentences = append(sentences, ankiField(es.SentencePl)+" — "+ankiField(es.SentenceEn))
+		}
+	}
+
+	_, err := fmt.Fprintf(aw.w, "%s\t%s\t%s\n", ankiField(entry.Word), strings.Join(translations, "; "), strings.Join(sentences, "<br>"))
+	return err
+}
+
+func (aw *ankiWriter) Flush() error {
+	if err := aw.writeHeader(); err != nil {
+		return err
+	}
+	return aw.w.Flush()
+}
+
+func stringOrEmpty[S ~string](value *S) string {
+	if value == nil {
+		return ""
+	}
+	return string(*value)
+}
+
+func rankOrEmpty(rank *int) string {
+	if rank == nil {
+		return ""
+	}
+	return strconv.Itoa(*rank)
+}
+
+// ankiField escapes a value for an HTML-enabled, tab-separated Anki field.
+func ankiField(value string) string {
+	value = strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ").Replace(value)
+	return html.EscapeString(value)
+}
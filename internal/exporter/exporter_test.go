@@ -0,0 +1,146 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/importer"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticRepo struct {
+	entries []*model.AddPolishWordInput
+}
+
+func (sr *staticRepo) ForEachEntry(ctx context.Context, fn func(entry *model.AddPolishWordInput) error) error {
+	for _, entry := range sr.entries {
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type recordingImportRepo struct {
+	records []repository.ImportRecord
+}
+
+func (rr *recordingImportRepo) ImportBatch(ctx context.Context, records []repository.ImportRecord) ([]*model.ImportRowResult, error) {
+	rr.records = append(rr.records, records...)
+
+	results := make([]*model.ImportRowResult, len(records))
+	for i, record := range records {
+		results[i] = &model.ImportRowResult{Line: record.Line, Status: model.ImportStatusCreated}
+	}
+	return results, nil
+}
+
+func testEntries() []*model.AddPolishWordInput {
+	noun := model.PartOfSpeechNoun
+	gender := model.GenderMasculineInanimate
+	usageNote := "also a door lock"
+	german := "de"
+	b1 := model.CefrLevelB1
+	a2 := model.CefrLevelA2
+	a1 := model.CefrLevelA1
+	shared := model.VisibilityShared
+	private := model.VisibilityPrivate
+	wordRank := 1520
+	translationRank := 3000
+
+	return []*model.AddPolishWordInput{
+		{
+			Word:          "zamek",
+			CefrLevel:     &b1,
+			FrequencyRank: &wordRank,
+			Translations: []*model.AddTranslationInput{
+				{
+					EnglishWord:   "castle",
+					PartOfSpeech:  &noun,
+					Gender:        &gender,
+					CefrLevel:     &a2,
+					FrequencyRank: &translationRank,
+					ExampleSentences: []*model.AddExampleSentenceInput{
+						{SentencePl: "Zamek stoi na wzgórzu.", SentenceEn: "The castle stands on a hill.", Texts: []*model.SentenceTextInput{
+							{Language: "de", Text: "Das Schloss steht auf einem Hügel."},
+							{Language: "fr", Text: "Le château se dresse sur une colline."},
+						}},
+						{SentencePl: "Zwiedzamy zamek, \"Wawel\".", SentenceEn: "We visit the castle, \"Wawel\".", Visibility: &shared},
+					},
+				},
+				{EnglishWord: "lock", UsageNote: &usageNote, Visibility: &shared, ExampleSentences: []*model.AddExampleSentenceInput{}},
+				{EnglishWord: "Schloss", Language: &german, ExampleSentences: []*model.AddExampleSentenceInput{}},
+			},
+		},
+		{Word: "dom", CefrLevel: &a1, Visibility: &private, Translations: []*model.AddTranslationInput{}},
+	}
+}
+
+func TestJSONLExportRoundTripsThroughImport(t *testing.T) {
+
+	var out bytes.Buffer
+	require.NoError(t, Export(context.Background(), &staticRepo{entries: testEntries()}, &out, FormatJSONL))
+
+	importRepo := &recordingImportRepo{}
+	_, err := importer.Import(context.Background(), importRepo, &out, model.ImportFormatJSONL, 0)
+	require.NoError(t, err)
+
+	require.Len(t, importRepo.records, 2)
+	assert.Equal(t, *testEntries()[0], importRepo.records[0].Input)
+	assert.Equal(t, *testEntries()[1], importRepo.records[1].Input)
+}
+
+func TestCSVExportRoundTripsThroughImport(t *testing.T) {
+
+	var out bytes.Buffer
+	require.NoError(t, Export(context.Background(), &staticRepo{entries: testEntries()}, &out, FormatCSV))
+
+	assert.Equal(t, "word,english_word,part_of_speech,gender,aspect,aspect_partner,usage_note,sentence_pl,sentence_en,language,sentence_text_language,sentence_text,cefr_level,frequency_rank,translation_cefr_level,translation_frequency_rank,visibility,translation_visibility,sentence_visibility\n"+
+		"zamek,castle,NOUN,MASCULINE_INANIMATE,,,,Zamek stoi na wzgórzu.,The castle stands on a hill.,,de,Das Schloss steht auf einem Hügel.,B1,1520,A2,3000,,,\n"+
+		"zamek,castle,NOUN,MASCULINE_INANIMATE,,,,Zamek stoi na wzgórzu.,The castle stands on a hill.,,fr,Le château se dresse sur une colline.,B1,1520,A2,3000,,,\n"+
+		"zamek,castle,NOUN,MASCULINE_INANIMATE,,,,\"Zwiedzamy zamek, \"\"Wawel\"\".\",\"We visit the castle, \"\"Wawel\"\".\",,,,B1,1520,A2,3000,,,SHARED\n"+
+		"zamek,lock,,,,,also a door lock,,,,,,B1,1520,,,,SHARED,\n"+
+		"zamek,Schloss,,,,,,,,de,,,B1,1520,,,,,\n"+
+		"dom,,,,,,,,,,,,A1,,,,PRIVATE,,\n", out.String())
+
+	importRepo := &recordingImportRepo{}
+	report, err := importer.Import(context.Background(), importRepo, &out, model.ImportFormatCSV, 0)
+	require.NoError(t, err)
+	assert.Zero(t, report.Failed)
+
+	require.Len(t, importRepo.records, 6)
+	assert.Equal(t, []*model.SentenceTextInput{{Language: "de", Text: "Das Schloss steht auf einem Hügel."}}, importRepo.records[0].Input.Translations[0].ExampleSentences[0].Texts)
+	assert.Equal(t, []*model.SentenceTextInput{{Language: "fr", Text: "Le château se dresse sur une colline."}}, importRepo.records[1].Input.Translations[0].ExampleSentences[0].Texts)
+	assert.Equal(t, "Zwiedzamy zamek, \"Wawel\".", importRepo.records[2].Input.Translations[0].ExampleSentences[0].SentencePl)
+	assert.Nil(t, importRepo.records[2].Input.Translations[0].ExampleSentences[0].Texts)
+	assert.Equal(t, model.VisibilityShared, *importRepo.records[2].Input.Translations[0].ExampleSentences[0].Visibility)
+	assert.Nil(t, importRepo.records[2].Input.Translations[0].Visibility)
+	assert.Equal(t, model.GenderMasculineInanimate, *importRepo.records[2].Input.Translations[0].Gender)
+	assert.Equal(t, model.CefrLevelB1, *importRepo.records[2].Input.CefrLevel)
+	assert.Equal(t, 1520, *importRepo.records[2].Input.FrequencyRank)
+	assert.Equal(t, model.CefrLevelA2, *importRepo.records[2].Input.Translations[0].CefrLevel)
+	assert.Equal(t, 3000, *importRepo.records[2].Input.Translations[0].FrequencyRank)
+	assert.Nil(t, importRepo.records[3].Input.Translations[0].CefrLevel)
+	assert.Equal(t, "lock", importRepo.records[3].Input.Translations[0].EnglishWord)
+	assert.Equal(t, "also a door lock", *importRepo.records[3].Input.Translations[0].UsageNote)
+	assert.Equal(t, model.VisibilityShared, *importRepo.records[3].Input.Translations[0].Visibility)
+	assert.Equal(t, "de", *importRepo.records[4].Input.Translations[0].Language)
+	assert.Nil(t, importRepo.records[3].Input.Translations[0].Language)
+	assert.Equal(t, "dom", importRepo.records[5].Input.Word)
+	assert.Equal(t, model.CefrLevelA1, *importRepo.records[5].Input.CefrLevel)
+	assert.Equal(t, model.VisibilityPrivate, *importRepo.records[5].Input.Visibility)
+}
+
+func TestAnkiExport(t *testing.T) {
+
+	var out bytes.Buffer
+	require.NoError(t, Export(context.Background(), &staticRepo{entries: testEntries()}, &out, FormatAnki))
+
+	assert.Equal(t, "#separator:tab\n#html:true\n#columns:Front\tBack\tNotes\n"+
+		"zamek\tcastle; lock; Schloss\tZamek stoi na wzgórzu. — The castle stands on a hill.<br>"+
+		"Zwiedzamy zamek, &#34;Wawel&#34;. — We visit the castle, &#34;Wawel&#34;.\n", out.String())
+}
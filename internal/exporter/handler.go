@@ -0,0 +1,39 @@
+package exporter
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/repository"
+)
+
+// Handler serves the dictionary as a download, e.g. GET /export?format=csv.
+// The format defaults to JSONL.
+func Handler(repo repository.ExportRepositoryInterface) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		format := Format(r.URL.Query().Get("format"))
+		if format == "" {
+			format = FormatJSONL
+		}
+		if !format.IsValid() {
+			http.Error(w, fmt.Sprintf("unsupported format %q, use jsonl, csv or anki", format), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", format.FileName()))
+
+		// Once streaming has started the status code is already sent, so a
+		// failure can only be logged and the download ends truncated.
+		if err := Export(r.Context(), repo, w, format); err != nil {
+			log.Printf("export failed: %v", err)
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package frequency
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Load reads a frequency list, most common word first, and returns its words
+// lowercased in that order, so a word's rank is its index plus one. Each line
+// holds a word and optionally numeric columns such as a rank or a count,
+// separated by tabs or spaces; "1 się 12345" and "się\t12345" both name się.
+// Blank lines and lines starting with # are skipped, and a word listed again
+// in another case keeps its first rank.
+func Load(r io.Reader) ([]string, error) {
+	var words []string
+	seen := map[string]bool{}
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		word := ""
+		for _, field := range strings.Fields(text) {
+			if _, err := strconv.ParseFloat(field, 64); err != nil {
+				word = strings.ToLower(field)
+				break
+			}
+		}
+		if word == "" {
+			return nil, fmt.Errorf("line %d: expected a word", line)
+		}
+
+		if !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return words, nil
+}
@@ -0,0 +1,24 @@
+package frequency
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadRanksWordsInFileOrder(t *testing.T) {
+
+	words, err := Load(strings.NewReader("# word count\n\nsię\t12345\n2 nie 9876\nSię 40\nw\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"się", "nie", "w"}, words)
+}
+
+func TestLoadRejectsLinesWithoutWord(t *testing.T) {
+
+	_, err := Load(strings.NewReader("się 12345\n3 9876\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 2")
+}
@@ -0,0 +1,111 @@
+package loaders
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBatchWait    = 2 * time.Millisecond
+	defaultMaxBatchSize = 500
+)
+
+// BatchLoader collects keys requested within a short window and resolves them
+// with a single call to fetch. Results are cached for the lifetime of the
+// loader, which is meant to be a single request.
+type BatchLoader[K comparable, V any] struct {
+	fetch        func(ctx context.Context, keys []K) (map[K]V, error)
+	wait         time.Duration
+	maxBatchSize int
+
+	mu    sync.Mutex
+	cache map[K]*loadResult[V]
+	batch *pendingBatch[K, V]
+}
+
+type loadResult[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+type pendingBatch[K comparable, V any] struct {
+	keys       []K
+	results    []*loadResult[V]
+	dispatched bool
+}
+
+func NewBatchLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *BatchLoader[K, V] {
+	return &BatchLoader[K, V]{
+		fetch:        fetch,
+		wait:         defaultBatchWait,
+		maxBatchSize: defaultMaxBatchSize,
+		cache:        map[K]*loadResult[V]{},
+	}
+}
+
+func (l *BatchLoader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+
+	if result, ok := l.cache[key]; ok {
+		l.mu.Unlock()
+		return result.wait(ctx)
+	}
+
+	result := &loadResult[V]{done: make(chan struct{})}
+	l.cache[key] = result
+
+	if l.batch == nil {
+		l.batch = &pendingBatch[K, V]{}
+		batch := l.batch
+		time.AfterFunc(l.wait, func() { l.dispatch(ctx, batch) })
+	}
+
+	l.batch.keys = append(l.batch.keys, key)
+	l.batch.results = append(l.batch.results, result)
+
+	if len(l.batch.keys) >= l.maxBatchSize {
+		go l.dispatch(ctx, l.batch)
+		l.batch = nil
+	}
+
+	l.mu.Unlock()
+
+	return result.wait(ctx)
+}
+
+func (l *BatchLoader[K, V]) dispatch(ctx context.Context, batch *pendingBatch[K, V]) {
+	l.mu.Lock()
+	if batch.dispatched {
+		l.mu.Unlock()
+		return
+	}
+	batch.dispatched = true
+	if l.batch == batch {
+		l.batch = nil
+	}
+	l.mu.Unlock()
+
+	values, err := l.fetch(context.WithoutCancel(ctx), batch.keys)
+
+	for i, key := range batch.keys {
+		result := batch.results[i]
+		if err != nil {
+			result.err = err
+		} else {
+			result.value = values[key]
+		}
+		close(result.done)
+	}
+}
+
+func (r *loadResult[V]) wait(ctx context.Context) (V, error) {
+	select {
+	case <-r.done:
+		return r.value, r.err
+	case <-ctx.Done():
+		var zeroValue V
+		return zeroValue, ctx.Err()
+	}
+}
@@ -0,0 +1,102 @@
+package loaders
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchLoaderBatchesConcurrentLoads(t *testing.T) {
+
+	var mu sync.Mutex
+	var batches [][]string
+
+	loader := NewBatchLoader(func(ctx context.Context, keys []string) (map[string]string, error) {
+		mu.Lock()
+		batches = append(batches, append([]string(nil), keys...))
+		mu.Unlock()
+
+		values := map[string]string{}
+		for _, key := range keys {
+			values[key] = "value-" + key
+		}
+		return values, nil
+	})
+
+	keys := []string{"1", "2", "3", "2", "1"}
+	results := make([]string, len(keys))
+
+	var wg sync.WaitGroup
+	wg.Add(len(keys))
+	for i, key := range keys {
+		go func(i int, key string) {
+			defer wg.Done()
+			value, err := loader.Load(context.Background(), key)
+			assert.NoError(t, err)
+			results[i] = value
+		}(i, key)
+	}
+	wg.Wait()
+
+	require.Len(t, batches, 1)
+	sort.Strings(batches[0])
+	assert.Equal(t, []string{"1", "2", "3"}, batches[0])
+	assert.Equal(t, []string{"value-1", "value-2", "value-3", "value-2", "value-1"}, results)
+
+	value, err := loader.Load(context.Background(), "3")
+	require.NoError(t, err)
+	assert.Equal(t, "value-3", value)
+	assert.Len(t, batches, 1)
+}
+
+func TestBatchLoaderSplitsLargeBatches(t *testing.T) {
+
+	var mu sync.Mutex
+	var batchSizes []int
+
+	loader := NewBatchLoader(func(ctx context.Context, keys []int) (map[int]int, error) {
+		mu.Lock()
+		batchSizes = append(batchSizes, len(keys))
+		mu.Unlock()
+
+		values := map[int]int{}
+		for _, key := range keys {
+			values[key] = key * 2
+		}
+		return values, nil
+	})
+	loader.maxBatchSize = 2
+
+	var wg sync.WaitGroup
+	wg.Add(5)
+	for i := 0; i < 5; i++ {
+		go func(key int) {
+			defer wg.Done()
+			value, err := loader.Load(context.Background(), key)
+			assert.NoError(t, err)
+			assert.Equal(t, key*2, value)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.GreaterOrEqual(t, len(batchSizes), 3)
+	for _, size := range batchSizes {
+		assert.LessOrEqual(t, size, 2)
+	}
+}
+
+func TestBatchLoaderPropagatesErrors(t *testing.T) {
+
+	loader := NewBatchLoader(func(ctx context.Context, keys []string) (map[string]string, error) {
+		return nil, errors.New("database is down")
+	})
+
+	_, err := loader.Load(context.Background(), "1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "database is down")
+}
@@ -0,0 +1,94 @@
+package loaders
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/repository"
+)
+
+type contextKey struct{}
+
+type Loaders struct {
+	PolishWordByID                  *BatchLoader[string, *model.PolishWord]
+	TranslationByID                 *BatchLoader[string, *model.Translation]
+	TranslationsByPolishWordID      *BatchLoader[string, []*model.Translation]
+	ExampleSentencesByTranslationID *BatchLoader[string, []*model.ExampleSentence]
+	InflectionsByPolishWordID       *BatchLoader[string, []*model.Inflection]
+	PronunciationByPolishWordID     *BatchLoader[string, *model.Pronunciation]
+	LevelByPolishWordID             *BatchLoader[string, *repository.Level]
+	LevelByTranslationID            *BatchLoader[string, *repository.Level]
+	HistoryByPolishWordID           *BatchLoader[string, []*model.Revision]
+	AudioByPolishWordID             *BatchLoader[string, *model.Audio]
+	AudioByExampleSentenceID        *BatchLoader[string, *model.Audio]
+	LexemeByID                      *BatchLoader[string, *model.Lexeme]
+	RelationsByPolishWordID         *BatchLoader[string, []*model.WordRelation]
+	TagsByPolishWordID              *BatchLoader[string, []*model.Tag]
+	TagsByTranslationID             *BatchLoader[string, []*model.Tag]
+	AccessByPolishWordID            *BatchLoader[string, *repository.Access]
+	AccessByTranslationID           *BatchLoader[string, *repository.Access]
+	AccessByExampleSentenceID       *BatchLoader[string, *repository.Access]
+	TextsByExampleSentenceID        *BatchLoader[string, []*model.SentenceText]
+}
+
+func NewLoaders(
+	polishWordRepo repository.PolishWordRepositoryInterface,
+	translationRepo repository.TranslationRepositoryInterface,
+	exampleSentenceRepo repository.ExampleSentenceRepositoryInterface,
+	inflectionRepo repository.InflectionRepositoryInterface,
+	audioRepo repository.AudioRepositoryInterface,
+	lexemeRepo repository.LexemeRepositoryInterface,
+	relationRepo repository.RelationRepositoryInterface,
+	tagRepo repository.TagRepositoryInterface,
+) *Loaders {
+	return &Loaders{
+		PolishWordByID:                  NewBatchLoader(polishWordRepo.GetPolishWordsByIDs),
+		TranslationByID:                 NewBatchLoader(translationRepo.GetTranslationsByIDs),
+		TranslationsByPolishWordID:      NewBatchLoader(translationRepo.GetTranslationsByPolishWordIDs),
+		ExampleSentencesByTranslationID: NewBatchLoader(exampleSentenceRepo.GetExampleSentencesByTranslationIDs),
+		InflectionsByPolishWordID:       NewBatchLoader(inflectionRepo.GetInflectionsByPolishWordIDs),
+		PronunciationByPolishWordID:     NewBatchLoader(polishWordRepo.GetPronunciationsByPolishWordIDs),
+		LevelByPolishWordID:             NewBatchLoader(polishWordRepo.GetLevelsByPolishWordIDs),
+		LevelByTranslationID:            NewBatchLoader(translationRepo.GetLevelsByTranslationIDs),
+		HistoryByPolishWordID:           NewBatchLoader(polishWordRepo.GetRevisionsByPolishWordIDs),
+		AudioByPolishWordID:             NewBatchLoader(audioRepo.GetAudioByPolishWordIDs),
+		AudioByExampleSentenceID:        NewBatchLoader(audioRepo.GetAudioByExampleSentenceIDs),
+		LexemeByID:                      NewBatchLoader(lexemeRepo.GetLexemesByIDs),
+		RelationsByPolishWordID:         NewBatchLoader(relationRepo.GetRelationsByPolishWordIDs),
+		TagsByPolishWordID:              NewBatchLoader(tagRepo.GetTagsByPolishWordIDs),
+		TagsByTranslationID:             NewBatchLoader(tagRepo.GetTagsByTranslationIDs),
+		AccessByPolishWordID:            NewBatchLoader(polishWordRepo.GetAccessByPolishWordIDs),
+		AccessByTranslationID:           NewBatchLoader(translationRepo.GetAccessByTranslationIDs),
+		AccessByExampleSentenceID:       NewBatchLoader(exampleSentenceRepo.GetAccessByExampleSentenceIDs),
+		TextsByExampleSentenceID:        NewBatchLoader(exampleSentenceRepo.GetTextsByExampleSentenceIDs),
+	}
+}
+
+// Middleware attaches a fresh set of loaders to every request, so cached
+// entities never outlive the request that loaded them.
+func Middleware(
+	polishWordRepo repository.PolishWordRepositoryInterface,
+	translationRepo repository.TranslationRepositoryInterface,
+	exampleSentenceRepo repository.ExampleSentenceRepositoryInterface,
+	inflectionRepo repository.InflectionRepositoryInterface,
+	audioRepo repository.AudioRepositoryInterface,
+	lexemeRepo repository.LexemeRepositoryInterface,
+	relationRepo repository.RelationRepositoryInterface,
+	tagRepo repository.TagRepositoryInterface,
+	next http.Handler,
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		loaders := NewLoaders(polishWordRepo, translationRepo, exampleSentenceRepo, inflectionRepo, audioRepo, lexemeRepo, relationRepo, tagRepo)
+		next.ServeHTTP(w, r.WithContext(WithLoaders(r.Context(), loaders)))
+	})
+}
+
+func WithLoaders(ctx context.Context, loaders *Loaders) context.Context {
+	return context.WithValue(ctx, contextKey{}, loaders)
+}
+
+func For(ctx context.Context) *Loaders {
+	loaders, _ := ctx.Value(contextKey{}).(*Loaders)
+	return loaders
+}
@@ -2,37 +2,211 @@
 
 package model
 
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+type DictionaryEntry interface {
+	IsDictionaryEntry()
+}
+
+type SearchResult interface {
+	IsSearchResult()
+}
+
 type AddExampleSentenceInput struct {
 	SentencePl string `json:"sentencePl"`
 	SentenceEn string `json:"sentenceEn"`
+	// The sentence in languages other than those of the lexeme and the translation.
+	Texts []*SentenceTextInput `json:"texts,omitempty"`
+	// Defaults to the visibility of the translation.
+	Visibility *Visibility `json:"visibility,omitempty"`
+}
+
+type AddInflectionInput struct {
+	Form   string             `json:"form"`
+	Case   *GrammaticalCase   `json:"case,omitempty"`
+	Number *GrammaticalNumber `json:"number,omitempty"`
+	Person *Person            `json:"person,omitempty"`
+	Tense  *Tense             `json:"tense,omitempty"`
+	Gender *Gender            `json:"gender,omitempty"`
+}
+
+// Needs a text in the lexeme's language and one in the translation's language, and may have texts in further languages.
+type AddLexemeExampleSentenceInput struct {
+	Texts []*SentenceTextInput `json:"texts"`
+}
+
+type AddLexemeInput struct {
+	// BCP-47 tag, e.g. pl, de or pt-BR.
+	Language     string                       `json:"language"`
+	Lemma        string                       `json:"lemma"`
+	Translations []*AddLexemeTranslationInput `json:"translations,omitempty"`
+}
+
+type AddLexemeTranslationInput struct {
+	// BCP-47 tag of the target language. It must differ from the lexeme's language.
+	Language         string                           `json:"language"`
+	Text             string                           `json:"text"`
+	PartOfSpeech     *PartOfSpeech                    `json:"partOfSpeech,omitempty"`
+	Gender           *Gender                          `json:"gender,omitempty"`
+	Aspect           *Aspect                          `json:"aspect,omitempty"`
+	AspectPartner    *string                          `json:"aspectPartner,omitempty"`
+	UsageNote        *string                          `json:"usageNote,omitempty"`
+	ExampleSentences []*AddLexemeExampleSentenceInput `json:"exampleSentences,omitempty"`
 }
 
 type AddPolishWordInput struct {
-	Word         string                 `json:"word"`
-	Translations []*AddTranslationInput `json:"translations"`
+	Word          string                 `json:"word"`
+	Translations  []*AddTranslationInput `json:"translations"`
+	Pronunciation *PronunciationInput    `json:"pronunciation,omitempty"`
+	CefrLevel     *CefrLevel             `json:"cefrLevel,omitempty"`
+	FrequencyRank *int                   `json:"frequencyRank,omitempty"`
+	// Defaults to PUBLIC. A public or shared word that is already in the
+	// dictionary is added to and keeps its visibility.
+	Visibility *Visibility `json:"visibility,omitempty"`
 }
 
 type AddTranslationInput struct {
-	EnglishWord      string                     `json:"englishWord"`
+	EnglishWord string `json:"englishWord"`
+	// BCP-47 tag of the language of englishWord. Defaults to en.
+	Language     *string       `json:"language,omitempty"`
+	PartOfSpeech *PartOfSpeech `json:"partOfSpeech,omitempty"`
+	Gender       *Gender       `json:"gender,omitempty"`
+	Aspect       *Aspect       `json:"aspect,omitempty"`
+	// The verb of the other aspect, e.g. zrobić for robić.
+	AspectPartner *string    `json:"aspectPartner,omitempty"`
+	UsageNote     *string    `json:"usageNote,omitempty"`
+	CefrLevel     *CefrLevel `json:"cefrLevel,omitempty"`
+	FrequencyRank *int       `json:"frequencyRank,omitempty"`
+	// Defaults to the visibility of the word.
+	Visibility       *Visibility                `json:"visibility,omitempty"`
 	ExampleSentences []*AddExampleSentenceInput `json:"exampleSentences"`
 }
 
+type AddWordRelationInput struct {
+	PolishWordID  *string      `json:"polishWordId,omitempty"`
+	PolishWord    *string      `json:"polishWord,omitempty"`
+	RelatedWordID *string      `json:"relatedWordId,omitempty"`
+	RelatedWord   *string      `json:"relatedWord,omitempty"`
+	Type          RelationType `json:"type"`
+}
+
+type Audio struct {
+	// A signed link that streams the recording. It expires, so fetch a fresh one instead of storing it.
+	URL         string `json:"url"`
+	ContentType string `json:"contentType"`
+	// Size in bytes.
+	Size int `json:"size"`
+	// Name of the recording in the blob store, used to sign url.
+	Key string `json:"-"`
+}
+
+// A mutation as recorded in the audit log. JSON fields are given as text.
+type AuditEntry struct {
+	ID         string    `json:"id"`
+	OccurredAt time.Time `json:"occurredAt"`
+	// The mutation field, e.g. updatePolishWord.
+	Operation string `json:"operation"`
+	// The name of the GraphQL operation it was part of, if it had one.
+	OperationName *string `json:"operationName,omitempty"`
+	// The arguments as JSON.
+	Arguments string `json:"arguments"`
+	// Entries it changed or returned, such as polish_word:12 or inflection:3.
+	AffectedIds []string `json:"affectedIds"`
+	// JSON object of the changed words, translations and sentences before the mutation, keyed like affectedIds.
+	Before string `json:"before"`
+	// The same entries after the mutation.
+	After string `json:"after"`
+	// The caller, or null when it was anonymous.
+	Actor     *string `json:"actor,omitempty"`
+	ClientIP  *string `json:"clientIp,omitempty"`
+	RequestID string  `json:"requestId"`
+	// Why the mutation failed, if it did.
+	Error *string `json:"error,omitempty"`
+}
+
+type AuditEntryEdge struct {
+	Cursor string      `json:"cursor"`
+	Node   *AuditEntry `json:"node"`
+}
+
+type AuditLogConnection struct {
+	Edges    []*AuditEntryEdge `json:"edges"`
+	PageInfo *PageInfo         `json:"pageInfo"`
+}
+
+type AuditLogFilter struct {
+	Actor      *string    `json:"actor,omitempty"`
+	Operation  *string    `json:"operation,omitempty"`
+	AffectedID *string    `json:"affectedId,omitempty"`
+	Since      *time.Time `json:"since,omitempty"`
+	Until      *time.Time `json:"until,omitempty"`
+}
+
 type EditExampleSentenceInput struct {
+	ID         *string `json:"id,omitempty"`
 	SentencePl *string `json:"sentencePl,omitempty"`
 	SentenceEn *string `json:"sentenceEn,omitempty"`
-	Version    int     `json:"version"`
+	// Sets the sentence in languages other than those of the lexeme and the translation.
+	Texts []*SentenceTextInput `json:"texts,omitempty"`
+	// Languages to remove the sentence in. The texts in the languages of the lexeme and the translation cannot be removed.
+	RemoveTexts []string `json:"removeTexts,omitempty"`
+	// Only the owner or an admin may change it. New sentences default to the visibility of the translation.
+	Visibility *Visibility `json:"visibility,omitempty"`
+	Version    *int        `json:"version,omitempty"`
+}
+
+type EditInflectionInput struct {
+	Form *string `json:"form,omitempty"`
+	// Setting a case clears person and tense.
+	Case   *GrammaticalCase   `json:"case,omitempty"`
+	Number *GrammaticalNumber `json:"number,omitempty"`
+	// Setting a person or tense clears the case.
+	Person  *Person `json:"person,omitempty"`
+	Tense   *Tense  `json:"tense,omitempty"`
+	Gender  *Gender `json:"gender,omitempty"`
+	Version int     `json:"version"`
 }
 
 type EditPolishWordInput struct {
 	Word         *string                 `json:"word,omitempty"`
 	Translations []*EditTranslationInput `json:"translations,omitempty"`
-	Version      int                     `json:"version"`
+	Remove       []string                `json:"remove,omitempty"`
+	// An empty string clears ipa or syllabification and 0 clears stressedSyllable.
+	Pronunciation *PronunciationInput `json:"pronunciation,omitempty"`
+	CefrLevel     *CefrLevel          `json:"cefrLevel,omitempty"`
+	// 0 clears the rank.
+	FrequencyRank *int `json:"frequencyRank,omitempty"`
+	// Only the owner or an admin may change it.
+	Visibility *Visibility `json:"visibility,omitempty"`
+	Version    int         `json:"version"`
 }
 
 type EditTranslationInput struct {
-	EnglishWord      *string                     `json:"englishWord,omitempty"`
+	ID          *string `json:"id,omitempty"`
+	EnglishWord *string `json:"englishWord,omitempty"`
+	// BCP-47 tag. New translations default to en.
+	Language *string `json:"language,omitempty"`
+	// Changing the part of speech clears gender, aspect and aspectPartner when they no longer apply.
+	PartOfSpeech *PartOfSpeech `json:"partOfSpeech,omitempty"`
+	Gender       *Gender       `json:"gender,omitempty"`
+	Aspect       *Aspect       `json:"aspect,omitempty"`
+	// An empty string clears the aspect partner.
+	AspectPartner *string `json:"aspectPartner,omitempty"`
+	// An empty string clears the usage note.
+	UsageNote *string    `json:"usageNote,omitempty"`
+	CefrLevel *CefrLevel `json:"cefrLevel,omitempty"`
+	// 0 clears the rank.
+	FrequencyRank *int `json:"frequencyRank,omitempty"`
+	// Only the owner or an admin may change it. New translations default to the visibility of the word.
+	Visibility       *Visibility                 `json:"visibility,omitempty"`
 	ExampleSentences []*EditExampleSentenceInput `json:"exampleSentences,omitempty"`
-	Version          int                         `json:"version"`
+	Remove           []string                    `json:"remove,omitempty"`
+	Version          *int                        `json:"version,omitempty"`
 }
 
 type ExampleSentence struct {
@@ -40,26 +214,1029 @@ type ExampleSentence struct {
 	Translation *Translation `json:"translation"`
 	SentencePl  string       `json:"sentencePl"`
 	SentenceEn  string       `json:"sentenceEn"`
-	Version     int          `json:"version"`
+	// The sentence in every language it has: first the language of the lexeme,
+	// then that of the translation, then further languages in alphabetical order.
+	Texts      []*SentenceText `json:"texts"`
+	Audio      *Audio          `json:"audio,omitempty"`
+	Visibility Visibility      `json:"visibility"`
+	Owner      *string         `json:"owner,omitempty"`
+	Version    int             `json:"version"`
+	// Set on a past state of the sentence, whose own fields are all filled in and must not be loaded.
+	Past bool `json:"-"`
+	// ID of the translation, used to load translation when it is not already set.
+	TranslationID string `json:"-"`
 }
 
-type Mutation struct {
+func (ExampleSentence) IsSearchResult() {}
+
+func (ExampleSentence) IsDictionaryEntry() {}
+
+// Old and new values are given as text; null means the field was empty.
+type FieldChange struct {
+	Field string  `json:"field"`
+	From  *string `json:"from,omitempty"`
+	To    *string `json:"to,omitempty"`
 }
 
-type PolishWord struct {
-	ID           string         `json:"id"`
-	Word         string         `json:"word"`
+type ImportReport struct {
+	Created int                `json:"created"`
+	Merged  int                `json:"merged"`
+	Skipped int                `json:"skipped"`
+	Failed  int                `json:"failed"`
+	Rows    []*ImportRowResult `json:"rows"`
+}
+
+type ImportRowResult struct {
+	Line   int          `json:"line"`
+	Word   *string      `json:"word,omitempty"`
+	Status ImportStatus `json:"status"`
+	Error  *string      `json:"error,omitempty"`
+}
+
+// An inflected form of a Polish word. Noun and adjective forms have a case and
+// a number; verb forms have a tense, a person and a number. Gender is used for
+// adjective forms and for the past tense.
+type Inflection struct {
+	ID         string             `json:"id"`
+	Form       string             `json:"form"`
+	Case       *GrammaticalCase   `json:"case,omitempty"`
+	Number     *GrammaticalNumber `json:"number,omitempty"`
+	Person     *Person            `json:"person,omitempty"`
+	Tense      *Tense             `json:"tense,omitempty"`
+	Gender     *Gender            `json:"gender,omitempty"`
+	PolishWord *PolishWord        `json:"polishWord"`
+	Version    int                `json:"version"`
+	// ID of the Polish word, used to load polishWord when it is not already set.
+	PolishWordID string `json:"-"`
+}
+
+// A headword in any source language. Polish words are the lexemes with
+// language pl; polishWord and englishWord are views of them and their English
+// translations.
+type Lexeme struct {
+	ID string `json:"id"`
+	// BCP-47 tag of the source language, e.g. pl or de.
+	Language string `json:"language"`
+	Lemma    string `json:"lemma"`
+	// Translations into language, or into every language when it is left out.
 	Translations []*Translation `json:"translations"`
 	Version      int            `json:"version"`
 }
 
+type Mutation struct {
+}
+
+type PageInfo struct {
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor,omitempty"`
+	EndCursor       *string `json:"endCursor,omitempty"`
+}
+
+type PolishWord struct {
+	ID   string `json:"id"`
+	Word string `json:"word"`
+	// Only English translations unless another language is asked for; null returns all of them.
+	Translations  []*Translation `json:"translations"`
+	Inflections   []*Inflection  `json:"inflections"`
+	Pronunciation *Pronunciation `json:"pronunciation,omitempty"`
+	Audio         *Audio         `json:"audio,omitempty"`
+	// Links to related words, optionally of one type.
+	Related   []*WordRelation `json:"related"`
+	Tags      []*Tag          `json:"tags"`
+	CefrLevel *CefrLevel      `json:"cefrLevel,omitempty"`
+	// Position in the frequency list, 1 being the most common word.
+	FrequencyRank *int `json:"frequencyRank,omitempty"`
+	// Every recorded revision of the word's own fields, oldest first.
+	History    []*Revision `json:"history"`
+	Visibility Visibility  `json:"visibility"`
+	// The caller who created the word, null for words added before owners were recorded.
+	Owner   *string `json:"owner,omitempty"`
+	Version int     `json:"version"`
+	// Set on a past state of the word, whose own fields are all filled in and must not be loaded.
+	Past bool `json:"-"`
+}
+
+func (PolishWord) IsSearchResult() {}
+
+func (PolishWord) IsDictionaryEntry() {}
+
+type PolishWordConnection struct {
+	Edges    []*PolishWordEdge `json:"edges"`
+	PageInfo *PageInfo         `json:"pageInfo"`
+}
+
+type PolishWordEdge struct {
+	Cursor string      `json:"cursor"`
+	Node   *PolishWord `json:"node"`
+}
+
+type PolishWordFilter struct {
+	// Only words with at least one translation of this part of speech.
+	PartOfSpeech *PartOfSpeech `json:"partOfSpeech,omitempty"`
+	// Only words tagged with these tags, as tagMatch decides.
+	Tags     []string  `json:"tags,omitempty"`
+	TagMatch *TagMatch `json:"tagMatch,omitempty"`
+	// Only words at one of these levels.
+	CefrLevels []CefrLevel `json:"cefrLevels,omitempty"`
+	// Only words ranked this common or more, i.e. with a frequencyRank of at most this.
+	MaxFrequencyRank *int `json:"maxFrequencyRank,omitempty"`
+}
+
+type Pronunciation struct {
+	Ipa *string `json:"ipa,omitempty"`
+	// Syllables separated by hyphens, e.g. za-mek.
+	Syllabification *string `json:"syllabification,omitempty"`
+	// 1-based index of the stressed syllable.
+	StressedSyllable *int `json:"stressedSyllable,omitempty"`
+}
+
+// Fields left out are generated from the spelling when the server has a generator enabled.
+type PronunciationInput struct {
+	Ipa              *string `json:"ipa,omitempty"`
+	Syllabification  *string `json:"syllabification,omitempty"`
+	StressedSyllable *int    `json:"stressedSyllable,omitempty"`
+}
+
 type Query struct {
 }
 
+// A recorded state of an entry.
+type Revision struct {
+	Version int `json:"version"`
+	// Who made the change, when known.
+	Author    *string   `json:"author,omitempty"`
+	ChangedAt time.Time `json:"changedAt"`
+	// The fields that changed since the previous revision.
+	Changes []*FieldChange `json:"changes"`
+}
+
+type SearchHit struct {
+	Score   float64      `json:"score"`
+	Snippet string       `json:"snippet"`
+	Result  SearchResult `json:"result"`
+}
+
+type SentenceText struct {
+	Language string `json:"language"`
+	Text     string `json:"text"`
+}
+
+type SentenceTextInput struct {
+	Language string `json:"language"`
+	Text     string `json:"text"`
+}
+
+// A topic such as kitchen or travel. Names are matched without regard to case.
+type Tag struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Exactly one of a Polish word, given by id or spelling, or a translation.
+type TagTargetInput struct {
+	PolishWordID  *string `json:"polishWordId,omitempty"`
+	PolishWord    *string `json:"polishWord,omitempty"`
+	TranslationID *string `json:"translationId,omitempty"`
+}
+
+type TagUsage struct {
+	Tag              *Tag `json:"tag"`
+	PolishWordCount  int  `json:"polishWordCount"`
+	TranslationCount int  `json:"translationCount"`
+}
+
 type Translation struct {
-	ID               string             `json:"id"`
-	EnglishWord      string             `json:"englishWord"`
+	ID string `json:"id"`
+	// The translated text, whatever its language. Same as text.
+	EnglishWord string `json:"englishWord"`
+	// BCP-47 tag of the language the translation is in, e.g. en or pt-BR.
+	Language         string             `json:"language"`
+	Text             string             `json:"text"`
+	PartOfSpeech     *PartOfSpeech      `json:"partOfSpeech,omitempty"`
+	Gender           *Gender            `json:"gender,omitempty"`
+	Aspect           *Aspect            `json:"aspect,omitempty"`
+	AspectPartner    *string            `json:"aspectPartner,omitempty"`
+	UsageNote        *string            `json:"usageNote,omitempty"`
 	PolishWord       *PolishWord        `json:"polishWord"`
+	Lexeme           *Lexeme            `json:"lexeme"`
 	ExampleSentences []*ExampleSentence `json:"exampleSentences"`
-	Version          int                `json:"version"`
+	Tags             []*Tag             `json:"tags"`
+	CefrLevel        *CefrLevel         `json:"cefrLevel,omitempty"`
+	// Position in the frequency list, 1 being the most common word.
+	FrequencyRank *int       `json:"frequencyRank,omitempty"`
+	Visibility    Visibility `json:"visibility"`
+	Owner         *string    `json:"owner,omitempty"`
+	Version       int        `json:"version"`
+	// Set on a past state of the translation, whose own fields are all filled in and must not be loaded.
+	Past bool `json:"-"`
+	// ID of the Polish word, used to load polishWord when it is not already set.
+	PolishWordID string `json:"-"`
+}
+
+func (Translation) IsSearchResult() {}
+
+func (Translation) IsDictionaryEntry() {}
+
+// An entry that was deleted on its own rather than along with its parent.
+type TrashEntry struct {
+	// Pass it to restore.
+	ID   string    `json:"id"`
+	Kind TrashKind `json:"kind"`
+	// The word, the translation or the Polish sentence.
+	Text string `json:"text"`
+	// RFC 3339 time of the deletion.
+	DeletedAt string `json:"deletedAt"`
+	// How many translations and example sentences were deleted along with it.
+	Dependents int `json:"dependents"`
+}
+
+type WordRelation struct {
+	ID   string       `json:"id"`
+	Type RelationType `json:"type"`
+	Word *PolishWord  `json:"word"`
+	// True when the link points the other way: the words whose HYPERNYM,
+	// DIMINUTIVE or DERIVED_FROM is the word that was asked for. Always false for
+	// symmetric types.
+	Inverse bool `json:"inverse"`
+	// ID of the related word, used to load word when it is not already set.
+	WordID string `json:"-"`
+}
+
+type Aspect string
+
+const (
+	AspectPerfective   Aspect = "PERFECTIVE"
+	AspectImperfective Aspect = "IMPERFECTIVE"
+)
+
+var AllAspect = []Aspect{
+	AspectPerfective,
+	AspectImperfective,
+}
+
+func (e Aspect) IsValid() bool {
+	switch e {
+	case AspectPerfective, AspectImperfective:
+		return true
+	}
+	return false
+}
+
+func (e Aspect) String() string {
+	return string(e)
+}
+
+func (e *Aspect) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Aspect(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Aspect", str)
+	}
+	return nil
+}
+
+func (e Aspect) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// Common European Framework of Reference level, from A1 for beginners to C2.
+type CefrLevel string
+
+const (
+	CefrLevelA1 CefrLevel = "A1"
+	CefrLevelA2 CefrLevel = "A2"
+	CefrLevelB1 CefrLevel = "B1"
+	CefrLevelB2 CefrLevel = "B2"
+	CefrLevelC1 CefrLevel = "C1"
+	CefrLevelC2 CefrLevel = "C2"
+)
+
+var AllCefrLevel = []CefrLevel{
+	CefrLevelA1,
+	CefrLevelA2,
+	CefrLevelB1,
+	CefrLevelB2,
+	CefrLevelC1,
+	CefrLevelC2,
+}
+
+func (e CefrLevel) IsValid() bool {
+	switch e {
+	case CefrLevelA1, CefrLevelA2, CefrLevelB1, CefrLevelB2, CefrLevelC1, CefrLevelC2:
+		return true
+	}
+	return false
+}
+
+func (e CefrLevel) String() string {
+	return string(e)
+}
+
+func (e *CefrLevel) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CefrLevel(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CefrLevel", str)
+	}
+	return nil
+}
+
+func (e CefrLevel) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// Grammatical gender of a Polish noun.
+type Gender string
+
+const (
+	GenderMasculinePersonal  Gender = "MASCULINE_PERSONAL"
+	GenderMasculineAnimate   Gender = "MASCULINE_ANIMATE"
+	GenderMasculineInanimate Gender = "MASCULINE_INANIMATE"
+	GenderFeminine           Gender = "FEMININE"
+	GenderNeuter             Gender = "NEUTER"
+)
+
+var AllGender = []Gender{
+	GenderMasculinePersonal,
+	GenderMasculineAnimate,
+	GenderMasculineInanimate,
+	GenderFeminine,
+	GenderNeuter,
+}
+
+func (e Gender) IsValid() bool {
+	switch e {
+	case GenderMasculinePersonal, GenderMasculineAnimate, GenderMasculineInanimate, GenderFeminine, GenderNeuter:
+		return true
+	}
+	return false
+}
+
+func (e Gender) String() string {
+	return string(e)
+}
+
+func (e *Gender) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Gender(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Gender", str)
+	}
+	return nil
+}
+
+func (e Gender) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type GrammaticalCase string
+
+const (
+	GrammaticalCaseNominative   GrammaticalCase = "NOMINATIVE"
+	GrammaticalCaseGenitive     GrammaticalCase = "GENITIVE"
+	GrammaticalCaseDative       GrammaticalCase = "DATIVE"
+	GrammaticalCaseAccusative   GrammaticalCase = "ACCUSATIVE"
+	GrammaticalCaseInstrumental GrammaticalCase = "INSTRUMENTAL"
+	GrammaticalCaseLocative     GrammaticalCase = "LOCATIVE"
+	GrammaticalCaseVocative     GrammaticalCase = "VOCATIVE"
+)
+
+var AllGrammaticalCase = []GrammaticalCase{
+	GrammaticalCaseNominative,
+	GrammaticalCaseGenitive,
+	GrammaticalCaseDative,
+	GrammaticalCaseAccusative,
+	GrammaticalCaseInstrumental,
+	GrammaticalCaseLocative,
+	GrammaticalCaseVocative,
+}
+
+func (e GrammaticalCase) IsValid() bool {
+	switch e {
+	case GrammaticalCaseNominative, GrammaticalCaseGenitive, GrammaticalCaseDative, GrammaticalCaseAccusative, GrammaticalCaseInstrumental, GrammaticalCaseLocative, GrammaticalCaseVocative:
+		return true
+	}
+	return false
+}
+
+func (e GrammaticalCase) String() string {
+	return string(e)
+}
+
+func (e *GrammaticalCase) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = GrammaticalCase(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid GrammaticalCase", str)
+	}
+	return nil
+}
+
+func (e GrammaticalCase) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type GrammaticalNumber string
+
+const (
+	GrammaticalNumberSingular GrammaticalNumber = "SINGULAR"
+	GrammaticalNumberPlural   GrammaticalNumber = "PLURAL"
+)
+
+var AllGrammaticalNumber = []GrammaticalNumber{
+	GrammaticalNumberSingular,
+	GrammaticalNumberPlural,
+}
+
+func (e GrammaticalNumber) IsValid() bool {
+	switch e {
+	case GrammaticalNumberSingular, GrammaticalNumberPlural:
+		return true
+	}
+	return false
+}
+
+func (e GrammaticalNumber) String() string {
+	return string(e)
+}
+
+func (e *GrammaticalNumber) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = GrammaticalNumber(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid GrammaticalNumber", str)
+	}
+	return nil
+}
+
+func (e GrammaticalNumber) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ImportFormat string
+
+const (
+	ImportFormatJSONL ImportFormat = "JSONL"
+	ImportFormatCSV   ImportFormat = "CSV"
+)
+
+var AllImportFormat = []ImportFormat{
+	ImportFormatJSONL,
+	ImportFormatCSV,
+}
+
+func (e ImportFormat) IsValid() bool {
+	switch e {
+	case ImportFormatJSONL, ImportFormatCSV:
+		return true
+	}
+	return false
+}
+
+func (e ImportFormat) String() string {
+	return string(e)
+}
+
+func (e *ImportFormat) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ImportFormat(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ImportFormat", str)
+	}
+	return nil
+}
+
+func (e ImportFormat) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ImportStatus string
+
+const (
+	ImportStatusCreated ImportStatus = "CREATED"
+	ImportStatusMerged  ImportStatus = "MERGED"
+	ImportStatusSkipped ImportStatus = "SKIPPED"
+	ImportStatusFailed  ImportStatus = "FAILED"
+)
+
+var AllImportStatus = []ImportStatus{
+	ImportStatusCreated,
+	ImportStatusMerged,
+	ImportStatusSkipped,
+	ImportStatusFailed,
+}
+
+func (e ImportStatus) IsValid() bool {
+	switch e {
+	case ImportStatusCreated, ImportStatusMerged, ImportStatusSkipped, ImportStatusFailed:
+		return true
+	}
+	return false
+}
+
+func (e ImportStatus) String() string {
+	return string(e)
+}
+
+func (e *ImportStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ImportStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ImportStatus", str)
+	}
+	return nil
+}
+
+func (e ImportStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type PartOfSpeech string
+
+const (
+	PartOfSpeechNoun         PartOfSpeech = "NOUN"
+	PartOfSpeechVerb         PartOfSpeech = "VERB"
+	PartOfSpeechAdjective    PartOfSpeech = "ADJECTIVE"
+	PartOfSpeechAdverb       PartOfSpeech = "ADVERB"
+	PartOfSpeechPronoun      PartOfSpeech = "PRONOUN"
+	PartOfSpeechNumeral      PartOfSpeech = "NUMERAL"
+	PartOfSpeechPreposition  PartOfSpeech = "PREPOSITION"
+	PartOfSpeechConjunction  PartOfSpeech = "CONJUNCTION"
+	PartOfSpeechParticle     PartOfSpeech = "PARTICLE"
+	PartOfSpeechInterjection PartOfSpeech = "INTERJECTION"
+)
+
+var AllPartOfSpeech = []PartOfSpeech{
+	PartOfSpeechNoun,
+	PartOfSpeechVerb,
+	PartOfSpeechAdjective,
+	PartOfSpeechAdverb,
+	PartOfSpeechPronoun,
+	PartOfSpeechNumeral,
+	PartOfSpeechPreposition,
+	PartOfSpeechConjunction,
+	PartOfSpeechParticle,
+	PartOfSpeechInterjection,
+}
+
+func (e PartOfSpeech) IsValid() bool {
+	switch e {
+	case PartOfSpeechNoun, PartOfSpeechVerb, PartOfSpeechAdjective, PartOfSpeechAdverb, PartOfSpeechPronoun, PartOfSpeechNumeral, PartOfSpeechPreposition, PartOfSpeechConjunction, PartOfSpeechParticle, PartOfSpeechInterjection:
+		return true
+	}
+	return false
+}
+
+func (e PartOfSpeech) String() string {
+	return string(e)
+}
+
+func (e *PartOfSpeech) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PartOfSpeech(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PartOfSpeech", str)
+	}
+	return nil
+}
+
+func (e PartOfSpeech) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type Person string
+
+const (
+	PersonFirst  Person = "FIRST"
+	PersonSecond Person = "SECOND"
+	PersonThird  Person = "THIRD"
+)
+
+var AllPerson = []Person{
+	PersonFirst,
+	PersonSecond,
+	PersonThird,
+}
+
+func (e Person) IsValid() bool {
+	switch e {
+	case PersonFirst, PersonSecond, PersonThird:
+		return true
+	}
+	return false
+}
+
+func (e Person) String() string {
+	return string(e)
+}
+
+func (e *Person) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Person(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Person", str)
+	}
+	return nil
+}
+
+func (e Person) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type PolishWordOrder string
+
+const (
+	// Most common first. Words without a rank come last.
+	PolishWordOrderFrequencyRank PolishWordOrder = "FREQUENCY_RANK"
+	// A1 first. Words without a level come last.
+	PolishWordOrderCefrLevel PolishWordOrder = "CEFR_LEVEL"
+)
+
+var AllPolishWordOrder = []PolishWordOrder{
+	PolishWordOrderFrequencyRank,
+	PolishWordOrderCefrLevel,
+}
+
+func (e PolishWordOrder) IsValid() bool {
+	switch e {
+	case PolishWordOrderFrequencyRank, PolishWordOrderCefrLevel:
+		return true
+	}
+	return false
+}
+
+func (e PolishWordOrder) String() string {
+	return string(e)
+}
+
+func (e *PolishWordOrder) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PolishWordOrder(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PolishWordOrder", str)
+	}
+	return nil
+}
+
+func (e PolishWordOrder) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// SYNONYM, ANTONYM and ASPECT_PAIR are symmetric. The directed types read from
+// the word that was asked for: its HYPERNYM is a broader word (pies → zwierzę),
+// its DIMINUTIVE is a diminutive of it (kot → kotek) and DERIVED_FROM is the word
+// it was formed from (pisarz → pisać).
+type RelationType string
+
+const (
+	RelationTypeSynonym     RelationType = "SYNONYM"
+	RelationTypeAntonym     RelationType = "ANTONYM"
+	RelationTypeHypernym    RelationType = "HYPERNYM"
+	RelationTypeDiminutive  RelationType = "DIMINUTIVE"
+	RelationTypeAspectPair  RelationType = "ASPECT_PAIR"
+	RelationTypeDerivedFrom RelationType = "DERIVED_FROM"
+)
+
+var AllRelationType = []RelationType{
+	RelationTypeSynonym,
+	RelationTypeAntonym,
+	RelationTypeHypernym,
+	RelationTypeDiminutive,
+	RelationTypeAspectPair,
+	RelationTypeDerivedFrom,
+}
+
+func (e RelationType) IsValid() bool {
+	switch e {
+	case RelationTypeSynonym, RelationTypeAntonym, RelationTypeHypernym, RelationTypeDiminutive, RelationTypeAspectPair, RelationTypeDerivedFrom:
+		return true
+	}
+	return false
+}
+
+func (e RelationType) String() string {
+	return string(e)
+}
+
+func (e *RelationType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = RelationType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid RelationType", str)
+	}
+	return nil
+}
+
+func (e RelationType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// Roles are ordered: an admin may do whatever an editor may, and an editor whatever a reader may. Every identified caller is a reader.
+type Role string
+
+const (
+	RoleReader Role = "READER"
+	RoleEditor Role = "EDITOR"
+	RoleAdmin  Role = "ADMIN"
+)
+
+var AllRole = []Role{
+	RoleReader,
+	RoleEditor,
+	RoleAdmin,
+}
+
+func (e Role) IsValid() bool {
+	switch e {
+	case RoleReader, RoleEditor, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+func (e Role) String() string {
+	return string(e)
+}
+
+func (e *Role) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Role(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Role", str)
+	}
+	return nil
+}
+
+func (e Role) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type SearchScope string
+
+const (
+	SearchScopePolishWords      SearchScope = "POLISH_WORDS"
+	SearchScopeTranslations     SearchScope = "TRANSLATIONS"
+	SearchScopeExampleSentences SearchScope = "EXAMPLE_SENTENCES"
+)
+
+var AllSearchScope = []SearchScope{
+	SearchScopePolishWords,
+	SearchScopeTranslations,
+	SearchScopeExampleSentences,
+}
+
+func (e SearchScope) IsValid() bool {
+	switch e {
+	case SearchScopePolishWords, SearchScopeTranslations, SearchScopeExampleSentences:
+		return true
+	}
+	return false
+}
+
+func (e SearchScope) String() string {
+	return string(e)
+}
+
+func (e *SearchScope) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SearchScope(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SearchScope", str)
+	}
+	return nil
+}
+
+func (e SearchScope) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type TagMatch string
+
+const (
+	// Words with every one of the tags.
+	TagMatchAll TagMatch = "ALL"
+	// Words with at least one of the tags.
+	TagMatchAny TagMatch = "ANY"
+)
+
+var AllTagMatch = []TagMatch{
+	TagMatchAll,
+	TagMatchAny,
+}
+
+func (e TagMatch) IsValid() bool {
+	switch e {
+	case TagMatchAll, TagMatchAny:
+		return true
+	}
+	return false
+}
+
+func (e TagMatch) String() string {
+	return string(e)
+}
+
+func (e *TagMatch) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = TagMatch(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid TagMatch", str)
+	}
+	return nil
+}
+
+func (e TagMatch) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type Tense string
+
+const (
+	TensePresent Tense = "PRESENT"
+	TensePast    Tense = "PAST"
+	TenseFuture  Tense = "FUTURE"
+)
+
+var AllTense = []Tense{
+	TensePresent,
+	TensePast,
+	TenseFuture,
+}
+
+func (e Tense) IsValid() bool {
+	switch e {
+	case TensePresent, TensePast, TenseFuture:
+		return true
+	}
+	return false
+}
+
+func (e Tense) String() string {
+	return string(e)
+}
+
+func (e *Tense) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Tense(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Tense", str)
+	}
+	return nil
+}
+
+func (e Tense) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type TrashKind string
+
+const (
+	TrashKindPolishWord      TrashKind = "POLISH_WORD"
+	TrashKindTranslation     TrashKind = "TRANSLATION"
+	TrashKindExampleSentence TrashKind = "EXAMPLE_SENTENCE"
+)
+
+var AllTrashKind = []TrashKind{
+	TrashKindPolishWord,
+	TrashKindTranslation,
+	TrashKindExampleSentence,
+}
+
+func (e TrashKind) IsValid() bool {
+	switch e {
+	case TrashKindPolishWord, TrashKindTranslation, TrashKindExampleSentence:
+		return true
+	}
+	return false
+}
+
+func (e TrashKind) String() string {
+	return string(e)
+}
+
+func (e *TrashKind) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = TrashKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid TrashKind", str)
+	}
+	return nil
+}
+
+func (e TrashKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// Who may see an entry. An entry is never more visible than the word or
+// translation it belongs to, and the entries of a private one are private to the
+// same owner.
+type Visibility string
+
+const (
+	// Everyone, including anonymous callers.
+	VisibilityPublic Visibility = "PUBLIC"
+	// Every identified caller.
+	VisibilityShared Visibility = "SHARED"
+	// Only the owner. Private entries form the owner's personal dictionary and may repeat entries of the shared one.
+	VisibilityPrivate Visibility = "PRIVATE"
+)
+
+var AllVisibility = []Visibility{
+	VisibilityPublic,
+	VisibilityShared,
+	VisibilityPrivate,
+}
+
+func (e Visibility) IsValid() bool {
+	switch e {
+	case VisibilityPublic, VisibilityShared, VisibilityPrivate:
+		return true
+	}
+	return false
+}
+
+func (e Visibility) String() string {
+	return string(e)
+}
+
+func (e *Visibility) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Visibility(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Visibility", str)
+	}
+	return nil
+}
+
+func (e Visibility) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
 }
@@ -0,0 +1,32 @@
+package resolver
+
+import (
+	"context"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/apperror"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/auth"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/generated"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+)
+
+// Directives implements the schema directives.
+var Directives = generated.DirectiveRoot{
+	HasRole: HasRole,
+}
+
+// HasRole resolves a field only for callers with role, see auth.Principal.HasRole.
+func HasRole(ctx context.Context, obj any, next graphql.Resolver, role model.Role) (any, error) {
+	required := auth.Role(strings.ToLower(string(role)))
+	if !auth.PrincipalFrom(ctx).HasRole(required) {
+		action := "do this"
+		if fc := graphql.GetFieldContext(ctx); fc != nil {
+			action = "use " + fc.Field.Name
+		}
+		return nil, &apperror.ForbiddenError{Action: action, RequiredRole: string(required)}
+	}
+
+	return next(ctx)
+}
@@ -1,8 +1,14 @@
 package resolver
 
 import (
+	"context"
 	"database/sql"
+	"strings"
 
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/apperror"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/audio"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/loaders"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
 	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/repository"
 )
 
@@ -15,4 +21,81 @@ type Resolver struct {
 	PolishWordRepo      repository.PolishWordRepositoryInterface
 	TranslationRepo     repository.TranslationRepositoryInterface
 	ExampleSentenceRepo repository.ExampleSentenceRepositoryInterface
+	InflectionRepo      repository.InflectionRepositoryInterface
+	SearchRepo          repository.SearchRepositoryInterface
+	ImportRepo          repository.ImportRepositoryInterface
+	AudioRepo           repository.AudioRepositoryInterface
+	LexemeRepo          repository.LexemeRepositoryInterface
+	RelationRepo        repository.RelationRepositoryInterface
+	TagRepo             repository.TagRepositoryInterface
+	TrashRepo           repository.TrashRepositoryInterface
+	AuditRepo           repository.AuditRepositoryInterface
+	AudioRecorder       *audio.Recorder
+	AudioURLs           *audio.URLSigner
+}
+
+// loaders returns the request-scoped loaders, falling back to unshared ones
+// when the resolver is called outside of the HTTP middleware.
+func (r *Resolver) loaders(ctx context.Context) *loaders.Loaders {
+	if l := loaders.For(ctx); l != nil {
+		return l
+	}
+	return loaders.NewLoaders(r.PolishWordRepo, r.TranslationRepo, r.ExampleSentenceRepo, r.InflectionRepo, r.AudioRepo, r.LexemeRepo, r.RelationRepo, r.TagRepo)
+}
+
+// loadAccess loads who owns and who may see the entity with id. An entity
+// purged since it was loaded is reported as not found.
+func loadAccess(ctx context.Context, loader *loaders.BatchLoader[string, *repository.Access], entity string, id string) (*repository.Access, error) {
+	access, err := loader.Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if access == nil {
+		return nil, &apperror.NotFoundError{Entity: entity, Field: "id", Value: id}
+	}
+	return access, nil
+}
+
+func filterByPartOfSpeech(translations []*model.Translation, partOfSpeech *model.PartOfSpeech) []*model.Translation {
+	if partOfSpeech == nil {
+		return translations
+	}
+
+	filtered := []*model.Translation{}
+	for _, t := range translations {
+		if t.PartOfSpeech != nil && *t.PartOfSpeech == *partOfSpeech {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
+
+func filterByRelationType(relations []*model.WordRelation, relationType *model.RelationType) []*model.WordRelation {
+	if relationType == nil {
+		return relations
+	}
+
+	filtered := []*model.WordRelation{}
+	for _, relation := range relations {
+		if relation.Type == *relationType {
+			filtered = append(filtered, relation)
+		}
+	}
+	return filtered
+}
+
+// filterByLanguage keeps the translations into language, matched without
+// regard to case as BCP-47 tags are. A nil language keeps them all.
+func filterByLanguage(translations []*model.Translation, language *string) []*model.Translation {
+	if language == nil {
+		return translations
+	}
+
+	filtered := []*model.Translation{}
+	for _, t := range translations {
+		if strings.EqualFold(t.Language, *language) {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
 }
@@ -3,13 +3,23 @@ package resolver
 import (
 	"context"
 	"errors"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/apperror"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/audio"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/auth"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/loaders"
 	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
 	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/mocks"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/repository"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
 )
 
 func setupTestMutationResolver() (*mocks.MockPolishWordRepository, *mutationResolver) {
@@ -536,9 +546,9 @@ func TestGetAllPolishWords(t *testing.T) {
 		},
 	}
 
-	mockRepo.On("GetAllPolishWords", mock.Anything).Return(expected, nil).Once()
+	mockRepo.On("GetAllPolishWords", mock.Anything, (*model.PolishWordFilter)(nil), (*model.PolishWordOrder)(nil)).Return(expected, nil).Once()
 
-	result, err := query.PolishWordRepo.GetAllPolishWords(context.Background())
+	result, err := query.PolishWordRepo.GetAllPolishWords(context.Background(), nil, nil)
 	require.NoError(t, err)
 	assert.Equal(t, expected, result)
 
@@ -606,3 +616,419 @@ func TestGetExampleSentencesByTranslationId(t *testing.T) {
 
 	mockRepo.AssertExpectations(t)
 }
+
+func TestPolishWordsConnection(t *testing.T) {
+	mockRepo, _ := setupTestMutationResolver()
+	query := &queryResolver{Resolver: &Resolver{PolishWordRepo: mockRepo}}
+
+	first := 1
+	cursor := "cG9saXNod29yZDox"
+
+	expected := &model.PolishWordConnection{
+		Edges: []*model.PolishWordEdge{
+			{
+				Cursor: cursor,
+				Node: &model.PolishWord{
+					ID:   "1",
+					Word: "test_word",
+				},
+			},
+		},
+		PageInfo: &model.PageInfo{
+			HasNextPage: true,
+			StartCursor: &cursor,
+			EndCursor:   &cursor,
+		},
+	}
+
+	mockRepo.On("GetPolishWordsPage", mock.Anything, &first, (*string)(nil), (*int)(nil), (*string)(nil), (*model.PolishWordFilter)(nil)).Return(expected, nil).Once()
+
+	result, err := query.PolishWordsConnection(context.Background(), &first, nil, nil, nil, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, expected, result)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEnglishWord(t *testing.T) {
+	mockRepo := new(mocks.MockTranslationRepository)
+	query := &queryResolver{Resolver: &Resolver{TranslationRepo: mockRepo}}
+
+	expected := []*model.Translation{
+		{
+			ID:          "1",
+			EnglishWord: "castle",
+			PolishWord: &model.PolishWord{
+				ID:   "1",
+				Word: "zamek",
+			},
+		},
+	}
+
+	mockRepo.On("GetTranslationsByEnglishWord", mock.Anything, "castle", (*model.PartOfSpeech)(nil)).Return(expected, nil).Once()
+
+	result, err := query.EnglishWord(context.Background(), "castle", nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, expected, result)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPolishWordTranslationsAreBatched(t *testing.T) {
+	mockPolishWordRepo := new(mocks.MockPolishWordRepository)
+	mockTranslationRepo := new(mocks.MockTranslationRepository)
+	mockExampleSentenceRepo := new(mocks.MockExampleSentenceRepository)
+	r := &Resolver{
+		PolishWordRepo:      mockPolishWordRepo,
+		TranslationRepo:     mockTranslationRepo,
+		ExampleSentenceRepo: mockExampleSentenceRepo,
+	}
+	ctx := loaders.WithLoaders(context.Background(), loaders.NewLoaders(mockPolishWordRepo, mockTranslationRepo, mockExampleSentenceRepo, new(mocks.MockInflectionRepository), new(mocks.MockAudioRepository), new(mocks.MockLexemeRepository), new(mocks.MockRelationRepository), new(mocks.MockTagRepository)))
+
+	expected := map[string][]*model.Translation{
+		"1": {
+			{ID: "10", EnglishWord: "cat", Language: "en", PolishWordID: "1"},
+			{ID: "11", EnglishWord: "Katze", Language: "de", PolishWordID: "1"},
+		},
+		"2": {},
+	}
+
+	mockTranslationRepo.On("GetTranslationsByPolishWordIDs", mock.Anything, mock.MatchedBy(func(ids []string) bool {
+		return assert.ElementsMatch(t, []string{"1", "2"}, ids)
+	})).Return(expected, nil).Once()
+
+	english := "en"
+	words := []*model.PolishWord{{ID: "1", Word: "kot"}, {ID: "2", Word: "pies"}}
+	results := make([][]*model.Translation, len(words))
+
+	var wg sync.WaitGroup
+	wg.Add(len(words))
+	for i, word := range words {
+		go func(i int, word *model.PolishWord) {
+			defer wg.Done()
+			translations, err := r.PolishWord().Translations(ctx, word, nil, &english)
+			assert.NoError(t, err)
+			results[i] = translations
+		}(i, word)
+	}
+	wg.Wait()
+
+	assert.Equal(t, expected["1"][:1], results[0])
+	assert.Equal(t, expected["2"], results[1])
+
+	mockTranslationRepo.AssertExpectations(t)
+}
+
+func TestTranslationPolishWordUsesPreloadedValue(t *testing.T) {
+	mockPolishWordRepo := new(mocks.MockPolishWordRepository)
+	r := &Resolver{PolishWordRepo: mockPolishWordRepo}
+
+	preloaded := &model.PolishWord{ID: "1", Word: "kot"}
+
+	result, err := r.Translation().PolishWord(context.Background(), &model.Translation{ID: "10", PolishWord: preloaded, PolishWordID: "1"})
+
+	require.NoError(t, err)
+	assert.Same(t, preloaded, result)
+
+	mockPolishWordRepo.AssertNotCalled(t, "GetPolishWordsByIDs", mock.Anything, mock.Anything)
+}
+
+func TestAddInflection(t *testing.T) {
+	mockRepo := new(mocks.MockInflectionRepository)
+	mutation := &mutationResolver{Resolver: &Resolver{InflectionRepo: mockRepo}}
+
+	word := "zamek"
+	genitive := model.GrammaticalCaseGenitive
+	singular := model.GrammaticalNumberSingular
+	input := model.AddInflectionInput{Form: "zamku", Case: &genitive, Number: &singular}
+	expected := &model.Inflection{ID: "5", Form: "zamku", Case: &genitive, Number: &singular, PolishWordID: "1"}
+
+	mockRepo.On("AddInflection", mock.Anything, (*string)(nil), &word, input).Return(expected, nil).Once()
+
+	result, err := mutation.AddInflection(context.Background(), nil, &word, input)
+
+	require.NoError(t, err)
+	assert.Equal(t, expected, result)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPolishWordInflectionsAreLoadedByPolishWordID(t *testing.T) {
+	mockInflectionRepo := new(mocks.MockInflectionRepository)
+	r := &Resolver{InflectionRepo: mockInflectionRepo}
+	ctx := loaders.WithLoaders(context.Background(), loaders.NewLoaders(
+		new(mocks.MockPolishWordRepository), new(mocks.MockTranslationRepository), new(mocks.MockExampleSentenceRepository), mockInflectionRepo, new(mocks.MockAudioRepository), new(mocks.MockLexemeRepository), new(mocks.MockRelationRepository), new(mocks.MockTagRepository)))
+
+	expected := []*model.Inflection{{ID: "5", Form: "zamku", PolishWordID: "1"}}
+
+	mockInflectionRepo.On("GetInflectionsByPolishWordIDs", mock.Anything, []string{"1"}).
+		Return(map[string][]*model.Inflection{"1": expected}, nil).Once()
+
+	result, err := r.PolishWord().Inflections(ctx, &model.PolishWord{ID: "1", Word: "zamek"})
+
+	require.NoError(t, err)
+	assert.Equal(t, expected, result)
+
+	mockInflectionRepo.AssertExpectations(t)
+}
+
+func TestPolishWordPronunciationIsLoadedByPolishWordID(t *testing.T) {
+	mockPolishWordRepo := new(mocks.MockPolishWordRepository)
+	r := &Resolver{PolishWordRepo: mockPolishWordRepo}
+	ctx := loaders.WithLoaders(context.Background(), loaders.NewLoaders(
+		mockPolishWordRepo, new(mocks.MockTranslationRepository), new(mocks.MockExampleSentenceRepository), new(mocks.MockInflectionRepository), new(mocks.MockAudioRepository), new(mocks.MockLexemeRepository), new(mocks.MockRelationRepository), new(mocks.MockTagRepository)))
+
+	ipa := "ˈza.mɛk"
+	expected := &model.Pronunciation{Ipa: &ipa}
+
+	mockPolishWordRepo.On("GetPronunciationsByPolishWordIDs", mock.Anything, []string{"1"}).
+		Return(map[string]*model.Pronunciation{"1": expected}, nil).Once()
+
+	result, err := r.PolishWord().Pronunciation(ctx, &model.PolishWord{ID: "1", Word: "zamek"})
+
+	require.NoError(t, err)
+	assert.Equal(t, expected, result)
+
+	mockPolishWordRepo.AssertExpectations(t)
+}
+
+func TestPolishWordAudioIsLoadedAndSigned(t *testing.T) {
+	mockAudioRepo := new(mocks.MockAudioRepository)
+	r := &Resolver{AudioRepo: mockAudioRepo, AudioURLs: &audio.URLSigner{Secret: []byte("secret")}}
+	ctx := loaders.WithLoaders(context.Background(), loaders.NewLoaders(
+		new(mocks.MockPolishWordRepository), new(mocks.MockTranslationRepository), new(mocks.MockExampleSentenceRepository), new(mocks.MockInflectionRepository), mockAudioRepo, new(mocks.MockLexemeRepository), new(mocks.MockRelationRepository), new(mocks.MockTagRepository)))
+
+	stored := &model.Audio{Key: "a1.mp3", ContentType: "audio/mpeg", Size: 2048}
+
+	mockAudioRepo.On("GetAudioByPolishWordIDs", mock.Anything, []string{"1"}).
+		Return(map[string]*model.Audio{"1": stored}, nil).Once()
+
+	result, err := r.PolishWord().Audio(ctx, &model.PolishWord{ID: "1", Word: "zamek"})
+	require.NoError(t, err)
+	assert.Equal(t, stored, result)
+
+	url, err := r.Audio().URL(ctx, result)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(url, "/audio/a1.mp3?expires="))
+
+	mockAudioRepo.AssertExpectations(t)
+}
+
+func TestExampleSentenceTextsAreLoaded(t *testing.T) {
+	mockExampleSentenceRepo := new(mocks.MockExampleSentenceRepository)
+	r := &Resolver{ExampleSentenceRepo: mockExampleSentenceRepo}
+	ctx := loaders.WithLoaders(context.Background(), loaders.NewLoaders(
+		new(mocks.MockPolishWordRepository), new(mocks.MockTranslationRepository), mockExampleSentenceRepo, new(mocks.MockInflectionRepository), new(mocks.MockAudioRepository), new(mocks.MockLexemeRepository), new(mocks.MockRelationRepository), new(mocks.MockTagRepository)))
+
+	stored := []*model.SentenceText{
+		{Language: "pl", Text: "Kot śpi."},
+		{Language: "en", Text: "The cat sleeps."},
+		{Language: "de", Text: "Die Katze schläft."},
+	}
+	mockExampleSentenceRepo.On("GetTextsByExampleSentenceIDs", mock.Anything, []string{"3"}).
+		Return(map[string][]*model.SentenceText{"3": stored}, nil).Once()
+
+	texts, err := r.ExampleSentence().Texts(ctx, &model.ExampleSentence{ID: "3", SentencePl: "Kot śpi.", SentenceEn: "The cat sleeps.", TranslationID: "2"})
+
+	require.NoError(t, err)
+	assert.Equal(t, stored, texts)
+
+	mockExampleSentenceRepo.AssertExpectations(t)
+}
+
+func TestPastExampleSentenceTextsAreTaggedWithLanguages(t *testing.T) {
+	mockLexemeRepo := new(mocks.MockLexemeRepository)
+	r := &Resolver{LexemeRepo: mockLexemeRepo}
+	ctx := loaders.WithLoaders(context.Background(), loaders.NewLoaders(
+		new(mocks.MockPolishWordRepository), new(mocks.MockTranslationRepository), new(mocks.MockExampleSentenceRepository), new(mocks.MockInflectionRepository), new(mocks.MockAudioRepository), mockLexemeRepo, new(mocks.MockRelationRepository), new(mocks.MockTagRepository)))
+
+	mockLexemeRepo.On("GetLexemesByIDs", mock.Anything, []string{"1"}).
+		Return(map[string]*model.Lexeme{"1": {ID: "1", Language: "pl", Lemma: "kot"}}, nil).Once()
+
+	es := &model.ExampleSentence{
+		ID:          "3",
+		SentencePl:  "Kot śpi.",
+		SentenceEn:  "Die Katze schläft.",
+		Translation: &model.Translation{ID: "2", EnglishWord: "Katze", Language: "de", PolishWordID: "1"},
+		Past:        true,
+	}
+
+	texts, err := r.ExampleSentence().Texts(ctx, es)
+
+	require.NoError(t, err)
+	assert.Equal(t, []*model.SentenceText{
+		{Language: "pl", Text: "Kot śpi."},
+		{Language: "de", Text: "Die Katze schläft."},
+	}, texts)
+
+	mockLexemeRepo.AssertExpectations(t)
+}
+
+func TestImportDictionary(t *testing.T) {
+	mockRepo := new(mocks.MockImportRepository)
+	mutation := &mutationResolver{Resolver: &Resolver{ImportRepo: mockRepo}}
+
+	word := "kot"
+	mockRepo.On("ImportBatch", mock.Anything, []repository.ImportRecord{
+		{Line: 1, Input: model.AddPolishWordInput{Word: word}},
+	}).Return([]*model.ImportRowResult{
+		{Line: 1, Word: &word, Status: model.ImportStatusMerged},
+	}, nil).Once()
+
+	file := graphql.Upload{File: strings.NewReader(`{"word":"kot"}` + "\n"), Filename: "words.jsonl"}
+
+	report, err := mutation.ImportDictionary(context.Background(), file, model.ImportFormatJSONL)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Merged)
+	require.Len(t, report.Rows, 1)
+	assert.Equal(t, model.ImportStatusMerged, report.Rows[0].Status)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPolishWordRelatedIsFilteredByType(t *testing.T) {
+	mockRelationRepo := new(mocks.MockRelationRepository)
+	r := &Resolver{RelationRepo: mockRelationRepo}
+	ctx := loaders.WithLoaders(context.Background(), loaders.NewLoaders(
+		new(mocks.MockPolishWordRepository), new(mocks.MockTranslationRepository), new(mocks.MockExampleSentenceRepository), new(mocks.MockInflectionRepository), new(mocks.MockAudioRepository), new(mocks.MockLexemeRepository), mockRelationRepo, new(mocks.MockTagRepository)))
+
+	synonym := &model.WordRelation{ID: "1", Type: model.RelationTypeSynonym, WordID: "2"}
+	hypernym := &model.WordRelation{ID: "3", Type: model.RelationTypeHypernym, WordID: "4", Inverse: true}
+
+	mockRelationRepo.On("GetRelationsByPolishWordIDs", mock.Anything, []string{"1"}).
+		Return(map[string][]*model.WordRelation{"1": {synonym, hypernym}}, nil).Once()
+
+	relationType := model.RelationTypeHypernym
+	result, err := r.PolishWord().Related(ctx, &model.PolishWord{ID: "1", Word: "pies"}, &relationType)
+	require.NoError(t, err)
+	assert.Equal(t, []*model.WordRelation{hypernym}, result)
+
+	result, err = r.PolishWord().Related(ctx, &model.PolishWord{ID: "1", Word: "pies"}, nil)
+	require.NoError(t, err)
+	assert.Len(t, result, 2)
+
+	mockRelationRepo.AssertExpectations(t)
+}
+
+func TestPolishWordsAreFilteredByTags(t *testing.T) {
+	mockRepo := new(mocks.MockPolishWordRepository)
+	query := &queryResolver{&Resolver{PolishWordRepo: mockRepo}}
+
+	match := model.TagMatchAny
+	expected := []*model.PolishWord{{ID: "1", Word: "garnek"}}
+
+	mockRepo.On("GetAllPolishWords", mock.Anything, &model.PolishWordFilter{Tags: []string{"kitchen", "travel"}, TagMatch: &match}, (*model.PolishWordOrder)(nil)).
+		Return(expected, nil).Once()
+
+	result, err := query.PolishWords(context.Background(), []string{"kitchen", "travel"}, &match, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, expected, result)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTranslationLevelIsLoadedByTranslationID(t *testing.T) {
+	mockTranslationRepo := new(mocks.MockTranslationRepository)
+	r := &Resolver{TranslationRepo: mockTranslationRepo}
+	ctx := loaders.WithLoaders(context.Background(), loaders.NewLoaders(
+		new(mocks.MockPolishWordRepository), mockTranslationRepo, new(mocks.MockExampleSentenceRepository), new(mocks.MockInflectionRepository), new(mocks.MockAudioRepository), new(mocks.MockLexemeRepository), new(mocks.MockRelationRepository), new(mocks.MockTagRepository)))
+
+	cefrLevel := model.CefrLevelB2
+	rank := 1200
+
+	mockTranslationRepo.On("GetLevelsByTranslationIDs", mock.Anything, []string{"2"}).
+		Return(map[string]*repository.Level{"2": {CefrLevel: &cefrLevel, FrequencyRank: &rank}}, nil).Once()
+
+	translation := &model.Translation{ID: "2", EnglishWord: "castle"}
+
+	level, err := r.Translation().CefrLevel(ctx, translation)
+	require.NoError(t, err)
+	assert.Equal(t, &cefrLevel, level)
+
+	frequencyRank, err := r.Translation().FrequencyRank(ctx, translation)
+	require.NoError(t, err)
+	assert.Equal(t, &rank, frequencyRank)
+
+	mockTranslationRepo.AssertExpectations(t)
+}
+
+func TestPolishWordAccessIsLoadedOnce(t *testing.T) {
+	mockRepo := new(mocks.MockPolishWordRepository)
+	r := &Resolver{PolishWordRepo: mockRepo}
+	ctx := loaders.WithLoaders(context.Background(), loaders.NewLoaders(
+		mockRepo, new(mocks.MockTranslationRepository), new(mocks.MockExampleSentenceRepository), new(mocks.MockInflectionRepository), new(mocks.MockAudioRepository), new(mocks.MockLexemeRepository), new(mocks.MockRelationRepository), new(mocks.MockTagRepository)))
+
+	owner := "ania"
+	mockRepo.On("GetAccessByPolishWordIDs", mock.Anything, []string{"4"}).
+		Return(map[string]*repository.Access{"4": {Owner: &owner, Visibility: model.VisibilityPrivate}}, nil).Once()
+
+	word := &model.PolishWord{ID: "4", Word: "dom"}
+
+	visibility, err := r.PolishWord().Visibility(ctx, word)
+	require.NoError(t, err)
+	assert.Equal(t, model.VisibilityPrivate, visibility)
+
+	wordOwner, err := r.PolishWord().Owner(ctx, word)
+	require.NoError(t, err)
+	assert.Equal(t, &owner, wordOwner)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPastPolishWordDoesNotLoadCurrentFields(t *testing.T) {
+	mockRepo := new(mocks.MockPolishWordRepository)
+	r := &Resolver{PolishWordRepo: mockRepo}
+	query := &queryResolver{r}
+
+	id := "1"
+	version := 2
+	past := &model.PolishWord{ID: "1", Word: "zamek", Version: 2, Past: true, Translations: []*model.Translation{}}
+
+	mockRepo.On("GetPolishWordAsOf", mock.Anything, &id, (*string)(nil), &version, (*time.Time)(nil)).
+		Return(past, nil).Once()
+
+	result, err := query.PolishWord(context.Background(), &id, nil, &version, nil)
+	require.NoError(t, err)
+	assert.Equal(t, past, result)
+
+	level, err := r.PolishWord().CefrLevel(context.Background(), result)
+	require.NoError(t, err)
+	assert.Nil(t, level)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestHasRoleLetsHigherRolesThrough(t *testing.T) {
+	next := func(ctx context.Context) (any, error) { return "resolved", nil }
+
+	admin := auth.WithPrincipal(context.Background(), &auth.Principal{Name: "root", Roles: []auth.Role{auth.RoleAdmin}})
+	result, err := HasRole(admin, nil, next, model.RoleEditor)
+	require.NoError(t, err)
+	assert.Equal(t, "resolved", result)
+
+	reader := auth.WithPrincipal(context.Background(), &auth.Principal{Name: "ania"})
+	_, err = HasRole(reader, nil, next, model.RoleReader)
+	require.NoError(t, err)
+}
+
+func TestHasRoleForbidsOthers(t *testing.T) {
+	next := func(ctx context.Context) (any, error) {
+		t.Fatal("the field must not be resolved")
+		return nil, nil
+	}
+
+	editor := auth.WithPrincipal(context.Background(), &auth.Principal{Name: "ania", Roles: []auth.Role{auth.RoleEditor}})
+	editor = graphql.WithFieldContext(editor, &graphql.FieldContext{Field: graphql.CollectedField{Field: &ast.Field{Name: "auditLog"}}})
+
+	_, err := HasRole(editor, nil, next, model.RoleAdmin)
+
+	var forbidden *apperror.ForbiddenError
+	require.ErrorAs(t, err, &forbidden)
+	assert.Equal(t, "not allowed to use auditLog without the admin role", err.Error())
+
+	_, err = HasRole(context.Background(), nil, next, model.RoleReader)
+	require.ErrorAs(t, err, &forbidden, "anonymous callers have no role")
+}
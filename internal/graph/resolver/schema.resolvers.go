@@ -6,11 +6,104 @@ package resolver
 
 import (
 	"context"
+	"time"
 
+	"github.com/99designs/gqlgen/graphql"
 	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/generated"
 	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/importer"
 )
 
+// URL is the resolver for the url field.
+func (r *audioResolver) URL(ctx context.Context, obj *model.Audio) (string, error) {
+	return r.AudioURLs.URL(obj.Key), nil
+}
+
+// Translation is the resolver for the translation field.
+func (r *exampleSentenceResolver) Translation(ctx context.Context, obj *model.ExampleSentence) (*model.Translation, error) {
+	if obj.Translation != nil {
+		return obj.Translation, nil
+	}
+	return r.loaders(ctx).TranslationByID.Load(ctx, obj.TranslationID)
+}
+
+// Texts is the resolver for the texts field.
+func (r *exampleSentenceResolver) Texts(ctx context.Context, obj *model.ExampleSentence) ([]*model.SentenceText, error) {
+	if !obj.Past {
+		return r.loaders(ctx).TextsByExampleSentenceID.Load(ctx, obj.ID)
+	}
+
+	// sentence_texts keeps no history, so a past sentence only has the texts
+	// it had in sentencePl and sentenceEn.
+	translation, err := r.ExampleSentence().Translation(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	lexeme, err := r.loaders(ctx).LexemeByID.Load(ctx, translation.PolishWordID)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*model.SentenceText{
+		{Language: lexeme.Language, Text: obj.SentencePl},
+		{Language: translation.Language, Text: obj.SentenceEn},
+	}, nil
+}
+
+// Audio is the resolver for the audio field.
+func (r *exampleSentenceResolver) Audio(ctx context.Context, obj *model.ExampleSentence) (*model.Audio, error) {
+	if obj.Audio != nil {
+		return obj.Audio, nil
+	}
+	return r.loaders(ctx).AudioByExampleSentenceID.Load(ctx, obj.ID)
+}
+
+// Visibility is the resolver for the visibility field.
+func (r *exampleSentenceResolver) Visibility(ctx context.Context, obj *model.ExampleSentence) (model.Visibility, error) {
+	if obj.Visibility != "" {
+		return obj.Visibility, nil
+	}
+	access, err := loadAccess(ctx, r.loaders(ctx).AccessByExampleSentenceID, "example sentence", obj.ID)
+	if err != nil {
+		return "", err
+	}
+	return access.Visibility, nil
+}
+
+// Owner is the resolver for the owner field.
+func (r *exampleSentenceResolver) Owner(ctx context.Context, obj *model.ExampleSentence) (*string, error) {
+	if obj.Owner != nil {
+		return obj.Owner, nil
+	}
+	access, err := loadAccess(ctx, r.loaders(ctx).AccessByExampleSentenceID, "example sentence", obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	return access.Owner, nil
+}
+
+// PolishWord is the resolver for the polishWord field.
+func (r *inflectionResolver) PolishWord(ctx context.Context, obj *model.Inflection) (*model.PolishWord, error) {
+	if obj.PolishWord != nil {
+		return obj.PolishWord, nil
+	}
+	return r.loaders(ctx).PolishWordByID.Load(ctx, obj.PolishWordID)
+}
+
+// Translations is the resolver for the translations field.
+func (r *lexemeResolver) Translations(ctx context.Context, obj *model.Lexeme, language *string) ([]*model.Translation, error) {
+	translations := obj.Translations
+	if translations == nil {
+		var err error
+		translations, err = r.loaders(ctx).TranslationsByPolishWordID.Load(ctx, obj.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return filterByLanguage(translations, language), nil
+}
+
 // AddPolishWord is the resolver for the addPolishWord field.
 func (r *mutationResolver) AddPolishWord(ctx context.Context, polishWord model.AddPolishWordInput) (*model.PolishWord, error) {
 	return r.PolishWordRepo.AddPolishWord(ctx, polishWord)
@@ -26,6 +119,11 @@ func (r *mutationResolver) UpdatePolishWord(ctx context.Context, id *string, wor
 	return r.PolishWordRepo.UpdatePolishWord(ctx, id, word, edits)
 }
 
+// RevertPolishWord is the resolver for the revertPolishWord field.
+func (r *mutationResolver) RevertPolishWord(ctx context.Context, id string, toVersion int) (*model.PolishWord, error) {
+	return r.PolishWordRepo.RevertPolishWord(ctx, id, toVersion)
+}
+
 // AddTranslation is the resolver for the addTranslation field.
 func (r *mutationResolver) AddTranslation(ctx context.Context, polishWordID *string, polishWord *string, translation *model.AddTranslationInput) (*model.Translation, error) {
 	return r.TranslationRepo.AddTranslation(ctx, polishWordID, polishWord, translation)
@@ -56,14 +154,203 @@ func (r *mutationResolver) UpdateExampleSentence(ctx context.Context, id string,
 	return r.ExampleSentenceRepo.UpdateExampleSentence(ctx, id, edits)
 }
 
+// AddInflection is the resolver for the addInflection field.
+func (r *mutationResolver) AddInflection(ctx context.Context, polishWordID *string, polishWord *string, inflection model.AddInflectionInput) (*model.Inflection, error) {
+	return r.InflectionRepo.AddInflection(ctx, polishWordID, polishWord, inflection)
+}
+
+// DeleteInflection is the resolver for the deleteInflection field.
+func (r *mutationResolver) DeleteInflection(ctx context.Context, id string) (*model.Inflection, error) {
+	return r.InflectionRepo.DeleteInflection(ctx, id)
+}
+
+// UpdateInflection is the resolver for the updateInflection field.
+func (r *mutationResolver) UpdateInflection(ctx context.Context, id string, edits model.EditInflectionInput) (*model.Inflection, error) {
+	return r.InflectionRepo.UpdateInflection(ctx, id, edits)
+}
+
+// ImportDictionary is the resolver for the importDictionary field.
+func (r *mutationResolver) ImportDictionary(ctx context.Context, file graphql.Upload, format model.ImportFormat) (*model.ImportReport, error) {
+	return importer.Import(ctx, r.ImportRepo, file.File, format, importer.DefaultBatchSize)
+}
+
+// AttachPolishWordAudio is the resolver for the attachPolishWordAudio field.
+func (r *mutationResolver) AttachPolishWordAudio(ctx context.Context, polishWordID *string, polishWord *string, file graphql.Upload) (*model.PolishWord, error) {
+	return r.AudioRecorder.AttachToPolishWord(ctx, polishWordID, polishWord, file.File, file.Size)
+}
+
+// AttachExampleSentenceAudio is the resolver for the attachExampleSentenceAudio field.
+func (r *mutationResolver) AttachExampleSentenceAudio(ctx context.Context, exampleSentenceID string, file graphql.Upload) (*model.ExampleSentence, error) {
+	return r.AudioRecorder.AttachToExampleSentence(ctx, exampleSentenceID, file.File, file.Size)
+}
+
+// AddLexeme is the resolver for the addLexeme field.
+func (r *mutationResolver) AddLexeme(ctx context.Context, lexeme model.AddLexemeInput) (*model.Lexeme, error) {
+	return r.LexemeRepo.AddLexeme(ctx, lexeme)
+}
+
+// DeleteLexeme is the resolver for the deleteLexeme field.
+func (r *mutationResolver) DeleteLexeme(ctx context.Context, id string) (*model.Lexeme, error) {
+	return r.LexemeRepo.DeleteLexeme(ctx, id)
+}
+
+// AddLexemeTranslation is the resolver for the addLexemeTranslation field.
+func (r *mutationResolver) AddLexemeTranslation(ctx context.Context, lexemeID string, translation model.AddLexemeTranslationInput) (*model.Translation, error) {
+	return r.LexemeRepo.AddLexemeTranslation(ctx, lexemeID, translation)
+}
+
+// AddWordRelation is the resolver for the addWordRelation field.
+func (r *mutationResolver) AddWordRelation(ctx context.Context, relation model.AddWordRelationInput) (*model.WordRelation, error) {
+	return r.RelationRepo.AddWordRelation(ctx, relation)
+}
+
+// RemoveWordRelation is the resolver for the removeWordRelation field.
+func (r *mutationResolver) RemoveWordRelation(ctx context.Context, id string) (*model.WordRelation, error) {
+	return r.RelationRepo.RemoveWordRelation(ctx, id)
+}
+
+// AddTags is the resolver for the addTags field.
+func (r *mutationResolver) AddTags(ctx context.Context, target model.TagTargetInput, tags []string) ([]*model.Tag, error) {
+	return r.TagRepo.AddTags(ctx, target, tags)
+}
+
+// RemoveTags is the resolver for the removeTags field.
+func (r *mutationResolver) RemoveTags(ctx context.Context, target model.TagTargetInput, tags []string) ([]*model.Tag, error) {
+	return r.TagRepo.RemoveTags(ctx, target, tags)
+}
+
+// Restore is the resolver for the restore field.
+func (r *mutationResolver) Restore(ctx context.Context, id string) (model.DictionaryEntry, error) {
+	return r.TrashRepo.Restore(ctx, id)
+}
+
+// Translations is the resolver for the translations field.
+func (r *polishWordResolver) Translations(ctx context.Context, obj *model.PolishWord, partOfSpeech *model.PartOfSpeech, language *string) ([]*model.Translation, error) {
+	translations := obj.Translations
+	if translations == nil {
+		var err error
+		translations, err = r.loaders(ctx).TranslationsByPolishWordID.Load(ctx, obj.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return filterByPartOfSpeech(filterByLanguage(translations, language), partOfSpeech), nil
+}
+
+// Inflections is the resolver for the inflections field.
+func (r *polishWordResolver) Inflections(ctx context.Context, obj *model.PolishWord) ([]*model.Inflection, error) {
+	if obj.Inflections != nil {
+		return obj.Inflections, nil
+	}
+	return r.loaders(ctx).InflectionsByPolishWordID.Load(ctx, obj.ID)
+}
+
+// Pronunciation is the resolver for the pronunciation field.
+func (r *polishWordResolver) Pronunciation(ctx context.Context, obj *model.PolishWord) (*model.Pronunciation, error) {
+	if obj.Pronunciation != nil || obj.Past {
+		return obj.Pronunciation, nil
+	}
+	return r.loaders(ctx).PronunciationByPolishWordID.Load(ctx, obj.ID)
+}
+
+// Audio is the resolver for the audio field.
+func (r *polishWordResolver) Audio(ctx context.Context, obj *model.PolishWord) (*model.Audio, error) {
+	if obj.Audio != nil {
+		return obj.Audio, nil
+	}
+	return r.loaders(ctx).AudioByPolishWordID.Load(ctx, obj.ID)
+}
+
+// Related is the resolver for the related field.
+func (r *polishWordResolver) Related(ctx context.Context, obj *model.PolishWord, typeArg *model.RelationType) ([]*model.WordRelation, error) {
+	relations, err := r.loaders(ctx).RelationsByPolishWordID.Load(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	return filterByRelationType(relations, typeArg), nil
+}
+
+// Tags is the resolver for the tags field.
+func (r *polishWordResolver) Tags(ctx context.Context, obj *model.PolishWord) ([]*model.Tag, error) {
+	return r.loaders(ctx).TagsByPolishWordID.Load(ctx, obj.ID)
+}
+
+// CefrLevel is the resolver for the cefrLevel field.
+func (r *polishWordResolver) CefrLevel(ctx context.Context, obj *model.PolishWord) (*model.CefrLevel, error) {
+	if obj.CefrLevel != nil || obj.Past {
+		return obj.CefrLevel, nil
+	}
+	level, err := r.loaders(ctx).LevelByPolishWordID.Load(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	return level.CefrLevel, nil
+}
+
+// FrequencyRank is the resolver for the frequencyRank field.
+func (r *polishWordResolver) FrequencyRank(ctx context.Context, obj *model.PolishWord) (*int, error) {
+	if obj.FrequencyRank != nil || obj.Past {
+		return obj.FrequencyRank, nil
+	}
+	level, err := r.loaders(ctx).LevelByPolishWordID.Load(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	return level.FrequencyRank, nil
+}
+
+// History is the resolver for the history field.
+func (r *polishWordResolver) History(ctx context.Context, obj *model.PolishWord) ([]*model.Revision, error) {
+	return r.loaders(ctx).HistoryByPolishWordID.Load(ctx, obj.ID)
+}
+
+// Visibility is the resolver for the visibility field.
+func (r *polishWordResolver) Visibility(ctx context.Context, obj *model.PolishWord) (model.Visibility, error) {
+	if obj.Visibility != "" || obj.Past {
+		return obj.Visibility, nil
+	}
+	access, err := loadAccess(ctx, r.loaders(ctx).AccessByPolishWordID, "polish word", obj.ID)
+	if err != nil {
+		return "", err
+	}
+	return access.Visibility, nil
+}
+
+// Owner is the resolver for the owner field.
+func (r *polishWordResolver) Owner(ctx context.Context, obj *model.PolishWord) (*string, error) {
+	if obj.Owner != nil || obj.Past {
+		return obj.Owner, nil
+	}
+	access, err := loadAccess(ctx, r.loaders(ctx).AccessByPolishWordID, "polish word", obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	return access.Owner, nil
+}
+
 // PolishWord is the resolver for the polishWord field.
-func (r *queryResolver) PolishWord(ctx context.Context, id *string, word *string) (*model.PolishWord, error) {
+func (r *queryResolver) PolishWord(ctx context.Context, id *string, word *string, asOfVersion *int, asOf *time.Time) (*model.PolishWord, error) {
+	if asOfVersion != nil || asOf != nil {
+		return r.PolishWordRepo.GetPolishWordAsOf(ctx, id, word, asOfVersion, asOf)
+	}
 	return r.PolishWordRepo.GetSinglePolishWord(ctx, id, word)
 }
 
 // PolishWords is the resolver for the polishWords field.
-func (r *queryResolver) PolishWords(ctx context.Context) ([]*model.PolishWord, error) {
-	return r.PolishWordRepo.GetAllPolishWords(ctx)
+func (r *queryResolver) PolishWords(ctx context.Context, tags []string, tagMatch *model.TagMatch, filter *model.PolishWordFilter, orderBy *model.PolishWordOrder) ([]*model.PolishWord, error) {
+	if filter == nil {
+		filter = &model.PolishWordFilter{}
+	}
+	if tags != nil {
+		filter.Tags = tags
+		filter.TagMatch = tagMatch
+	}
+	return r.PolishWordRepo.GetAllPolishWords(ctx, filter, orderBy)
+}
+
+// PolishWordsConnection is the resolver for the polishWordsConnection field.
+func (r *queryResolver) PolishWordsConnection(ctx context.Context, first *int, after *string, last *int, before *string, filter *model.PolishWordFilter) (*model.PolishWordConnection, error) {
+	return r.PolishWordRepo.GetPolishWordsPage(ctx, first, after, last, before, filter)
 }
 
 // Translation is the resolver for the translation field.
@@ -71,6 +358,11 @@ func (r *queryResolver) Translation(ctx context.Context, id string) (*model.Tran
 	return r.TranslationRepo.GetSingleTranslationByID(ctx, id)
 }
 
+// EnglishWord is the resolver for the englishWord field.
+func (r *queryResolver) EnglishWord(ctx context.Context, word string, partOfSpeech *model.PartOfSpeech) ([]*model.Translation, error) {
+	return r.TranslationRepo.GetTranslationsByEnglishWord(ctx, word, partOfSpeech)
+}
+
 // ExampleSentence is the resolver for the exampleSentence field.
 func (r *queryResolver) ExampleSentence(ctx context.Context, id string) (*model.ExampleSentence, error) {
 	return r.ExampleSentenceRepo.GetSingleExampleSentence(ctx, id)
@@ -81,11 +373,168 @@ func (r *queryResolver) ExampleSentences(ctx context.Context, translationID stri
 	return r.ExampleSentenceRepo.GetExampleSentencesByTranslationId(ctx, translationID)
 }
 
+// Search is the resolver for the search field.
+func (r *queryResolver) Search(ctx context.Context, query string, scope []model.SearchScope, limit *int) ([]*model.SearchHit, error) {
+	return r.SearchRepo.Search(ctx, query, scope, limit)
+}
+
+// Lexeme is the resolver for the lexeme field.
+func (r *queryResolver) Lexeme(ctx context.Context, id *string, language *string, lemma *string) (*model.Lexeme, error) {
+	return r.LexemeRepo.GetLexeme(ctx, id, language, lemma)
+}
+
+// Lexemes is the resolver for the lexemes field.
+func (r *queryResolver) Lexemes(ctx context.Context, language *string) ([]*model.Lexeme, error) {
+	return r.LexemeRepo.GetLexemes(ctx, language)
+}
+
+// Translations is the resolver for the translations field.
+func (r *queryResolver) Translations(ctx context.Context, text string, language string) ([]*model.Translation, error) {
+	return r.LexemeRepo.GetTranslationsByText(ctx, text, language)
+}
+
+// Tags is the resolver for the tags field.
+func (r *queryResolver) Tags(ctx context.Context) ([]*model.TagUsage, error) {
+	return r.TagRepo.GetTagUsage(ctx)
+}
+
+// MyWords is the resolver for the myWords field.
+func (r *queryResolver) MyWords(ctx context.Context) ([]*model.PolishWord, error) {
+	return r.PolishWordRepo.GetMyWords(ctx)
+}
+
+// Trash is the resolver for the trash field.
+func (r *queryResolver) Trash(ctx context.Context) ([]*model.TrashEntry, error) {
+	return r.TrashRepo.GetTrash(ctx)
+}
+
+// AuditLog is the resolver for the auditLog field.
+func (r *queryResolver) AuditLog(ctx context.Context, filter *model.AuditLogFilter, first *int, after *string) (*model.AuditLogConnection, error) {
+	return r.AuditRepo.GetAuditLog(ctx, filter, first, after)
+}
+
+// Text is the resolver for the text field.
+func (r *translationResolver) Text(ctx context.Context, obj *model.Translation) (string, error) {
+	return obj.EnglishWord, nil
+}
+
+// PolishWord is the resolver for the polishWord field.
+func (r *translationResolver) PolishWord(ctx context.Context, obj *model.Translation) (*model.PolishWord, error) {
+	if obj.PolishWord != nil {
+		return obj.PolishWord, nil
+	}
+	return r.loaders(ctx).PolishWordByID.Load(ctx, obj.PolishWordID)
+}
+
+// Lexeme is the resolver for the lexeme field.
+func (r *translationResolver) Lexeme(ctx context.Context, obj *model.Translation) (*model.Lexeme, error) {
+	return r.loaders(ctx).LexemeByID.Load(ctx, obj.PolishWordID)
+}
+
+// ExampleSentences is the resolver for the exampleSentences field.
+func (r *translationResolver) ExampleSentences(ctx context.Context, obj *model.Translation) ([]*model.ExampleSentence, error) {
+	if obj.ExampleSentences != nil {
+		return obj.ExampleSentences, nil
+	}
+	return r.loaders(ctx).ExampleSentencesByTranslationID.Load(ctx, obj.ID)
+}
+
+// Tags is the resolver for the tags field.
+func (r *translationResolver) Tags(ctx context.Context, obj *model.Translation) ([]*model.Tag, error) {
+	return r.loaders(ctx).TagsByTranslationID.Load(ctx, obj.ID)
+}
+
+// CefrLevel is the resolver for the cefrLevel field.
+func (r *translationResolver) CefrLevel(ctx context.Context, obj *model.Translation) (*model.CefrLevel, error) {
+	if obj.CefrLevel != nil || obj.Past {
+		return obj.CefrLevel, nil
+	}
+	level, err := r.loaders(ctx).LevelByTranslationID.Load(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	return level.CefrLevel, nil
+}
+
+// FrequencyRank is the resolver for the frequencyRank field.
+func (r *translationResolver) FrequencyRank(ctx context.Context, obj *model.Translation) (*int, error) {
+	if obj.FrequencyRank != nil || obj.Past {
+		return obj.FrequencyRank, nil
+	}
+	level, err := r.loaders(ctx).LevelByTranslationID.Load(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	return level.FrequencyRank, nil
+}
+
+// Visibility is the resolver for the visibility field.
+func (r *translationResolver) Visibility(ctx context.Context, obj *model.Translation) (model.Visibility, error) {
+	if obj.Visibility != "" || obj.Past {
+		return obj.Visibility, nil
+	}
+	access, err := loadAccess(ctx, r.loaders(ctx).AccessByTranslationID, "translation", obj.ID)
+	if err != nil {
+		return "", err
+	}
+	return access.Visibility, nil
+}
+
+// Owner is the resolver for the owner field.
+func (r *translationResolver) Owner(ctx context.Context, obj *model.Translation) (*string, error) {
+	if obj.Owner != nil || obj.Past {
+		return obj.Owner, nil
+	}
+	access, err := loadAccess(ctx, r.loaders(ctx).AccessByTranslationID, "translation", obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	return access.Owner, nil
+}
+
+// Word is the resolver for the word field.
+func (r *wordRelationResolver) Word(ctx context.Context, obj *model.WordRelation) (*model.PolishWord, error) {
+	if obj.Word != nil {
+		return obj.Word, nil
+	}
+	return r.loaders(ctx).PolishWordByID.Load(ctx, obj.WordID)
+}
+
+// Audio returns generated.AudioResolver implementation.
+func (r *Resolver) Audio() generated.AudioResolver { return &audioResolver{r} }
+
+// ExampleSentence returns generated.ExampleSentenceResolver implementation.
+func (r *Resolver) ExampleSentence() generated.ExampleSentenceResolver {
+	return &exampleSentenceResolver{r}
+}
+
+// Inflection returns generated.InflectionResolver implementation.
+func (r *Resolver) Inflection() generated.InflectionResolver { return &inflectionResolver{r} }
+
+// Lexeme returns generated.LexemeResolver implementation.
+func (r *Resolver) Lexeme() generated.LexemeResolver { return &lexemeResolver{r} }
+
 // Mutation returns generated.MutationResolver implementation.
 func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResolver{r} }
 
+// PolishWord returns generated.PolishWordResolver implementation.
+func (r *Resolver) PolishWord() generated.PolishWordResolver { return &polishWordResolver{r} }
+
 // Query returns generated.QueryResolver implementation.
 func (r *Resolver) Query() generated.QueryResolver { return &queryResolver{r} }
 
+// Translation returns generated.TranslationResolver implementation.
+func (r *Resolver) Translation() generated.TranslationResolver { return &translationResolver{r} }
+
+// WordRelation returns generated.WordRelationResolver implementation.
+func (r *Resolver) WordRelation() generated.WordRelationResolver { return &wordRelationResolver{r} }
+
+type audioResolver struct{ *Resolver }
+type exampleSentenceResolver struct{ *Resolver }
+type inflectionResolver struct{ *Resolver }
+type lexemeResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
+type polishWordResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type translationResolver struct{ *Resolver }
+type wordRelationResolver struct{ *Resolver }
@@ -0,0 +1,124 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/apperror"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/repository"
+)
+
+const DefaultBatchSize = 500
+
+// lineError is a record that could not be parsed. It fails its own line
+// without stopping the import.
+type lineError struct {
+	line int
+	err  error
+}
+
+func (e *lineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.line, e.err)
+}
+
+type recordReader interface {
+	// Next returns io.EOF once the input is exhausted.
+	Next() (repository.ImportRecord, error)
+}
+
+func newRecordReader(r io.Reader, format model.ImportFormat) (recordReader, error) {
+	switch format {
+	case model.ImportFormatJSONL:
+		return newJSONLReader(r), nil
+	case model.ImportFormatCSV:
+		return newCSVReader(r)
+	default:
+		return nil, &apperror.ValidationError{Field: "format", Message: fmt.Sprintf("unsupported import format %q", format)}
+	}
+}
+
+// Import streams records from r into repo, batchSize records per transaction,
+// and reports the outcome of every line.
+func Import(ctx context.Context, repo repository.ImportRepositoryInterface, r io.Reader, format model.ImportFormat, batchSize int) (*model.ImportReport, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	reader, err := newRecordReader(r, format)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.ImportReport{Rows: []*model.ImportRowResult{}}
+	batch := make([]repository.ImportRecord, 0, batchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		results, err := repo.ImportBatch(ctx, batch)
+		if err != nil {
+			return err
+		}
+
+		report.Rows = append(report.Rows, results...)
+		batch = batch[:0]
+		return nil
+	}
+
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var parseErr *lineError
+		if errors.As(err, &parseErr) {
+			message := parseErr.err.Error()
+			report.Rows = append(report.Rows, &model.ImportRowResult{
+				Line:   parseErr.line,
+				Status: model.ImportStatusFailed,
+				Error:  &message,
+			})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		batch = append(batch, record)
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(report.Rows, func(a, b *model.ImportRowResult) int {
+		return a.Line - b.Line
+	})
+
+	for _, row := range report.Rows {
+		switch row.Status {
+		case model.ImportStatusCreated:
+			report.Created++
+		case model.ImportStatusMerged:
+			report.Merged++
+		case model.ImportStatusSkipped:
+			report.Skipped++
+		case model.ImportStatusFailed:
+			report.Failed++
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,113 @@
+package importer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingRepo remembers every batch and marks all of its records as created.
+type recordingRepo struct {
+	batches [][]repository.ImportRecord
+}
+
+func (rr *recordingRepo) ImportBatch(ctx context.Context, records []repository.ImportRecord) ([]*model.ImportRowResult, error) {
+	rr.batches = append(rr.batches, append([]repository.ImportRecord(nil), records...))
+
+	results := make([]*model.ImportRowResult, len(records))
+	for i, record := range records {
+		word := record.Input.Word
+		results[i] = &model.ImportRowResult{Line: record.Line, Word: &word, Status: model.ImportStatusCreated}
+	}
+	return results, nil
+}
+
+func TestImportJSONLBatchesAndReportsParseErrors(t *testing.T) {
+
+	repo := &recordingRepo{}
+	input := strings.Join([]string{
+		`{"word":"kot","translations":[{"englishWord":"cat","exampleSentences":[{"sentencePl":"Kot śpi.","sentenceEn":"The cat sleeps."}]}]}`,
+		``,
+		`{"word":"pies",`,
+		`{"word":"dom","translations":[]}`,
+		`{"word":"las"}`,
+	}, "\n")
+
+	report, err := Import(context.Background(), repo, strings.NewReader(input), model.ImportFormatJSONL, 2)
+	require.NoError(t, err)
+
+	require.Len(t, repo.batches, 2)
+	assert.Equal(t, 1, repo.batches[0][0].Line)
+	assert.Equal(t, 4, repo.batches[0][1].Line)
+	assert.Equal(t, 5, repo.batches[1][0].Line)
+	assert.Equal(t, "cat", repo.batches[0][0].Input.Translations[0].EnglishWord)
+
+	assert.Equal(t, 3, report.Created)
+	assert.Equal(t, 1, report.Failed)
+	require.Len(t, report.Rows, 4)
+	assert.Equal(t, 3, report.Rows[1].Line)
+	assert.Equal(t, model.ImportStatusFailed, report.Rows[1].Status)
+	assert.Contains(t, *report.Rows[1].Error, "invalid JSON")
+}
+
+func TestImportCSV(t *testing.T) {
+
+	repo := &recordingRepo{}
+	input := "word,english_word,sentence_pl,sentence_en\n" +
+		"zamek,castle,Zamek stoi na wzgórzu.,The castle stands on a hill.\n" +
+		"zamek,lock,,\n" +
+		"dom,,,\n" +
+		"kot,,Kot śpi.,The cat sleeps.\n"
+
+	report, err := Import(context.Background(), repo, strings.NewReader(input), model.ImportFormatCSV, 0)
+	require.NoError(t, err)
+
+	require.Len(t, repo.batches, 1)
+	records := repo.batches[0]
+	require.Len(t, records, 3)
+
+	assert.Equal(t, 2, records[0].Line)
+	assert.Equal(t, "zamek", records[0].Input.Word)
+	assert.Equal(t, "castle", records[0].Input.Translations[0].EnglishWord)
+	assert.Equal(t, "The castle stands on a hill.", records[0].Input.Translations[0].ExampleSentences[0].SentenceEn)
+
+	assert.Equal(t, "lock", records[1].Input.Translations[0].EnglishWord)
+	assert.Empty(t, records[1].Input.Translations[0].ExampleSentences)
+
+	assert.Equal(t, "dom", records[2].Input.Word)
+	assert.Empty(t, records[2].Input.Translations)
+
+	assert.Equal(t, 3, report.Created)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, 5, report.Rows[3].Line)
+}
+
+func TestImportCSVRejectsRanksThatAreNotNumbers(t *testing.T) {
+
+	repo := &recordingRepo{}
+	input := "word,cefr_level,frequency_rank\n" +
+		"kot,a1,12\n" +
+		"pies,A1,often\n"
+
+	report, err := Import(context.Background(), repo, strings.NewReader(input), model.ImportFormatCSV, 0)
+	require.NoError(t, err)
+
+	require.Len(t, repo.batches, 1)
+	require.Len(t, repo.batches[0], 1)
+	assert.Equal(t, model.CefrLevelA1, *repo.batches[0][0].Input.CefrLevel)
+	assert.Equal(t, 12, *repo.batches[0][0].Input.FrequencyRank)
+
+	assert.Equal(t, 1, report.Failed)
+	assert.Contains(t, *report.Rows[1].Error, `frequency_rank: "often" is not a number`)
+}
+
+func TestImportCSVRequiresWordColumn(t *testing.T) {
+
+	_, err := Import(context.Background(), &recordingRepo{}, strings.NewReader("polish,english\nkot,cat\n"), model.ImportFormatCSV, 0)
+	require.Error(t, err)
+}
@@ -0,0 +1,239 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/apperror"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/repository"
+)
+
+// CSVColumns is the header of the CSV format. Every row holds one example
+// sentence, or a translation without sentences, or a bare word; rows of the
+// same word are merged. Only word is required; the grammar columns and
+// language may be left out or empty, and an empty language means English.
+// sentence_text_language and sentence_text give the sentence in one further
+// language; a sentence with several is repeated on one row per language.
+// cefr_level and frequency_rank belong to the word, the translation_ ones to
+// the translation. visibility, translation_visibility and sentence_visibility
+// may be empty to take the visibility of the entry they belong to.
+var CSVColumns = []string{
+	"word", "english_word",
+	"part_of_speech", "gender", "aspect", "aspect_partner", "usage_note",
+	"sentence_pl", "sentence_en",
+	"language",
+	"sentence_text_language", "sentence_text",
+	"cefr_level", "frequency_rank", "translation_cefr_level", "translation_frequency_rank",
+	"visibility", "translation_visibility", "sentence_visibility",
+}
+
+const maxJSONLLineSize = 1 << 20
+
+// jsonlReader reads one AddPolishWordInput object per line. Blank lines are
+// ignored.
+type jsonlReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newJSONLReader(r io.Reader) *jsonlReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxJSONLLineSize)
+	return &jsonlReader{scanner: scanner}
+}
+
+func (jr *jsonlReader) Next() (repository.ImportRecord, error) {
+	for jr.scanner.Scan() {
+		jr.line++
+
+		content := bytes.TrimSpace(jr.scanner.Bytes())
+		if len(content) == 0 {
+			continue
+		}
+
+		var input model.AddPolishWordInput
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&input); err != nil {
+			return repository.ImportRecord{}, &lineError{line: jr.line, err: fmt.Errorf("invalid JSON: %w", err)}
+		}
+
+		return repository.ImportRecord{Line: jr.line, Input: input}, nil
+	}
+
+	if err := jr.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return repository.ImportRecord{}, &apperror.ValidationError{
+				Message: fmt.Sprintf("line %d is longer than %d bytes", jr.line+1, maxJSONLLineSize),
+			}
+		}
+		return repository.ImportRecord{}, err
+	}
+
+	return repository.ImportRecord{}, io.EOF
+}
+
+type csvReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return &csvReader{reader: reader}, nil
+	}
+	if err != nil {
+		return nil, &apperror.ValidationError{Message: fmt.Sprintf("invalid CSV header: %v", err)}
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+
+	if _, ok := columns["word"]; !ok {
+		return nil, &apperror.ValidationError{Message: fmt.Sprintf("CSV header must contain the columns %s", strings.Join(CSVColumns, ","))}
+	}
+
+	return &csvReader{reader: reader, columns: columns}, nil
+}
+
+func (cr *csvReader) Next() (repository.ImportRecord, error) {
+	if cr.columns == nil {
+		return repository.ImportRecord{}, io.EOF
+	}
+
+	row, err := cr.reader.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return repository.ImportRecord{}, &lineError{line: parseErr.StartLine, err: parseErr.Err}
+		}
+		return repository.ImportRecord{}, err
+	}
+
+	line, _ := cr.reader.FieldPos(0)
+
+	frequencyRank, err := rankField(cr.field(row, "frequency_rank"))
+	if err != nil {
+		return repository.ImportRecord{}, &lineError{line: line, err: fmt.Errorf("frequency_rank: %w", err)}
+	}
+	translationFrequencyRank, err := rankField(cr.field(row, "translation_frequency_rank"))
+	if err != nil {
+		return repository.ImportRecord{}, &lineError{line: line, err: fmt.Errorf("translation_frequency_rank: %w", err)}
+	}
+
+	input := model.AddPolishWordInput{
+		Word:          cr.field(row, "word"),
+		CefrLevel:     enumField[model.CefrLevel](cr.field(row, "cefr_level")),
+		FrequencyRank: frequencyRank,
+		Visibility:    enumField[model.Visibility](cr.field(row, "visibility")),
+		Translations:  []*model.AddTranslationInput{},
+	}
+
+	englishWord := cr.field(row, "english_word")
+	sentencePl := cr.field(row, "sentence_pl")
+	sentenceEn := cr.field(row, "sentence_en")
+	textLanguage := strings.TrimSpace(cr.field(row, "sentence_text_language"))
+	text := cr.field(row, "sentence_text")
+	sentenceVisibility := enumField[model.Visibility](cr.field(row, "sentence_visibility"))
+
+	if textLanguage != "" || text != "" || sentenceVisibility != nil {
+		if sentencePl == "" && sentenceEn == "" {
+			return repository.ImportRecord{}, &lineError{line: line, err: errors.New("sentence texts and sentence_visibility need an example sentence")}
+		}
+	}
+
+	if englishWord == "" {
+		if sentencePl != "" || sentenceEn != "" {
+			return repository.ImportRecord{}, &lineError{line: line, err: errors.New("example sentences need an english_word")}
+		}
+		if cr.field(row, "translation_cefr_level") != "" || translationFrequencyRank != nil {
+			return repository.ImportRecord{}, &lineError{line: line, err: errors.New("translation levels need an english_word")}
+		}
+		if strings.TrimSpace(cr.field(row, "translation_visibility")) != "" {
+			return repository.ImportRecord{}, &lineError{line: line, err: errors.New("translation_visibility needs an english_word")}
+		}
+		return repository.ImportRecord{Line: line, Input: input}, nil
+	}
+
+	translation := &model.AddTranslationInput{
+		EnglishWord:      englishWord,
+		Language:         optionalField(strings.TrimSpace(cr.field(row, "language"))),
+		PartOfSpeech:     enumField[model.PartOfSpeech](cr.field(row, "part_of_speech")),
+		Gender:           enumField[model.Gender](cr.field(row, "gender")),
+		Aspect:           enumField[model.Aspect](cr.field(row, "aspect")),
+		AspectPartner:    optionalField(cr.field(row, "aspect_partner")),
+		UsageNote:        optionalField(cr.field(row, "usage_note")),
+		CefrLevel:        enumField[model.CefrLevel](cr.field(row, "translation_cefr_level")),
+		FrequencyRank:    translationFrequencyRank,
+		Visibility:       enumField[model.Visibility](cr.field(row, "translation_visibility")),
+		ExampleSentences: []*model.AddExampleSentenceInput{},
+	}
+	if sentencePl != "" || sentenceEn != "" {
+		es := &model.AddExampleSentenceInput{
+			SentencePl: sentencePl,
+			SentenceEn: sentenceEn,
+			Visibility: sentenceVisibility,
+		}
+		if textLanguage != "" || text != "" {
+			es.Texts = []*model.SentenceTextInput{{Language: textLanguage, Text: text}}
+		}
+		translation.ExampleSentences = append(translation.ExampleSentences, es)
+	}
+	input.Translations = append(input.Translations, translation)
+
+	return repository.ImportRecord{Line: line, Input: input}, nil
+}
+
+// enumField accepts enum values in any case; unknown values are rejected by
+// the repository validation.
+func enumField[E ~string](value string) *E {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	e := E(strings.ToUpper(value))
+	return &e
+}
+
+// rankField parses a frequency rank; whether it is positive is checked by the
+// repository validation.
+func rankField(value string) (*int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	rank, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a number", value)
+	}
+	return &rank, nil
+}
+
+func optionalField(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func (cr *csvReader) field(row []string, column string) string {
+	i, ok := cr.columns[column]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return row[i]
+}
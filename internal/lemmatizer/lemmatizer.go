@@ -0,0 +1,107 @@
+package lemmatizer
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Lemmatizer maps an inflected Polish form to its dictionary forms.
+type Lemmatizer interface {
+	// Lemmas returns the lemmas of form, most likely first, or nil when the
+	// form is unknown.
+	Lemmas(form string) []string
+}
+
+//go:embed data/polimorf-sample.tab
+var bundled []byte
+
+// Dictionary is a Lemmatizer backed by a morphological dictionary held in
+// memory. Forms are matched case-insensitively.
+type Dictionary struct {
+	lemmas map[string][]string
+}
+
+// Default loads the small dictionary bundled with the binary.
+func Default() (*Dictionary, error) {
+	return Load(bytes.NewReader(bundled))
+}
+
+// LoadFile loads a PoliMorf or SGJP dump from path.
+func LoadFile(path string) (*Dictionary, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Load(f)
+}
+
+// Load reads tab-separated lines of form, lemma and any further columns, such
+// as the morphosyntactic tag, which are ignored. Blank lines and lines
+// starting with # are skipped. Lemmas keep the order of their first
+// appearance.
+func Load(r io.Reader) (*Dictionary, error) {
+	d := &Dictionary{lemmas: map[string][]string{}}
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.SplitN(text, "\t", 3)
+		if len(fields) < 2 || fields[0] == "" || fields[1] == "" {
+			return nil, fmt.Errorf("line %d: expected a form and a lemma separated by a tab", line)
+		}
+
+		form := strings.ToLower(fields[0])
+		if !slices.Contains(d.lemmas[form], fields[1]) {
+			d.lemmas[form] = append(d.lemmas[form], fields[1])
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (d *Dictionary) Lemmas(form string) []string {
+	return d.lemmas[strings.ToLower(form)]
+}
+
+// Len returns the number of distinct forms.
+func (d *Dictionary) Len() int {
+	return len(d.lemmas)
+}
+
+var wordPattern = regexp.MustCompile(`\p{L}+`)
+
+// Normalize replaces every word of text that has a lemma with its first lemma
+// and leaves everything else, such as quotes and operators, untouched. A nil
+// Lemmatizer returns text unchanged.
+func Normalize(l Lemmatizer, text string) string {
+	if l == nil {
+		return text
+	}
+
+	return wordPattern.ReplaceAllStringFunc(text, func(word string) string {
+		if lemmas := l.Lemmas(word); len(lemmas) > 0 {
+			return lemmas[0]
+		}
+		return word
+	})
+}
@@ -0,0 +1,45 @@
+package lemmatizer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadKeepsLemmaOrderAndSkipsDuplicates(t *testing.T) {
+
+	d, err := Load(strings.NewReader("# comment\n\nmamy\tmieć\tfin:pl:pri:imperf\nmamy\tmama\tsubst:sg:gen:f\nmamy\tmama\tsubst:pl:nom:f\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"mieć", "mama"}, d.Lemmas("mamy"))
+	assert.Equal(t, 1, d.Len())
+}
+
+func TestLoadRejectsLinesWithoutLemma(t *testing.T) {
+
+	_, err := Load(strings.NewReader("psa\tpies\npsu\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 2")
+}
+
+func TestDefaultDictionary(t *testing.T) {
+
+	d, err := Default()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"pies"}, d.Lemmas("psów"))
+	assert.Equal(t, []string{"zamek"}, d.Lemmas("Zamku"))
+	assert.Equal(t, []string{"człowiek"}, d.Lemmas("ludźmi"))
+	assert.Nil(t, d.Lemmas("xyz"))
+}
+
+func TestNormalize(t *testing.T) {
+
+	d, err := Load(strings.NewReader("psów\tpies\nstarych\tstary\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, `"stary pies" or kot`, Normalize(d, `"starych psów" or kot`))
+	assert.Equal(t, "psów", Normalize(nil, "psów"))
+}
@@ -0,0 +1,129 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedMigrationsLoad(t *testing.T) {
+
+	migrator, err := New(nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, migrator.Migrations)
+
+	for i, migration := range migrator.Migrations {
+		assert.Equal(t, i+1, migration.Version)
+		assert.NotEmpty(t, migration.Up)
+		assert.NotEmpty(t, migration.Down)
+	}
+}
+
+func TestLoadRejectsMissingDown(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"0001_create.up.sql": {Data: []byte("CREATE TABLE a (id INT);")},
+	}
+
+	_, err := Load(fsys)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "needs both an up and a down file")
+}
+
+func testMigrator(t *testing.T) (*Migrator, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return &Migrator{
+		DB: db,
+		Migrations: []Migration{
+			{Version: 1, Name: "create", Up: "CREATE TABLE a", Down: "DROP TABLE a"},
+			{Version: 2, Name: "alter", Up: "ALTER TABLE a ADD", Down: "ALTER TABLE a DROP"},
+			{Version: 3, Name: "index", Up: "CREATE INDEX", Down: "DROP INDEX"},
+		},
+	}, mock
+}
+
+func expectApplied(mock sqlmock.Sqlmock, versions ...int) {
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	rows := sqlmock.NewRows([]string{"version", "applied_at"})
+	for _, version := range versions {
+		rows.AddRow(version, time.Now())
+	}
+	mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").WillReturnRows(rows)
+}
+
+func TestUpAppliesPendingMigrations(t *testing.T) {
+
+	migrator, mock := testMigrator(t)
+
+	expectApplied(mock, 1)
+	for _, migration := range migrator.Migrations[1:] {
+		mock.ExpectBegin()
+		mock.ExpectExec(migration.Up).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO schema_migrations \\(version, name\\) VALUES \\(\\$1, \\$2\\)").
+			WithArgs(migration.Version, migration.Name).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+	}
+
+	done, err := migrator.Up(context.Background())
+	require.NoError(t, err)
+	require.Len(t, done, 2)
+	assert.Equal(t, 2, done[0].Version)
+	assert.Equal(t, 3, done[1].Version)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDownRevertsLatestMigration(t *testing.T) {
+
+	migrator, mock := testMigrator(t)
+
+	expectApplied(mock, 1, 2, 3)
+	expectApplied(mock, 1, 2, 3)
+	mock.ExpectBegin()
+	mock.ExpectExec("DROP INDEX").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM schema_migrations WHERE version = \\$1").
+		WithArgs(3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	done, err := migrator.Down(context.Background(), 1)
+	require.NoError(t, err)
+	require.Len(t, done, 1)
+	assert.Equal(t, 3, done[0].Version)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFailedMigrationRollsBack(t *testing.T) {
+
+	migrator, mock := testMigrator(t)
+
+	expectApplied(mock)
+	mock.ExpectBegin()
+	mock.ExpectExec("CREATE TABLE a").WillReturnError(assert.AnError)
+	mock.ExpectRollback()
+
+	done, err := migrator.Goto(context.Background(), 2)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to apply migration 1_create")
+	assert.Empty(t, done)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGotoUnknownVersion(t *testing.T) {
+
+	migrator, _ := testMigrator(t)
+
+	_, err := migrator.Goto(context.Background(), 7)
+	require.Error(t, err)
+}
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS example_sentences;
+DROP TABLE IF EXISTS translations;
+DROP TABLE IF EXISTS polish_words;
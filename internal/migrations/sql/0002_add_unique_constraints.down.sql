@@ -0,0 +1,2 @@
+ALTER TABLE example_sentences DROP CONSTRAINT IF EXISTS uq_example_sentence_tid_senpl_senen;
+ALTER TABLE translations DROP CONSTRAINT IF EXISTS uq_translation_pwid_englishword;
@@ -0,0 +1,16 @@
+-- Databases created from the old initdb scripts may already have these.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'uq_translation_pwid_englishword') THEN
+        ALTER TABLE translations
+        ADD CONSTRAINT uq_translation_pwid_englishword
+        UNIQUE (polish_word_id, english_word);
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'uq_example_sentence_tid_senpl_senen') THEN
+        ALTER TABLE example_sentences
+        ADD CONSTRAINT uq_example_sentence_tid_senpl_senen
+        UNIQUE (translation_id, sentence_pl, sentence_en);
+    END IF;
+END
+$$;
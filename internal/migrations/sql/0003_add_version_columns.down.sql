@@ -0,0 +1,3 @@
+ALTER TABLE example_sentences DROP COLUMN IF EXISTS version;
+ALTER TABLE translations DROP COLUMN IF EXISTS version;
+ALTER TABLE polish_words DROP COLUMN IF EXISTS version;
@@ -0,0 +1,3 @@
+ALTER TABLE polish_words ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE translations ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE example_sentences ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_example_sentences_sentence_en_fts;
+DROP INDEX IF EXISTS idx_example_sentences_sentence_pl_fts;
+DROP INDEX IF EXISTS idx_translations_english_word_fts;
+DROP INDEX IF EXISTS idx_polish_words_word_fts;
+
+DROP TEXT SEARCH CONFIGURATION IF EXISTS polish;
@@ -0,0 +1,22 @@
+-- PostgreSQL does not ship a Polish text search configuration. Start from
+-- "simple" (lowercasing, no stemming) so that a dictionary-backed one, e.g.
+-- built on an ispell Polish dictionary, can replace it without touching queries.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'polish') THEN
+        CREATE TEXT SEARCH CONFIGURATION polish (COPY = simple);
+    END IF;
+END
+$$;
+
+CREATE INDEX IF NOT EXISTS idx_polish_words_word_fts
+ON polish_words USING GIN (to_tsvector('polish', word));
+
+CREATE INDEX IF NOT EXISTS idx_translations_english_word_fts
+ON translations USING GIN (to_tsvector('english', english_word));
+
+CREATE INDEX IF NOT EXISTS idx_example_sentences_sentence_pl_fts
+ON example_sentences USING GIN (to_tsvector('polish', sentence_pl));
+
+CREATE INDEX IF NOT EXISTS idx_example_sentences_sentence_en_fts
+ON example_sentences USING GIN (to_tsvector('english', sentence_en));
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_translations_english_word_lower;
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_translations_english_word_lower
+ON translations (lower(english_word));
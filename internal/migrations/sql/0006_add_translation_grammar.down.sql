@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_translations_part_of_speech;
+
+ALTER TABLE translations
+    DROP COLUMN IF EXISTS usage_note,
+    DROP COLUMN IF EXISTS aspect_partner,
+    DROP COLUMN IF EXISTS aspect,
+    DROP COLUMN IF EXISTS gender,
+    DROP COLUMN IF EXISTS part_of_speech;
@@ -0,0 +1,17 @@
+ALTER TABLE translations
+    ADD COLUMN IF NOT EXISTS part_of_speech TEXT
+        CONSTRAINT chk_translation_part_of_speech CHECK (part_of_speech IN (
+            'NOUN', 'VERB', 'ADJECTIVE', 'ADVERB', 'PRONOUN', 'NUMERAL',
+            'PREPOSITION', 'CONJUNCTION', 'PARTICLE', 'INTERJECTION'
+        )),
+    ADD COLUMN IF NOT EXISTS gender TEXT
+        CONSTRAINT chk_translation_gender CHECK (gender IN (
+            'MASCULINE_PERSONAL', 'MASCULINE_ANIMATE', 'MASCULINE_INANIMATE', 'FEMININE', 'NEUTER'
+        )),
+    ADD COLUMN IF NOT EXISTS aspect TEXT
+        CONSTRAINT chk_translation_aspect CHECK (aspect IN ('PERFECTIVE', 'IMPERFECTIVE')),
+    ADD COLUMN IF NOT EXISTS aspect_partner VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS usage_note VARCHAR(500);
+
+CREATE INDEX IF NOT EXISTS idx_translations_part_of_speech
+ON translations (part_of_speech, polish_word_id);
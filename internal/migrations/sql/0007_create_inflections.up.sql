@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS inflections (
+    id SERIAL PRIMARY KEY,
+    polish_word_id INTEGER NOT NULL,
+    form VARCHAR(50) NOT NULL,
+    grammatical_case TEXT CHECK (grammatical_case IN (
+        'NOMINATIVE', 'GENITIVE', 'DATIVE', 'ACCUSATIVE', 'INSTRUMENTAL', 'LOCATIVE', 'VOCATIVE'
+    )),
+    number TEXT CHECK (number IN ('SINGULAR', 'PLURAL')),
+    person TEXT CHECK (person IN ('FIRST', 'SECOND', 'THIRD')),
+    tense TEXT CHECK (tense IN ('PRESENT', 'PAST', 'FUTURE')),
+    gender TEXT CHECK (gender IN (
+        'MASCULINE_PERSONAL', 'MASCULINE_ANIMATE', 'MASCULINE_INANIMATE', 'FEMININE', 'NEUTER'
+    )),
+    version INTEGER NOT NULL DEFAULT 1,
+
+    CONSTRAINT fk_inflection_polish_word FOREIGN KEY (polish_word_id) REFERENCES polish_words (id) ON DELETE CASCADE
+);
+
+-- NULL features count as equal, so the same form cannot be stored twice.
+CREATE UNIQUE INDEX IF NOT EXISTS uq_inflection_form
+ON inflections (
+    polish_word_id, form,
+    COALESCE(grammatical_case, ''), COALESCE(number, ''), COALESCE(person, ''),
+    COALESCE(tense, ''), COALESCE(gender, '')
+);
+
+CREATE INDEX IF NOT EXISTS idx_inflections_form
+ON inflections (form);
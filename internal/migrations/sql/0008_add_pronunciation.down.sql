@@ -0,0 +1,4 @@
+ALTER TABLE polish_words
+    DROP COLUMN IF EXISTS stressed_syllable,
+    DROP COLUMN IF EXISTS syllabification,
+    DROP COLUMN IF EXISTS ipa;
@@ -0,0 +1,5 @@
+ALTER TABLE polish_words
+    ADD COLUMN IF NOT EXISTS ipa VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS syllabification VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS stressed_syllable SMALLINT
+        CONSTRAINT chk_polish_word_stressed_syllable CHECK (stressed_syllable > 0);
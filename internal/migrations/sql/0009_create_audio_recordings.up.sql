@@ -0,0 +1,18 @@
+-- A recording belongs to exactly one Polish word or example sentence. The
+-- audio itself lives in the blob store under blob_key.
+CREATE TABLE IF NOT EXISTS audio_recordings (
+    id SERIAL PRIMARY KEY,
+    polish_word_id INTEGER,
+    example_sentence_id INTEGER,
+    blob_key TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size INTEGER NOT NULL CHECK (size > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    CONSTRAINT fk_audio_recording_polish_word FOREIGN KEY (polish_word_id) REFERENCES polish_words (id) ON DELETE CASCADE,
+    CONSTRAINT fk_audio_recording_example_sentence FOREIGN KEY (example_sentence_id) REFERENCES example_sentences (id) ON DELETE CASCADE,
+    CONSTRAINT uq_audio_recording_polish_word UNIQUE (polish_word_id),
+    CONSTRAINT uq_audio_recording_example_sentence UNIQUE (example_sentence_id),
+    CONSTRAINT uq_audio_recording_blob_key UNIQUE (blob_key),
+    CONSTRAINT chk_audio_recording_owner CHECK ((polish_word_id IS NULL) <> (example_sentence_id IS NULL))
+);
@@ -0,0 +1,15 @@
+-- Lexemes and translations in other languages are dropped so the old unique
+-- constraints can be restored.
+DELETE FROM polish_words WHERE language <> 'pl';
+DELETE FROM translations WHERE language <> 'en';
+
+ALTER TABLE translations DROP CONSTRAINT IF EXISTS uq_translation_pwid_language_text;
+ALTER TABLE translations
+    ADD CONSTRAINT uq_translation_pwid_englishword UNIQUE (polish_word_id, english_word);
+
+ALTER TABLE polish_words DROP CONSTRAINT IF EXISTS uq_lexeme_language_word;
+ALTER TABLE polish_words
+    ADD CONSTRAINT polish_words_word_key UNIQUE (word);
+
+ALTER TABLE translations DROP COLUMN IF EXISTS language;
+ALTER TABLE polish_words DROP COLUMN IF EXISTS language;
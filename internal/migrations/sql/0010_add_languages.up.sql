@@ -0,0 +1,21 @@
+-- polish_words now holds lexemes in any source language and translations can
+-- target any language, both identified by BCP-47 tags. The column names are
+-- kept: polish_words.word is the lemma, translations.english_word is the
+-- translation text, and example_sentences.sentence_pl and sentence_en hold the
+-- sentence in the lexeme's and in the translation's language. Existing rows
+-- are Polish lexemes with English translations.
+ALTER TABLE polish_words
+    ADD COLUMN IF NOT EXISTS language VARCHAR(35) NOT NULL DEFAULT 'pl';
+
+ALTER TABLE translations
+    ADD COLUMN IF NOT EXISTS language VARCHAR(35) NOT NULL DEFAULT 'en';
+
+ALTER TABLE polish_words DROP CONSTRAINT IF EXISTS polish_words_word_key;
+ALTER TABLE polish_words DROP CONSTRAINT IF EXISTS uq_lexeme_language_word;
+ALTER TABLE polish_words
+    ADD CONSTRAINT uq_lexeme_language_word UNIQUE (language, word);
+
+ALTER TABLE translations DROP CONSTRAINT IF EXISTS uq_translation_pwid_englishword;
+ALTER TABLE translations DROP CONSTRAINT IF EXISTS uq_translation_pwid_language_text;
+ALTER TABLE translations
+    ADD CONSTRAINT uq_translation_pwid_language_text UNIQUE (polish_word_id, language, english_word);
@@ -0,0 +1,19 @@
+-- A row reads "related_word_id is the type of polish_word_id". Symmetric
+-- types are stored once in each direction.
+CREATE TABLE IF NOT EXISTS word_relations (
+    id SERIAL PRIMARY KEY,
+    polish_word_id INTEGER NOT NULL,
+    related_word_id INTEGER NOT NULL,
+    type TEXT NOT NULL,
+
+    CONSTRAINT fk_word_relation_polish_word FOREIGN KEY (polish_word_id) REFERENCES polish_words (id) ON DELETE CASCADE,
+    CONSTRAINT fk_word_relation_related_word FOREIGN KEY (related_word_id) REFERENCES polish_words (id) ON DELETE CASCADE,
+    CONSTRAINT uq_word_relation UNIQUE (polish_word_id, related_word_id, type),
+    CONSTRAINT chk_word_relation_type CHECK (type IN (
+        'SYNONYM', 'ANTONYM', 'HYPERNYM', 'DIMINUTIVE', 'ASPECT_PAIR', 'DERIVED_FROM'
+    )),
+    CONSTRAINT chk_word_relation_not_self CHECK (polish_word_id <> related_word_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_word_relations_related_word
+ON word_relations (related_word_id, type);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS translation_tags;
+DROP TABLE IF EXISTS polish_word_tags;
+DROP TABLE IF EXISTS tags;
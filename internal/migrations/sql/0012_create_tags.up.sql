@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS tags (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL
+);
+
+-- Tag names are unique without regard to case, so "IT" and "it" are one tag.
+CREATE UNIQUE INDEX IF NOT EXISTS uq_tag_name ON tags (LOWER(name));
+
+CREATE TABLE IF NOT EXISTS polish_word_tags (
+    polish_word_id INTEGER NOT NULL,
+    tag_id INTEGER NOT NULL,
+
+    CONSTRAINT pk_polish_word_tags PRIMARY KEY (polish_word_id, tag_id),
+    CONSTRAINT fk_polish_word_tag_polish_word FOREIGN KEY (polish_word_id) REFERENCES polish_words (id) ON DELETE CASCADE,
+    CONSTRAINT fk_polish_word_tag_tag FOREIGN KEY (tag_id) REFERENCES tags (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_polish_word_tags_tag ON polish_word_tags (tag_id);
+
+CREATE TABLE IF NOT EXISTS translation_tags (
+    translation_id INTEGER NOT NULL,
+    tag_id INTEGER NOT NULL,
+
+    CONSTRAINT pk_translation_tags PRIMARY KEY (translation_id, tag_id),
+    CONSTRAINT fk_translation_tag_translation FOREIGN KEY (translation_id) REFERENCES translations (id) ON DELETE CASCADE,
+    CONSTRAINT fk_translation_tag_tag FOREIGN KEY (tag_id) REFERENCES tags (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_translation_tags_tag ON translation_tags (tag_id);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_polish_words_cefr_level;
+DROP INDEX IF EXISTS idx_polish_words_frequency_rank;
+
+ALTER TABLE translations
+    DROP COLUMN IF EXISTS frequency_rank,
+    DROP COLUMN IF EXISTS cefr_level;
+
+ALTER TABLE polish_words
+    DROP COLUMN IF EXISTS frequency_rank,
+    DROP COLUMN IF EXISTS cefr_level;
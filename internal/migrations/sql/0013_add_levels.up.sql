@@ -0,0 +1,14 @@
+ALTER TABLE polish_words
+    ADD COLUMN IF NOT EXISTS cefr_level VARCHAR(2)
+        CONSTRAINT chk_polish_word_cefr_level CHECK (cefr_level IN ('A1', 'A2', 'B1', 'B2', 'C1', 'C2')),
+    ADD COLUMN IF NOT EXISTS frequency_rank INTEGER
+        CONSTRAINT chk_polish_word_frequency_rank CHECK (frequency_rank > 0);
+
+ALTER TABLE translations
+    ADD COLUMN IF NOT EXISTS cefr_level VARCHAR(2)
+        CONSTRAINT chk_translation_cefr_level CHECK (cefr_level IN ('A1', 'A2', 'B1', 'B2', 'C1', 'C2')),
+    ADD COLUMN IF NOT EXISTS frequency_rank INTEGER
+        CONSTRAINT chk_translation_frequency_rank CHECK (frequency_rank > 0);
+
+CREATE INDEX IF NOT EXISTS idx_polish_words_frequency_rank ON polish_words (frequency_rank, id);
+CREATE INDEX IF NOT EXISTS idx_polish_words_cefr_level ON polish_words (cefr_level, id);
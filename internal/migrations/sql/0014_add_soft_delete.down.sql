@@ -0,0 +1,24 @@
+-- Entries in the trash are deleted for good.
+DELETE FROM example_sentences WHERE deleted_at IS NOT NULL;
+DELETE FROM translations WHERE deleted_at IS NOT NULL;
+DELETE FROM polish_words WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_example_sentences_deleted_at;
+DROP INDEX IF EXISTS idx_translations_deleted_at;
+DROP INDEX IF EXISTS idx_polish_words_deleted_at;
+
+DROP INDEX IF EXISTS uq_example_sentence_tid_senpl_senen;
+ALTER TABLE example_sentences
+    ADD CONSTRAINT uq_example_sentence_tid_senpl_senen UNIQUE (translation_id, sentence_pl, sentence_en);
+
+DROP INDEX IF EXISTS uq_translation_pwid_language_text;
+ALTER TABLE translations
+    ADD CONSTRAINT uq_translation_pwid_language_text UNIQUE (polish_word_id, language, english_word);
+
+DROP INDEX IF EXISTS uq_lexeme_language_word;
+ALTER TABLE polish_words
+    ADD CONSTRAINT uq_lexeme_language_word UNIQUE (language, word);
+
+ALTER TABLE example_sentences DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE translations DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE polish_words DROP COLUMN IF EXISTS deleted_at;
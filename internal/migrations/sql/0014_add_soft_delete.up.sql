@@ -0,0 +1,22 @@
+-- Deleting an entry sets deleted_at on it and on the children that were still
+-- live, all to the same time, so restoring it brings back exactly what was
+-- deleted with it. Uniqueness only applies to live rows.
+ALTER TABLE polish_words ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE translations ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE example_sentences ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+ALTER TABLE polish_words DROP CONSTRAINT IF EXISTS uq_lexeme_language_word;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_lexeme_language_word
+ON polish_words (language, word) WHERE deleted_at IS NULL;
+
+ALTER TABLE translations DROP CONSTRAINT IF EXISTS uq_translation_pwid_language_text;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_translation_pwid_language_text
+ON translations (polish_word_id, language, english_word) WHERE deleted_at IS NULL;
+
+ALTER TABLE example_sentences DROP CONSTRAINT IF EXISTS uq_example_sentence_tid_senpl_senen;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_example_sentence_tid_senpl_senen
+ON example_sentences (translation_id, sentence_pl, sentence_en) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_polish_words_deleted_at ON polish_words (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_translations_deleted_at ON translations (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_example_sentences_deleted_at ON example_sentences (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS trg_example_sentences_history ON example_sentences;
+DROP TRIGGER IF EXISTS trg_translations_history ON translations;
+DROP TRIGGER IF EXISTS trg_polish_words_history ON polish_words;
+
+DROP FUNCTION IF EXISTS record_history();
+
+DROP TABLE IF EXISTS example_sentence_history;
+DROP TABLE IF EXISTS translation_history;
+DROP TABLE IF EXISTS polish_word_history;
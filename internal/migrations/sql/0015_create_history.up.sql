@@ -0,0 +1,112 @@
+-- Every write to an entry records a revision: a snapshot of the row, the
+-- fields that changed since the previous revision, who made the change and
+-- when. Statements of one transaction, such as an insert followed by setting
+-- its level, make up a single revision. The author is read from the
+-- transaction-local app.author setting. History outlives the entries, so
+-- deleted and purged entries keep theirs.
+CREATE TABLE IF NOT EXISTS polish_word_history (
+    id BIGSERIAL PRIMARY KEY,
+    entity_id INTEGER NOT NULL,
+    version INTEGER NOT NULL,
+    author TEXT,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    snapshot JSONB NOT NULL,
+    diff JSONB NOT NULL,
+    txid BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_polish_word_history_entity ON polish_word_history (entity_id, id);
+
+CREATE TABLE IF NOT EXISTS translation_history (
+    id BIGSERIAL PRIMARY KEY,
+    entity_id INTEGER NOT NULL,
+    version INTEGER NOT NULL,
+    author TEXT,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    snapshot JSONB NOT NULL,
+    diff JSONB NOT NULL,
+    txid BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_translation_history_entity ON translation_history (entity_id, id);
+
+CREATE TABLE IF NOT EXISTS example_sentence_history (
+    id BIGSERIAL PRIMARY KEY,
+    entity_id INTEGER NOT NULL,
+    version INTEGER NOT NULL,
+    author TEXT,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    snapshot JSONB NOT NULL,
+    diff JSONB NOT NULL,
+    txid BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_example_sentence_history_entity ON example_sentence_history (entity_id, id);
+
+-- Translations and example sentences never move to another parent, so their
+-- history can be looked up by the parent in the snapshot.
+CREATE INDEX IF NOT EXISTS idx_translation_history_polish_word
+ON translation_history (((snapshot ->> 'polish_word_id')::int), changed_at);
+CREATE INDEX IF NOT EXISTS idx_example_sentence_history_translation
+ON example_sentence_history (((snapshot ->> 'translation_id')::int), changed_at);
+
+CREATE OR REPLACE FUNCTION record_history() RETURNS trigger AS $$
+DECLARE
+    current JSONB := to_jsonb(NEW);
+    previous JSONB;
+    changes JSONB;
+    pending BIGINT;
+BEGIN
+    EXECUTE format('SELECT id FROM %I WHERE entity_id = $1 AND txid = txid_current()', TG_ARGV[0])
+    INTO pending USING NEW.id;
+
+    EXECUTE format('SELECT snapshot FROM %I WHERE entity_id = $1 AND txid <> txid_current() ORDER BY id DESC LIMIT 1', TG_ARGV[0])
+    INTO previous USING NEW.id;
+
+    SELECT COALESCE(jsonb_object_agg(key, jsonb_build_object('from', previous -> key, 'to', value)), '{}')
+    INTO changes
+    FROM jsonb_each(current)
+    WHERE key NOT IN ('id', 'version') AND (previous -> key) IS DISTINCT FROM value
+    AND NOT (previous IS NULL AND value = 'null');
+
+    IF pending IS NOT NULL THEN
+        EXECUTE format('UPDATE %I SET version = $2, snapshot = $3, diff = $4 WHERE id = $1', TG_ARGV[0])
+        USING pending, NEW.version, current, changes;
+    ELSIF changes <> '{}' THEN
+        EXECUTE format('INSERT INTO %I (entity_id, version, author, snapshot, diff, txid) VALUES ($1, $2, $3, $4, $5, txid_current())', TG_ARGV[0])
+        USING NEW.id, NEW.version, NULLIF(current_setting('app.author', true), ''), current, changes;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_polish_words_history ON polish_words;
+CREATE TRIGGER trg_polish_words_history
+AFTER INSERT OR UPDATE ON polish_words
+FOR EACH ROW EXECUTE FUNCTION record_history('polish_word_history');
+
+DROP TRIGGER IF EXISTS trg_translations_history ON translations;
+CREATE TRIGGER trg_translations_history
+AFTER INSERT OR UPDATE ON translations
+FOR EACH ROW EXECUTE FUNCTION record_history('translation_history');
+
+DROP TRIGGER IF EXISTS trg_example_sentences_history ON example_sentences;
+CREATE TRIGGER trg_example_sentences_history
+AFTER INSERT OR UPDATE ON example_sentences
+FOR EACH ROW EXECUTE FUNCTION record_history('example_sentence_history');
+
+-- Entries that predate the history start with a revision of their current
+-- state.
+INSERT INTO polish_word_history (entity_id, version, snapshot, diff, txid)
+SELECT e.id, e.version, to_jsonb(e), '{}', 0
+FROM polish_words e
+WHERE NOT EXISTS (SELECT 1 FROM polish_word_history h WHERE h.entity_id = e.id);
+INSERT INTO translation_history (entity_id, version, snapshot, diff, txid)
+SELECT e.id, e.version, to_jsonb(e), '{}', 0
+FROM translations e
+WHERE NOT EXISTS (SELECT 1 FROM translation_history h WHERE h.entity_id = e.id);
+INSERT INTO example_sentence_history (entity_id, version, snapshot, diff, txid)
+SELECT e.id, e.version, to_jsonb(e), '{}', 0
+FROM example_sentences e
+WHERE NOT EXISTS (SELECT 1 FROM example_sentence_history h WHERE h.entity_id = e.id);
@@ -0,0 +1,41 @@
+CREATE OR REPLACE FUNCTION record_history() RETURNS trigger AS $$
+DECLARE
+    current JSONB := to_jsonb(NEW);
+    previous JSONB;
+    changes JSONB;
+    pending BIGINT;
+BEGIN
+    EXECUTE format('SELECT id FROM %I WHERE entity_id = $1 AND txid = txid_current()', TG_ARGV[0])
+    INTO pending USING NEW.id;
+
+    EXECUTE format('SELECT snapshot FROM %I WHERE entity_id = $1 AND txid <> txid_current() ORDER BY id DESC LIMIT 1', TG_ARGV[0])
+    INTO previous USING NEW.id;
+
+    SELECT COALESCE(jsonb_object_agg(key, jsonb_build_object('from', previous -> key, 'to', value)), '{}')
+    INTO changes
+    FROM jsonb_each(current)
+    WHERE key NOT IN ('id', 'version') AND (previous -> key) IS DISTINCT FROM value
+    AND NOT (previous IS NULL AND value = 'null');
+
+    IF pending IS NOT NULL THEN
+        EXECUTE format('UPDATE %I SET version = $2, snapshot = $3, diff = $4 WHERE id = $1', TG_ARGV[0])
+        USING pending, NEW.version, current, changes;
+    ELSIF changes <> '{}' THEN
+        EXECUTE format('INSERT INTO %I (entity_id, version, author, snapshot, diff, txid) VALUES ($1, $2, $3, $4, $5, txid_current())', TG_ARGV[0])
+        USING NEW.id, NEW.version, NULLIF(current_setting('app.author', true), ''), current, changes;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS idx_example_sentence_history_change;
+DROP INDEX IF EXISTS idx_translation_history_change;
+DROP INDEX IF EXISTS idx_polish_word_history_change;
+
+ALTER TABLE example_sentence_history DROP COLUMN IF EXISTS change_id;
+ALTER TABLE translation_history DROP COLUMN IF EXISTS change_id;
+ALTER TABLE polish_word_history DROP COLUMN IF EXISTS change_id;
+
+DROP TABLE IF EXISTS audit_log;
+DROP FUNCTION IF EXISTS reject_audit_log_change();
@@ -0,0 +1,80 @@
+-- Every mutation is logged with who made it, from where and what it changed.
+-- Rows can only be added: updates, deletes and truncation are rejected.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    operation TEXT NOT NULL,
+    operation_name TEXT,
+    arguments JSONB NOT NULL,
+    affected_ids TEXT[] NOT NULL,
+    before JSONB NOT NULL,
+    after JSONB NOT NULL,
+    actor TEXT,
+    client_ip TEXT,
+    request_id TEXT NOT NULL,
+    error TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log (actor, id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_operation ON audit_log (operation, id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_occurred_at ON audit_log (occurred_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_affected_ids ON audit_log USING GIN (affected_ids);
+
+CREATE OR REPLACE FUNCTION reject_audit_log_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_audit_log_append_only ON audit_log;
+CREATE TRIGGER trg_audit_log_append_only
+BEFORE UPDATE OR DELETE ON audit_log
+FOR EACH ROW EXECUTE FUNCTION reject_audit_log_change();
+
+DROP TRIGGER IF EXISTS trg_audit_log_no_truncate ON audit_log;
+CREATE TRIGGER trg_audit_log_no_truncate
+BEFORE TRUNCATE ON audit_log
+FOR EACH STATEMENT EXECUTE FUNCTION reject_audit_log_change();
+
+-- Revisions record the mutation that wrote them, read from the
+-- transaction-local app.change_id setting, so its audit entry can show the
+-- entries before and after.
+ALTER TABLE polish_word_history ADD COLUMN IF NOT EXISTS change_id TEXT;
+ALTER TABLE translation_history ADD COLUMN IF NOT EXISTS change_id TEXT;
+ALTER TABLE example_sentence_history ADD COLUMN IF NOT EXISTS change_id TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_polish_word_history_change ON polish_word_history (change_id) WHERE change_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_translation_history_change ON translation_history (change_id) WHERE change_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_example_sentence_history_change ON example_sentence_history (change_id) WHERE change_id IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION record_history() RETURNS trigger AS $$
+DECLARE
+    current JSONB := to_jsonb(NEW);
+    previous JSONB;
+    changes JSONB;
+    pending BIGINT;
+BEGIN
+    EXECUTE format('SELECT id FROM %I WHERE entity_id = $1 AND txid = txid_current()', TG_ARGV[0])
+    INTO pending USING NEW.id;
+
+    EXECUTE format('SELECT snapshot FROM %I WHERE entity_id = $1 AND txid <> txid_current() ORDER BY id DESC LIMIT 1', TG_ARGV[0])
+    INTO previous USING NEW.id;
+
+    SELECT COALESCE(jsonb_object_agg(key, jsonb_build_object('from', previous -> key, 'to', value)), '{}')
+    INTO changes
+    FROM jsonb_each(current)
+    WHERE key NOT IN ('id', 'version') AND (previous -> key) IS DISTINCT FROM value
+    AND NOT (previous IS NULL AND value = 'null');
+
+    IF pending IS NOT NULL THEN
+        EXECUTE format('UPDATE %I SET version = $2, snapshot = $3, diff = $4 WHERE id = $1', TG_ARGV[0])
+        USING pending, NEW.version, current, changes;
+    ELSIF changes <> '{}' THEN
+        EXECUTE format('INSERT INTO %I (entity_id, version, author, snapshot, diff, txid, change_id) VALUES ($1, $2, $3, $4, $5, txid_current(), $6)', TG_ARGV[0])
+        USING NEW.id, NEW.version, NULLIF(current_setting('app.author', true), ''), current, changes,
+            NULLIF(current_setting('app.change_id', true), '');
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
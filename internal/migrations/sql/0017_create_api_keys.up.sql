@@ -0,0 +1,19 @@
+-- Keys are stored as SHA-256 hashes; the key itself is shown once, when it is
+-- created. prefix is its first characters, kept so a key can be recognised.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL,
+    roles TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+
+    CONSTRAINT uq_api_key_hash UNIQUE (key_hash),
+    CONSTRAINT chk_api_key_roles CHECK (roles <@ ARRAY['editor', 'admin']::TEXT[])
+);
+
+-- A name belongs to one live key at a time; revoked keys keep theirs.
+CREATE UNIQUE INDEX IF NOT EXISTS uq_api_key_name
+ON api_keys (name) WHERE revoked_at IS NULL;
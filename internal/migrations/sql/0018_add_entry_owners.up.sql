@@ -0,0 +1,11 @@
+-- owner is the caller who created an entry, taken from the transaction-local
+-- app.author setting like the author of a revision. Entries created before
+-- this migration have no owner. The default is set after the column is added
+-- so that existing rows are not given the owner of the migration.
+ALTER TABLE polish_words ADD COLUMN IF NOT EXISTS owner TEXT;
+ALTER TABLE translations ADD COLUMN IF NOT EXISTS owner TEXT;
+ALTER TABLE example_sentences ADD COLUMN IF NOT EXISTS owner TEXT;
+
+ALTER TABLE polish_words ALTER COLUMN owner SET DEFAULT NULLIF(current_setting('app.author', true), '');
+ALTER TABLE translations ALTER COLUMN owner SET DEFAULT NULLIF(current_setting('app.author', true), '');
+ALTER TABLE example_sentences ALTER COLUMN owner SET DEFAULT NULLIF(current_setting('app.author', true), '');
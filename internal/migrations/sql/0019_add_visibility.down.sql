@@ -0,0 +1,43 @@
+-- Private entries are deleted for good, as the shared dictionary could
+-- already hold entries with the same text.
+DELETE FROM example_sentences WHERE visibility = 'private';
+DELETE FROM translations WHERE visibility = 'private';
+DELETE FROM polish_words WHERE visibility = 'private';
+
+DROP TRIGGER IF EXISTS trg_example_sentences_visibility ON example_sentences;
+DROP TRIGGER IF EXISTS trg_translations_visibility ON translations;
+DROP TRIGGER IF EXISTS trg_polish_words_visibility ON polish_words;
+DROP FUNCTION IF EXISTS check_entry_visibility();
+DROP FUNCTION IF EXISTS visibility_within(TEXT, TEXT, TEXT, TEXT);
+
+DROP INDEX IF EXISTS idx_example_sentences_private_owner;
+DROP INDEX IF EXISTS idx_translations_private_owner;
+DROP INDEX IF EXISTS idx_polish_words_private_owner;
+
+DROP INDEX IF EXISTS uq_example_sentence_tid_senpl_senen;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_example_sentence_tid_senpl_senen
+ON example_sentences (translation_id, sentence_pl, sentence_en) WHERE deleted_at IS NULL;
+
+DROP INDEX IF EXISTS uq_translation_pwid_language_text;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_translation_pwid_language_text
+ON translations (polish_word_id, language, english_word) WHERE deleted_at IS NULL;
+
+DROP INDEX IF EXISTS uq_lexeme_language_word;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_lexeme_language_word
+ON polish_words (language, word) WHERE deleted_at IS NULL;
+
+ALTER TABLE example_sentences
+    DROP COLUMN IF EXISTS tenant,
+    DROP CONSTRAINT IF EXISTS chk_example_sentence_private_owner,
+    DROP CONSTRAINT IF EXISTS chk_example_sentence_visibility,
+    DROP COLUMN IF EXISTS visibility;
+ALTER TABLE translations
+    DROP COLUMN IF EXISTS tenant,
+    DROP CONSTRAINT IF EXISTS chk_translation_private_owner,
+    DROP CONSTRAINT IF EXISTS chk_translation_visibility,
+    DROP COLUMN IF EXISTS visibility;
+ALTER TABLE polish_words
+    DROP COLUMN IF EXISTS tenant,
+    DROP CONSTRAINT IF EXISTS chk_polish_word_private_owner,
+    DROP CONSTRAINT IF EXISTS chk_polish_word_visibility,
+    DROP COLUMN IF EXISTS visibility;
@@ -0,0 +1,105 @@
+-- Public entries are seen by everyone, shared ones by every identified caller
+-- and private ones only by their owner. Public and shared entries make up the
+-- shared dictionary; each owner's private entries are a dictionary of their
+-- own layered over it. tenant names the dictionary an entry belongs to, ''
+-- for the shared one, and uniqueness applies within it.
+ALTER TABLE polish_words
+    ADD COLUMN IF NOT EXISTS visibility TEXT NOT NULL DEFAULT 'public'
+        CONSTRAINT chk_polish_word_visibility CHECK (visibility IN ('public', 'shared', 'private'))
+        CONSTRAINT chk_polish_word_private_owner CHECK (visibility <> 'private' OR owner IS NOT NULL),
+    ADD COLUMN IF NOT EXISTS tenant TEXT GENERATED ALWAYS AS (CASE WHEN visibility = 'private' THEN owner ELSE '' END) STORED;
+ALTER TABLE translations
+    ADD COLUMN IF NOT EXISTS visibility TEXT NOT NULL DEFAULT 'public'
+        CONSTRAINT chk_translation_visibility CHECK (visibility IN ('public', 'shared', 'private'))
+        CONSTRAINT chk_translation_private_owner CHECK (visibility <> 'private' OR owner IS NOT NULL),
+    ADD COLUMN IF NOT EXISTS tenant TEXT GENERATED ALWAYS AS (CASE WHEN visibility = 'private' THEN owner ELSE '' END) STORED;
+ALTER TABLE example_sentences
+    ADD COLUMN IF NOT EXISTS visibility TEXT NOT NULL DEFAULT 'public'
+        CONSTRAINT chk_example_sentence_visibility CHECK (visibility IN ('public', 'shared', 'private'))
+        CONSTRAINT chk_example_sentence_private_owner CHECK (visibility <> 'private' OR owner IS NOT NULL),
+    ADD COLUMN IF NOT EXISTS tenant TEXT GENERATED ALWAYS AS (CASE WHEN visibility = 'private' THEN owner ELSE '' END) STORED;
+
+DROP INDEX IF EXISTS uq_lexeme_language_word;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_lexeme_language_word
+ON polish_words (tenant, language, word) WHERE deleted_at IS NULL;
+
+DROP INDEX IF EXISTS uq_translation_pwid_language_text;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_translation_pwid_language_text
+ON translations (tenant, polish_word_id, language, english_word) WHERE deleted_at IS NULL;
+
+DROP INDEX IF EXISTS uq_example_sentence_tid_senpl_senen;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_example_sentence_tid_senpl_senen
+ON example_sentences (tenant, translation_id, sentence_pl, sentence_en) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_polish_words_private_owner ON polish_words (owner) WHERE visibility = 'private';
+CREATE INDEX IF NOT EXISTS idx_translations_private_owner ON translations (owner) WHERE visibility = 'private';
+CREATE INDEX IF NOT EXISTS idx_example_sentences_private_owner ON example_sentences (owner) WHERE visibility = 'private';
+
+-- An entry may not be seen by more callers than the entry it belongs to, and
+-- the entries of a private one are private entries of the same owner, or an
+-- entry could be found without its word or translation. The check is deferred
+-- to commit so that an entry and its children can change visibility in either
+-- order within a transaction, and reads the rows as they are by then.
+CREATE OR REPLACE FUNCTION visibility_within(child_visibility TEXT, child_owner TEXT, parent_visibility TEXT, parent_owner TEXT)
+RETURNS BOOLEAN AS $$
+    SELECT CASE parent_visibility
+        WHEN 'private' THEN child_visibility = 'private' AND child_owner IS NOT DISTINCT FROM parent_owner
+        WHEN 'shared' THEN child_visibility <> 'public'
+        ELSE true
+    END;
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION check_entry_visibility() RETURNS trigger AS $$
+DECLARE
+    violated BOOLEAN;
+BEGIN
+    IF TG_TABLE_NAME = 'polish_words' THEN
+        SELECT EXISTS (
+            SELECT 1 FROM polish_words p JOIN translations t ON t.polish_word_id = p.id
+            WHERE p.id = NEW.id AND p.deleted_at IS NULL AND t.deleted_at IS NULL
+                AND NOT visibility_within(t.visibility, t.owner, p.visibility, p.owner)
+        ) INTO violated;
+    ELSIF TG_TABLE_NAME = 'translations' THEN
+        SELECT EXISTS (
+            SELECT 1 FROM translations t JOIN polish_words p ON p.id = t.polish_word_id
+            WHERE t.id = NEW.id AND t.deleted_at IS NULL
+                AND NOT visibility_within(t.visibility, t.owner, p.visibility, p.owner)
+            UNION ALL
+            SELECT 1 FROM translations t JOIN example_sentences es ON es.translation_id = t.id
+            WHERE t.id = NEW.id AND t.deleted_at IS NULL AND es.deleted_at IS NULL
+                AND NOT visibility_within(es.visibility, es.owner, t.visibility, t.owner)
+        ) INTO violated;
+    ELSE
+        SELECT EXISTS (
+            SELECT 1 FROM example_sentences es JOIN translations t ON t.id = es.translation_id
+            WHERE es.id = NEW.id AND es.deleted_at IS NULL
+                AND NOT visibility_within(es.visibility, es.owner, t.visibility, t.owner)
+        ) INTO violated;
+    END IF;
+
+    IF violated THEN
+        RAISE EXCEPTION 'an entry may not be more visible than the entry it belongs to'
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'chk_visibility_within_parent';
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_polish_words_visibility ON polish_words;
+CREATE CONSTRAINT TRIGGER trg_polish_words_visibility
+AFTER INSERT OR UPDATE OF visibility, deleted_at ON polish_words
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW EXECUTE FUNCTION check_entry_visibility();
+
+DROP TRIGGER IF EXISTS trg_translations_visibility ON translations;
+CREATE CONSTRAINT TRIGGER trg_translations_visibility
+AFTER INSERT OR UPDATE OF visibility, deleted_at ON translations
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW EXECUTE FUNCTION check_entry_visibility();
+
+DROP TRIGGER IF EXISTS trg_example_sentences_visibility ON example_sentences;
+CREATE CONSTRAINT TRIGGER trg_example_sentences_visibility
+AFTER INSERT OR UPDATE OF visibility, deleted_at ON example_sentences
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW EXECUTE FUNCTION check_entry_visibility();
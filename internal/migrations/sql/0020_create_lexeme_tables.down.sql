@@ -0,0 +1,53 @@
+-- Texts in languages other than the lexeme's and the translation's are lost.
+DROP TRIGGER IF EXISTS trg_lexemes_texts ON lexemes;
+DROP TRIGGER IF EXISTS trg_translations_texts ON translations;
+DROP TRIGGER IF EXISTS trg_example_sentences_texts ON example_sentences;
+DROP FUNCTION IF EXISTS sync_sentence_texts();
+DROP FUNCTION IF EXISTS copy_sentence_texts(INTEGER[]);
+DROP TABLE IF EXISTS sentence_texts;
+
+DROP VIEW IF EXISTS polish_words;
+DO $$
+BEGIN
+    IF to_regclass('polish_words') IS NULL THEN
+        ALTER TABLE lexemes RENAME TO polish_words;
+    END IF;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION check_entry_visibility() RETURNS trigger AS $$
+DECLARE
+    violated BOOLEAN;
+BEGIN
+    IF TG_TABLE_NAME = 'polish_words' THEN
+        SELECT EXISTS (
+            SELECT 1 FROM polish_words p JOIN translations t ON t.polish_word_id = p.id
+            WHERE p.id = NEW.id AND p.deleted_at IS NULL AND t.deleted_at IS NULL
+                AND NOT visibility_within(t.visibility, t.owner, p.visibility, p.owner)
+        ) INTO violated;
+    ELSIF TG_TABLE_NAME = 'translations' THEN
+        SELECT EXISTS (
+            SELECT 1 FROM translations t JOIN polish_words p ON p.id = t.polish_word_id
+            WHERE t.id = NEW.id AND t.deleted_at IS NULL
+                AND NOT visibility_within(t.visibility, t.owner, p.visibility, p.owner)
+            UNION ALL
+            SELECT 1 FROM translations t JOIN example_sentences es ON es.translation_id = t.id
+            WHERE t.id = NEW.id AND t.deleted_at IS NULL AND es.deleted_at IS NULL
+                AND NOT visibility_within(es.visibility, es.owner, t.visibility, t.owner)
+        ) INTO violated;
+    ELSE
+        SELECT EXISTS (
+            SELECT 1 FROM example_sentences es JOIN translations t ON t.id = es.translation_id
+            WHERE es.id = NEW.id AND es.deleted_at IS NULL
+                AND NOT visibility_within(es.visibility, es.owner, t.visibility, t.owner)
+        ) INTO violated;
+    END IF;
+
+    IF violated THEN
+        RAISE EXCEPTION 'an entry may not be more visible than the entry it belongs to'
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'chk_visibility_within_parent';
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
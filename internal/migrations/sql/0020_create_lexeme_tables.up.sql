@@ -0,0 +1,125 @@
+-- polish_words has held lexemes in every source language since 0010 and is
+-- renamed to lexemes. polish_words stays as a view of the Polish lexemes for
+-- queries and tools that still use the old name.
+DO $$
+BEGIN
+    IF to_regclass('lexemes') IS NULL THEN
+        ALTER TABLE polish_words RENAME TO lexemes;
+    END IF;
+END;
+$$;
+
+CREATE OR REPLACE VIEW polish_words AS
+SELECT * FROM lexemes WHERE language = 'pl'
+WITH LOCAL CHECK OPTION;
+
+-- The visibility check tells the tables apart by name.
+CREATE OR REPLACE FUNCTION check_entry_visibility() RETURNS trigger AS $$
+DECLARE
+    violated BOOLEAN;
+BEGIN
+    IF TG_TABLE_NAME = 'lexemes' THEN
+        SELECT EXISTS (
+            SELECT 1 FROM lexemes p JOIN translations t ON t.polish_word_id = p.id
+            WHERE p.id = NEW.id AND p.deleted_at IS NULL AND t.deleted_at IS NULL
+                AND NOT visibility_within(t.visibility, t.owner, p.visibility, p.owner)
+        ) INTO violated;
+    ELSIF TG_TABLE_NAME = 'translations' THEN
+        SELECT EXISTS (
+            SELECT 1 FROM translations t JOIN lexemes p ON p.id = t.polish_word_id
+            WHERE t.id = NEW.id AND t.deleted_at IS NULL
+                AND NOT visibility_within(t.visibility, t.owner, p.visibility, p.owner)
+            UNION ALL
+            SELECT 1 FROM translations t JOIN example_sentences es ON es.translation_id = t.id
+            WHERE t.id = NEW.id AND t.deleted_at IS NULL AND es.deleted_at IS NULL
+                AND NOT visibility_within(es.visibility, es.owner, t.visibility, t.owner)
+        ) INTO violated;
+    ELSE
+        SELECT EXISTS (
+            SELECT 1 FROM example_sentences es JOIN translations t ON t.id = es.translation_id
+            WHERE es.id = NEW.id AND es.deleted_at IS NULL
+                AND NOT visibility_within(es.visibility, es.owner, t.visibility, t.owner)
+        ) INTO violated;
+    END IF;
+
+    IF violated THEN
+        RAISE EXCEPTION 'an entry may not be more visible than the entry it belongs to'
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'chk_visibility_within_parent';
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- A sentence has one text per language. sentence_pl and sentence_en stay on
+-- example_sentences as the texts in the language of the lexeme and of the
+-- translation; they are copied here whenever they or those languages change,
+-- so sentence_texts holds every text of a sentence. Texts in further
+-- languages are only stored here.
+CREATE TABLE IF NOT EXISTS sentence_texts (
+    example_sentence_id INTEGER NOT NULL,
+    language VARCHAR(35) NOT NULL,
+    text TEXT NOT NULL,
+    CONSTRAINT pk_sentence_texts PRIMARY KEY (example_sentence_id, language),
+    CONSTRAINT fk_sentence_text_example_sentence FOREIGN KEY (example_sentence_id) REFERENCES example_sentences (id) ON DELETE CASCADE
+);
+
+CREATE OR REPLACE FUNCTION copy_sentence_texts(sentence_ids INTEGER[]) RETURNS void AS $$
+    INSERT INTO sentence_texts (example_sentence_id, language, text)
+    SELECT es.id, l.language, es.sentence_pl
+    FROM example_sentences es
+    JOIN translations t ON t.id = es.translation_id
+    JOIN lexemes l ON l.id = t.polish_word_id
+    WHERE es.id = ANY(sentence_ids)
+    UNION ALL
+    SELECT es.id, t.language, es.sentence_en
+    FROM example_sentences es
+    JOIN translations t ON t.id = es.translation_id
+    JOIN lexemes l ON l.id = t.polish_word_id
+    WHERE es.id = ANY(sentence_ids) AND t.language <> l.language
+    ON CONFLICT (example_sentence_id, language) DO UPDATE SET text = EXCLUDED.text;
+$$ LANGUAGE sql;
+
+-- When a lexeme or translation changes language, the text its sentences had
+-- in the old language moves to the new one.
+CREATE OR REPLACE FUNCTION sync_sentence_texts() RETURNS trigger AS $$
+DECLARE
+    sentence_ids INTEGER[];
+BEGIN
+    IF TG_TABLE_NAME = 'example_sentences' THEN
+        sentence_ids := ARRAY[NEW.id];
+    ELSIF TG_TABLE_NAME = 'translations' THEN
+        sentence_ids := ARRAY(SELECT id FROM example_sentences WHERE translation_id = NEW.id);
+    ELSE
+        sentence_ids := ARRAY(
+            SELECT es.id FROM example_sentences es JOIN translations t ON t.id = es.translation_id
+            WHERE t.polish_word_id = NEW.id);
+    END IF;
+
+    IF TG_TABLE_NAME <> 'example_sentences' THEN
+        DELETE FROM sentence_texts WHERE example_sentence_id = ANY(sentence_ids) AND language = OLD.language;
+    END IF;
+
+    PERFORM copy_sentence_texts(sentence_ids);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_example_sentences_texts ON example_sentences;
+CREATE TRIGGER trg_example_sentences_texts
+AFTER INSERT OR UPDATE OF sentence_pl, sentence_en ON example_sentences
+FOR EACH ROW EXECUTE FUNCTION sync_sentence_texts();
+
+DROP TRIGGER IF EXISTS trg_translations_texts ON translations;
+CREATE TRIGGER trg_translations_texts
+AFTER UPDATE OF language ON translations
+FOR EACH ROW WHEN (OLD.language IS DISTINCT FROM NEW.language)
+EXECUTE FUNCTION sync_sentence_texts();
+
+DROP TRIGGER IF EXISTS trg_lexemes_texts ON lexemes;
+CREATE TRIGGER trg_lexemes_texts
+AFTER UPDATE OF language ON lexemes
+FOR EACH ROW WHEN (OLD.language IS DISTINCT FROM NEW.language)
+EXECUTE FUNCTION sync_sentence_texts();
+
+SELECT copy_sentence_texts(ARRAY(SELECT id FROM example_sentences));
@@ -0,0 +1,3 @@
+ALTER TABLE lexemes ALTER COLUMN owner SET DEFAULT NULLIF(current_setting('app.author', true), '');
+ALTER TABLE translations ALTER COLUMN owner SET DEFAULT NULLIF(current_setting('app.author', true), '');
+ALTER TABLE example_sentences ALTER COLUMN owner SET DEFAULT NULLIF(current_setting('app.author', true), '');
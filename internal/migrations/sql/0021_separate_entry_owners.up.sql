@@ -0,0 +1,7 @@
+-- owner is taken from its own transaction-local app.owner setting rather than
+-- from app.author. Commands record their name as the author of the revisions
+-- they write, but are nobody's entries, so what they create has no owner
+-- unless one is given.
+ALTER TABLE lexemes ALTER COLUMN owner SET DEFAULT NULLIF(current_setting('app.owner', true), '');
+ALTER TABLE translations ALTER COLUMN owner SET DEFAULT NULLIF(current_setting('app.owner', true), '');
+ALTER TABLE example_sentences ALTER COLUMN owner SET DEFAULT NULLIF(current_setting('app.owner', true), '');
@@ -0,0 +1,11 @@
+DROP TRIGGER IF EXISTS trg_sentence_texts_changes ON sentence_texts;
+DROP TRIGGER IF EXISTS trg_translation_tags_changes ON translation_tags;
+DROP TRIGGER IF EXISTS trg_polish_word_tags_changes ON polish_word_tags;
+DROP TRIGGER IF EXISTS trg_tags_changes ON tags;
+DROP TRIGGER IF EXISTS trg_word_relations_changes ON word_relations;
+DROP TRIGGER IF EXISTS trg_audio_recordings_changes ON audio_recordings;
+DROP TRIGGER IF EXISTS trg_inflections_changes ON inflections;
+
+DROP FUNCTION IF EXISTS record_entry_change();
+
+DROP TABLE IF EXISTS entry_changes;
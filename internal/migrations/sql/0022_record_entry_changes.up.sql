@@ -0,0 +1,77 @@
+-- Inflections, tags, word relations, recordings and sentence texts have no
+-- history of their own. What a mutation does to them is recorded here under
+-- its app.change_id, as the row before and after each statement, so that its
+-- audit entry can show them like the revisions of words, translations and
+-- sentences. Rows are removed once the audit entry has been written; writes
+-- outside a mutation, such as commands, are not recorded.
+CREATE TABLE IF NOT EXISTS entry_changes (
+    id BIGSERIAL PRIMARY KEY,
+    change_id TEXT NOT NULL,
+    entity TEXT NOT NULL,
+    entity_id TEXT NOT NULL,
+    before JSONB,
+    after JSONB
+);
+
+CREATE INDEX IF NOT EXISTS idx_entry_changes_change ON entry_changes (change_id);
+
+-- The first argument is the entity prefix of the keys in the audit entry, the
+-- others the columns that identify a row, joined with ':' for rows with a
+-- composite key.
+CREATE OR REPLACE FUNCTION record_entry_change() RETURNS trigger AS $$
+DECLARE
+    current_change TEXT := NULLIF(current_setting('app.change_id', true), '');
+    entry JSONB := to_jsonb(COALESCE(NEW, OLD));
+    entry_key TEXT;
+BEGIN
+    IF current_change IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    SELECT string_agg(entry ->> column_name, ':' ORDER BY position)
+    INTO entry_key
+    FROM unnest(TG_ARGV[1:]) WITH ORDINALITY AS k(column_name, position);
+
+    INSERT INTO entry_changes (change_id, entity, entity_id, before, after)
+    VALUES (current_change, TG_ARGV[0], entry_key,
+        CASE WHEN TG_OP <> 'INSERT' THEN to_jsonb(OLD) END,
+        CASE WHEN TG_OP <> 'DELETE' THEN to_jsonb(NEW) END);
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_inflections_changes ON inflections;
+CREATE TRIGGER trg_inflections_changes
+AFTER INSERT OR UPDATE OR DELETE ON inflections
+FOR EACH ROW EXECUTE FUNCTION record_entry_change('inflection', 'id');
+
+DROP TRIGGER IF EXISTS trg_audio_recordings_changes ON audio_recordings;
+CREATE TRIGGER trg_audio_recordings_changes
+AFTER INSERT OR UPDATE OR DELETE ON audio_recordings
+FOR EACH ROW EXECUTE FUNCTION record_entry_change('audio_recording', 'id');
+
+DROP TRIGGER IF EXISTS trg_word_relations_changes ON word_relations;
+CREATE TRIGGER trg_word_relations_changes
+AFTER INSERT OR UPDATE OR DELETE ON word_relations
+FOR EACH ROW EXECUTE FUNCTION record_entry_change('word_relation', 'id');
+
+DROP TRIGGER IF EXISTS trg_tags_changes ON tags;
+CREATE TRIGGER trg_tags_changes
+AFTER INSERT OR UPDATE OR DELETE ON tags
+FOR EACH ROW EXECUTE FUNCTION record_entry_change('tag', 'id');
+
+DROP TRIGGER IF EXISTS trg_polish_word_tags_changes ON polish_word_tags;
+CREATE TRIGGER trg_polish_word_tags_changes
+AFTER INSERT OR UPDATE OR DELETE ON polish_word_tags
+FOR EACH ROW EXECUTE FUNCTION record_entry_change('polish_word_tag', 'polish_word_id', 'tag_id');
+
+DROP TRIGGER IF EXISTS trg_translation_tags_changes ON translation_tags;
+CREATE TRIGGER trg_translation_tags_changes
+AFTER INSERT OR UPDATE OR DELETE ON translation_tags
+FOR EACH ROW EXECUTE FUNCTION record_entry_change('translation_tag', 'translation_id', 'tag_id');
+
+DROP TRIGGER IF EXISTS trg_sentence_texts_changes ON sentence_texts;
+CREATE TRIGGER trg_sentence_texts_changes
+AFTER INSERT OR UPDATE OR DELETE ON sentence_texts
+FOR EACH ROW EXECUTE FUNCTION record_entry_change('sentence_text', 'example_sentence_id', 'language');
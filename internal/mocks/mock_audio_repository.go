@@ -0,0 +1,36 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAudioRepository struct {
+	mock.Mock
+}
+
+func (m *MockAudioRepository) AttachPolishWordAudio(ctx context.Context, polishWordID *string, polishWord *string, audio *model.Audio) (*model.PolishWord, string, error) {
+
+	args := m.Called(ctx, polishWordID, polishWord, audio)
+	pw, _ := args.Get(0).(*model.PolishWord)
+	return pw, args.String(1), args.Error(2)
+}
+
+func (m *MockAudioRepository) AttachExampleSentenceAudio(ctx context.Context, exampleSentenceID string, audio *model.Audio) (*model.ExampleSentence, string, error) {
+
+	args := m.Called(ctx, exampleSentenceID, audio)
+	es, _ := args.Get(0).(*model.ExampleSentence)
+	return es, args.String(1), args.Error(2)
+}
+
+func (m *MockAudioRepository) GetAudioByPolishWordIDs(ctx context.Context, polishWordIDs []string) (map[string]*model.Audio, error) {
+
+	return GetMockResult[map[string]*model.Audio](m.Called(ctx, polishWordIDs))
+}
+
+func (m *MockAudioRepository) GetAudioByExampleSentenceIDs(ctx context.Context, exampleSentenceIDs []string) (map[string]*model.Audio, error) {
+
+	return GetMockResult[map[string]*model.Audio](m.Called(ctx, exampleSentenceIDs))
+}
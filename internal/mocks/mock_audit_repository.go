@@ -0,0 +1,28 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/repository"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) RecordAudit(ctx context.Context, record *repository.AuditRecord) error {
+
+	return m.Called(ctx, record).Error(0)
+}
+
+func (m *MockAuditRepository) GetChanges(ctx context.Context, changeID string) (*repository.Changes, error) {
+
+	return GetMockResult[*repository.Changes](m.Called(ctx, changeID))
+}
+
+func (m *MockAuditRepository) GetAuditLog(ctx context.Context, filter *model.AuditLogFilter, first *int, after *string) (*model.AuditLogConnection, error) {
+
+	return GetMockResult[*model.AuditLogConnection](m.Called(ctx, filter, first, after))
+}
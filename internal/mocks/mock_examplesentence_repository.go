@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/repository"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -35,3 +36,18 @@ func (m *MockExampleSentenceRepository) GetExampleSentencesByTranslationId(ctx c
 
 	return GetMockResult[[]*model.ExampleSentence](m.Called(ctx, translationID))
 }
+
+func (m *MockExampleSentenceRepository) GetExampleSentencesByTranslationIDs(ctx context.Context, translationIDs []string) (map[string][]*model.ExampleSentence, error) {
+
+	return GetMockResult[map[string][]*model.ExampleSentence](m.Called(ctx, translationIDs))
+}
+
+func (m *MockExampleSentenceRepository) GetAccessByExampleSentenceIDs(ctx context.Context, ids []string) (map[string]*repository.Access, error) {
+
+	return GetMockResult[map[string]*repository.Access](m.Called(ctx, ids))
+}
+
+func (m *MockExampleSentenceRepository) GetTextsByExampleSentenceIDs(ctx context.Context, ids []string) (map[string][]*model.SentenceText, error) {
+
+	return GetMockResult[map[string][]*model.SentenceText](m.Called(ctx, ids))
+}
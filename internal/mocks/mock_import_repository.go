@@ -0,0 +1,18 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/repository"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockImportRepository struct {
+	mock.Mock
+}
+
+func (m *MockImportRepository) ImportBatch(ctx context.Context, records []repository.ImportRecord) ([]*model.ImportRowResult, error) {
+
+	return GetMockResult[[]*model.ImportRowResult](m.Called(ctx, records))
+}
@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockInflectionRepository struct {
+	mock.Mock
+}
+
+func (m *MockInflectionRepository) AddInflection(ctx context.Context, polishWordID *string, polishWord *string, inflection model.AddInflectionInput) (*model.Inflection, error) {
+
+	return GetMockResult[*model.Inflection](m.Called(ctx, polishWordID, polishWord, inflection))
+}
+
+func (m *MockInflectionRepository) DeleteInflection(ctx context.Context, id string) (*model.Inflection, error) {
+
+	return GetMockResult[*model.Inflection](m.Called(ctx, id))
+}
+
+func (m *MockInflectionRepository) UpdateInflection(ctx context.Context, id string, edits model.EditInflectionInput) (*model.Inflection, error) {
+
+	return GetMockResult[*model.Inflection](m.Called(ctx, id, edits))
+}
+
+func (m *MockInflectionRepository) GetInflectionsByPolishWordIDs(ctx context.Context, polishWordIDs []string) (map[string][]*model.Inflection, error) {
+
+	return GetMockResult[map[string][]*model.Inflection](m.Called(ctx, polishWordIDs))
+}
@@ -0,0 +1,47 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLexemeRepository struct {
+	mock.Mock
+}
+
+func (m *MockLexemeRepository) AddLexeme(ctx context.Context, lexeme model.AddLexemeInput) (*model.Lexeme, error) {
+
+	return GetMockResult[*model.Lexeme](m.Called(ctx, lexeme))
+}
+
+func (m *MockLexemeRepository) DeleteLexeme(ctx context.Context, id string) (*model.Lexeme, error) {
+
+	return GetMockResult[*model.Lexeme](m.Called(ctx, id))
+}
+
+func (m *MockLexemeRepository) AddLexemeTranslation(ctx context.Context, lexemeID string, translation model.AddLexemeTranslationInput) (*model.Translation, error) {
+
+	return GetMockResult[*model.Translation](m.Called(ctx, lexemeID, translation))
+}
+
+func (m *MockLexemeRepository) GetLexeme(ctx context.Context, id *string, language *string, lemma *string) (*model.Lexeme, error) {
+
+	return GetMockResult[*model.Lexeme](m.Called(ctx, id, language, lemma))
+}
+
+func (m *MockLexemeRepository) GetLexemes(ctx context.Context, language *string) ([]*model.Lexeme, error) {
+
+	return GetMockResult[[]*model.Lexeme](m.Called(ctx, language))
+}
+
+func (m *MockLexemeRepository) GetLexemesByIDs(ctx context.Context, ids []string) (map[string]*model.Lexeme, error) {
+
+	return GetMockResult[map[string]*model.Lexeme](m.Called(ctx, ids))
+}
+
+func (m *MockLexemeRepository) GetTranslationsByText(ctx context.Context, text string, language string) ([]*model.Translation, error) {
+
+	return GetMockResult[[]*model.Translation](m.Called(ctx, text, language))
+}
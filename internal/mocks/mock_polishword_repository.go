@@ -2,8 +2,10 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/repository"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -26,12 +28,57 @@ func (m *MockPolishWordRepository) UpdatePolishWord(ctx context.Context, id *str
 	return GetMockResult[*model.PolishWord](m.Called(ctx, id, word, edits))
 }
 
-func (m *MockPolishWordRepository) GetAllPolishWords(ctx context.Context) ([]*model.PolishWord, error) {
+func (m *MockPolishWordRepository) GetAllPolishWords(ctx context.Context, filter *model.PolishWordFilter, orderBy *model.PolishWordOrder) ([]*model.PolishWord, error) {
 
-	return GetMockResult[[]*model.PolishWord](m.Called(ctx))
+	return GetMockResult[[]*model.PolishWord](m.Called(ctx, filter, orderBy))
+}
+
+func (m *MockPolishWordRepository) GetPolishWordsPage(ctx context.Context, first *int, after *string, last *int, before *string, filter *model.PolishWordFilter) (*model.PolishWordConnection, error) {
+
+	return GetMockResult[*model.PolishWordConnection](m.Called(ctx, first, after, last, before, filter))
 }
 
 func (m *MockPolishWordRepository) GetSinglePolishWord(ctx context.Context, id *string, word *string) (*model.PolishWord, error) {
 
 	return GetMockResult[*model.PolishWord](m.Called(ctx, id, word))
 }
+
+func (m *MockPolishWordRepository) GetPolishWordsByIDs(ctx context.Context, ids []string) (map[string]*model.PolishWord, error) {
+
+	return GetMockResult[map[string]*model.PolishWord](m.Called(ctx, ids))
+}
+
+func (m *MockPolishWordRepository) GetPronunciationsByPolishWordIDs(ctx context.Context, ids []string) (map[string]*model.Pronunciation, error) {
+
+	return GetMockResult[map[string]*model.Pronunciation](m.Called(ctx, ids))
+}
+
+func (m *MockPolishWordRepository) GetLevelsByPolishWordIDs(ctx context.Context, ids []string) (map[string]*repository.Level, error) {
+
+	return GetMockResult[map[string]*repository.Level](m.Called(ctx, ids))
+}
+
+func (m *MockPolishWordRepository) GetAccessByPolishWordIDs(ctx context.Context, ids []string) (map[string]*repository.Access, error) {
+
+	return GetMockResult[map[string]*repository.Access](m.Called(ctx, ids))
+}
+
+func (m *MockPolishWordRepository) GetMyWords(ctx context.Context) ([]*model.PolishWord, error) {
+
+	return GetMockResult[[]*model.PolishWord](m.Called(ctx))
+}
+
+func (m *MockPolishWordRepository) GetPolishWordAsOf(ctx context.Context, id *string, word *string, version *int, asOf *time.Time) (*model.PolishWord, error) {
+
+	return GetMockResult[*model.PolishWord](m.Called(ctx, id, word, version, asOf))
+}
+
+func (m *MockPolishWordRepository) RevertPolishWord(ctx context.Context, id string, toVersion int) (*model.PolishWord, error) {
+
+	return GetMockResult[*model.PolishWord](m.Called(ctx, id, toVersion))
+}
+
+func (m *MockPolishWordRepository) GetRevisionsByPolishWordIDs(ctx context.Context, ids []string) (map[string][]*model.Revision, error) {
+
+	return GetMockResult[map[string][]*model.Revision](m.Called(ctx, ids))
+}
@@ -0,0 +1,27 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRelationRepository struct {
+	mock.Mock
+}
+
+func (m *MockRelationRepository) AddWordRelation(ctx context.Context, relation model.AddWordRelationInput) (*model.WordRelation, error) {
+
+	return GetMockResult[*model.WordRelation](m.Called(ctx, relation))
+}
+
+func (m *MockRelationRepository) RemoveWordRelation(ctx context.Context, id string) (*model.WordRelation, error) {
+
+	return GetMockResult[*model.WordRelation](m.Called(ctx, id))
+}
+
+func (m *MockRelationRepository) GetRelationsByPolishWordIDs(ctx context.Context, polishWordIDs []string) (map[string][]*model.WordRelation, error) {
+
+	return GetMockResult[map[string][]*model.WordRelation](m.Called(ctx, polishWordIDs))
+}
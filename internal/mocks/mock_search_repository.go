@@ -0,0 +1,17 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSearchRepository struct {
+	mock.Mock
+}
+
+func (m *MockSearchRepository) Search(ctx context.Context, query string, scope []model.SearchScope, limit *int) ([]*model.SearchHit, error) {
+
+	return GetMockResult[[]*model.SearchHit](m.Called(ctx, query, scope, limit))
+}
@@ -0,0 +1,37 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTagRepository struct {
+	mock.Mock
+}
+
+func (m *MockTagRepository) AddTags(ctx context.Context, target model.TagTargetInput, tags []string) ([]*model.Tag, error) {
+
+	return GetMockResult[[]*model.Tag](m.Called(ctx, target, tags))
+}
+
+func (m *MockTagRepository) RemoveTags(ctx context.Context, target model.TagTargetInput, tags []string) ([]*model.Tag, error) {
+
+	return GetMockResult[[]*model.Tag](m.Called(ctx, target, tags))
+}
+
+func (m *MockTagRepository) GetTagUsage(ctx context.Context) ([]*model.TagUsage, error) {
+
+	return GetMockResult[[]*model.TagUsage](m.Called(ctx))
+}
+
+func (m *MockTagRepository) GetTagsByPolishWordIDs(ctx context.Context, polishWordIDs []string) (map[string][]*model.Tag, error) {
+
+	return GetMockResult[map[string][]*model.Tag](m.Called(ctx, polishWordIDs))
+}
+
+func (m *MockTagRepository) GetTagsByTranslationIDs(ctx context.Context, translationIDs []string) (map[string][]*model.Tag, error) {
+
+	return GetMockResult[map[string][]*model.Tag](m.Called(ctx, translationIDs))
+}
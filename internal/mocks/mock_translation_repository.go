@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/repository"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -30,3 +31,28 @@ func (m *MockTranslationRepository) GetSingleTranslationByID(ctx context.Context
 
 	return GetMockResult[*model.Translation](m.Called(ctx, id))
 }
+
+func (m *MockTranslationRepository) GetTranslationsByEnglishWord(ctx context.Context, englishWord string, partOfSpeech *model.PartOfSpeech) ([]*model.Translation, error) {
+
+	return GetMockResult[[]*model.Translation](m.Called(ctx, englishWord, partOfSpeech))
+}
+
+func (m *MockTranslationRepository) GetTranslationsByIDs(ctx context.Context, ids []string) (map[string]*model.Translation, error) {
+
+	return GetMockResult[map[string]*model.Translation](m.Called(ctx, ids))
+}
+
+func (m *MockTranslationRepository) GetTranslationsByPolishWordIDs(ctx context.Context, polishWordIDs []string) (map[string][]*model.Translation, error) {
+
+	return GetMockResult[map[string][]*model.Translation](m.Called(ctx, polishWordIDs))
+}
+
+func (m *MockTranslationRepository) GetLevelsByTranslationIDs(ctx context.Context, ids []string) (map[string]*repository.Level, error) {
+
+	return GetMockResult[map[string]*repository.Level](m.Called(ctx, ids))
+}
+
+func (m *MockTranslationRepository) GetAccessByTranslationIDs(ctx context.Context, ids []string) (map[string]*repository.Access, error) {
+
+	return GetMockResult[map[string]*repository.Access](m.Called(ctx, ids))
+}
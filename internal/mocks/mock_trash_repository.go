@@ -0,0 +1,29 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/repository"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTrashRepository struct {
+	mock.Mock
+}
+
+func (m *MockTrashRepository) GetTrash(ctx context.Context) ([]*model.TrashEntry, error) {
+
+	return GetMockResult[[]*model.TrashEntry](m.Called(ctx))
+}
+
+func (m *MockTrashRepository) Restore(ctx context.Context, id string) (model.DictionaryEntry, error) {
+
+	return GetMockResult[model.DictionaryEntry](m.Called(ctx, id))
+}
+
+func (m *MockTrashRepository) Purge(ctx context.Context, deletedBefore time.Time) (*repository.PurgeReport, error) {
+
+	return GetMockResult[*repository.PurgeReport](m.Called(ctx, deletedBefore))
+}
@@ -0,0 +1,278 @@
+package pronunciation
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Result is the pronunciation of a single word.
+type Result struct {
+	// IPA is a broad transcription with syllables separated by dots and the
+	// stressed syllable marked, e.g. ˈza.mɛk.
+	IPA       string
+	Syllables []string
+	// StressedSyllable is 1-based.
+	StressedSyllable int
+}
+
+// Generator derives a pronunciation from the spelling of a word.
+type Generator interface {
+	// Generate returns false when it cannot transcribe word.
+	Generate(word string) (Result, bool)
+}
+
+// Rules transcribes standard Polish spelling with a fixed set of rules:
+// digraphs, softening by i, nasal vowels, voicing assimilation and final
+// devoicing. Stress falls on the penultimate syllable, which is wrong for a
+// small number of loanwords, so generated values can be corrected by hand.
+type Rules struct{}
+
+type unit struct {
+	start, end int
+	sound      string
+	vowel      bool
+	// transparent marks w and rz, which are devoiced after a voiceless
+	// consonant but do not voice the consonant before them.
+	transparent bool
+}
+
+var digraphs = map[string]string{
+	"ch": "x", "cz": "tʂ", "dz": "dz", "dż": "dʐ", "dź": "dʑ", "rz": "ʐ", "sz": "ʂ",
+}
+
+var letters = map[rune]string{
+	'a': "a", 'ą': "ɔ̃", 'b': "b", 'c': "ts", 'ć': "tɕ", 'd': "d", 'e': "ɛ", 'ę': "ɛ̃",
+	'f': "f", 'g': "ɡ", 'h': "x", 'i': "i", 'j': "j", 'k': "k", 'l': "l", 'ł': "w",
+	'm': "m", 'n': "n", 'ń': "ɲ", 'o': "ɔ", 'ó': "u", 'p': "p", 'r': "r", 's': "s",
+	'ś': "ɕ", 't': "t", 'u': "u", 'w': "v", 'y': "ɨ", 'z': "z", 'ź': "ʑ", 'ż': "ʐ",
+}
+
+// softened consonants merge with a following i; palatalized ones keep their
+// place of articulation.
+var softened = map[string]string{"c": "tɕ", "s": "ɕ", "z": "ʑ", "n": "ɲ", "dz": "dʑ"}
+
+var palatalized = map[string]string{
+	"p": "pʲ", "b": "bʲ", "m": "mʲ", "f": "fʲ", "w": "vʲ", "k": "kʲ", "g": "ɡʲ", "h": "xʲ", "ch": "xʲ",
+}
+
+var devoiced = map[string]string{
+	"b": "p", "d": "t", "ɡ": "k", "v": "f", "z": "s", "ʐ": "ʂ", "ʑ": "ɕ",
+	"dz": "ts", "dʐ": "tʂ", "dʑ": "tɕ", "bʲ": "pʲ", "vʲ": "fʲ", "ɡʲ": "kʲ",
+}
+
+var voiced = map[string]string{}
+
+var voiceless = map[string]bool{"x": true, "xʲ": true}
+
+func init() {
+	for v, vl := range devoiced {
+		voiced[vl] = v
+		voiceless[vl] = true
+	}
+}
+
+func isVowelLetter(r rune) bool {
+	return strings.ContainsRune("aąeęioóuy", r)
+}
+
+func (Rules) Generate(word string) (Result, bool) {
+	original := []rune(word)
+	lower := []rune(strings.ToLower(word))
+	if len(lower) == 0 || len(lower) != len(original) {
+		return Result{}, false
+	}
+
+	units, ok := tokenize(lower)
+	if !ok {
+		return Result{}, false
+	}
+
+	applyNasalRules(units, lower)
+	applyVoicing(units)
+
+	syllables := syllabify(units)
+	if syllables == nil {
+		return Result{}, false
+	}
+
+	stressed := 1
+	if len(syllables) > 1 {
+		stressed = len(syllables) - 1
+	}
+
+	result := Result{StressedSyllable: stressed}
+	var ipa []string
+	for i, syllable := range syllables {
+		var sound strings.Builder
+		if len(syllables) > 1 && i+1 == stressed {
+			sound.WriteString("ˈ")
+		}
+		for _, u := range syllable {
+			sound.WriteString(u.sound)
+		}
+		ipa = append(ipa, sound.String())
+		result.Syllables = append(result.Syllables, string(original[syllable[0].start:syllable[len(syllable)-1].end]))
+	}
+	result.IPA = strings.Join(ipa, ".")
+
+	return result, true
+}
+
+func tokenize(word []rune) ([]unit, bool) {
+	var units []unit
+
+	for i := 0; i < len(word); {
+		if !unicode.IsLetter(word[i]) {
+			return nil, false
+		}
+
+		spelling := string(word[i])
+		if i+1 < len(word) {
+			if _, ok := digraphs[string(word[i:i+2])]; ok {
+				spelling = string(word[i : i+2])
+			}
+		}
+
+		u := unit{start: i, vowel: isVowelLetter(word[i])}
+		if sound, ok := digraphs[spelling]; ok {
+			u.sound = sound
+		} else if sound, ok := letters[word[i]]; ok {
+			u.sound = sound
+		} else {
+			return nil, false
+		}
+		u.transparent = spelling == "w" || spelling == "rz"
+
+		next := i + len([]rune(spelling))
+		if !u.vowel && next < len(word) && word[next] == 'i' {
+			beforeVowel := next+1 < len(word) && isVowelLetter(word[next+1])
+
+			if sound, ok := softened[spelling]; ok {
+				u.sound = sound
+			} else if sound, ok := palatalized[spelling]; ok {
+				u.sound = sound
+			}
+
+			// The i only softens the consonant when a vowel follows it.
+			if beforeVowel && (softened[spelling] != "" || palatalized[spelling] != "") {
+				next++
+			}
+		}
+
+		u.end = next
+		units = append(units, u)
+		i = next
+	}
+
+	return units, true
+}
+
+// applyNasalRules splits ą and ę before stops and affricates into a vowel and
+// a nasal consonant of the same place, drops nasality before l and ł and at
+// the end of a word for ę.
+func applyNasalRules(units []unit, word []rune) {
+	for i := range units {
+		letter := word[units[i].start]
+		if letter != 'ą' && letter != 'ę' {
+			continue
+		}
+
+		oral := "ɔ"
+		if letter == 'ę' {
+			oral = "ɛ"
+		}
+
+		if i+1 == len(units) {
+			if letter == 'ę' {
+				units[i].sound = oral
+			}
+			continue
+		}
+
+		next := units[i+1].sound
+		switch {
+		case strings.HasPrefix(next, "p") || strings.HasPrefix(next, "b"):
+			units[i].sound = oral + "m"
+		case next == "tɕ" || next == "dʑ":
+			units[i].sound = oral + "ɲ"
+		case strings.HasPrefix(next, "t") || strings.HasPrefix(next, "d"):
+			units[i].sound = oral + "n"
+		case strings.HasPrefix(next, "k") || strings.HasPrefix(next, "ɡ"):
+			units[i].sound = oral + "ŋ"
+		case next == "l" || next == "w":
+			units[i].sound = oral
+		}
+	}
+}
+
+type voicing int
+
+const (
+	voicingNone voicing = iota
+	voicingVoiced
+	voicingVoiceless
+)
+
+// applyVoicing makes every cluster of obstruents agree with its last member,
+// devoices obstruents at the end of the word and devoices w and rz after a
+// voiceless consonant.
+func applyVoicing(units []unit) {
+	following := voicingVoiceless
+
+	for i := len(units) - 1; i >= 0; i-- {
+		u := &units[i]
+
+		switch {
+		case devoiced[u.sound] != "":
+			if following == voicingVoiceless {
+				u.sound = devoiced[u.sound]
+			} else if u.transparent {
+				following = voicingNone
+			} else {
+				following = voicingVoiced
+			}
+		case voiceless[u.sound]:
+			if sound, ok := voiced[u.sound]; ok && following == voicingVoiced {
+				u.sound = sound
+			} else {
+				following = voicingVoiceless
+			}
+		default:
+			following = voicingNone
+		}
+	}
+
+	for i := 1; i < len(units); i++ {
+		if units[i].transparent && devoiced[units[i].sound] != "" && voiceless[units[i-1].sound] {
+			units[i].sound = devoiced[units[i].sound]
+		}
+	}
+}
+
+// syllabify gives every vowel its own syllable. A single consonant between
+// two vowels starts the next syllable; in longer clusters the first consonant
+// closes the previous one.
+func syllabify(units []unit) [][]unit {
+	var nuclei []int
+	for i, u := range units {
+		if u.vowel {
+			nuclei = append(nuclei, i)
+		}
+	}
+	if len(nuclei) == 0 {
+		return nil
+	}
+
+	var syllables [][]unit
+	start := 0
+	for n := 0; n+1 < len(nuclei); n++ {
+		boundary := nuclei[n] + 1
+		if nuclei[n+1]-nuclei[n]-1 > 1 {
+			boundary++
+		}
+		syllables = append(syllables, units[start:boundary])
+		start = boundary
+	}
+
+	return append(syllables, units[start:])
+}
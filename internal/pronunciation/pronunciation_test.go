@@ -0,0 +1,50 @@
+package pronunciation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRulesGenerate(t *testing.T) {
+
+	tests := []struct {
+		word      string
+		ipa       string
+		syllables []string
+		stressed  int
+	}{
+		{"zamek", "ˈza.mɛk", []string{"za", "mek"}, 1},
+		{"pies", "pʲɛs", []string{"pies"}, 1},
+		{"chleb", "xlɛp", []string{"chleb"}, 1},
+		{"dziecko", "ˈdʑɛts.kɔ", []string{"dziec", "ko"}, 1},
+		{"wszystko", "ˈfʂɨs.tkɔ", []string{"wszys", "tko"}, 1},
+		{"kwiat", "kfʲat", []string{"kwiat"}, 1},
+		{"wódka", "ˈvut.ka", []string{"wód", "ka"}, 1},
+		{"prośba", "ˈprɔʑ.ba", []string{"proś", "ba"}, 1},
+		{"ręka", "ˈrɛŋ.ka", []string{"rę", "ka"}, 1},
+		{"się", "ɕɛ", []string{"się"}, 1},
+		{"zamknąć", "ˈzam.knɔɲtɕ", []string{"zam", "knąć"}, 1},
+		{"przyjaciel", "pʂɨ.ˈja.tɕɛl", []string{"przy", "ja", "ciel"}, 2},
+		{"Kraków", "ˈkra.kuf", []string{"Kra", "ków"}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			result, ok := Rules{}.Generate(tt.word)
+
+			assert.True(t, ok)
+			assert.Equal(t, tt.ipa, result.IPA)
+			assert.Equal(t, tt.syllables, result.Syllables)
+			assert.Equal(t, tt.stressed, result.StressedSyllable)
+		})
+	}
+}
+
+func TestRulesGenerateRejectsNonWords(t *testing.T) {
+
+	for _, word := range []string{"", "w", "dom kultury", "x-ray", "quiz"} {
+		_, ok := Rules{}.Generate(word)
+		assert.False(t, ok, word)
+	}
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/auth"
+	"github.com/lib/pq"
+)
+
+// roleArray scans a TEXT[] of roles.
+type roleArray []auth.Role
+
+func (a *roleArray) Scan(src any) error {
+	var roles pq.StringArray
+	if err := roles.Scan(src); err != nil {
+		return err
+	}
+
+	*a = make(roleArray, len(roles))
+	for i, role := range roles {
+		(*a)[i] = auth.Role(role)
+	}
+	return nil
+}
+
+func apiKeyFields(k *APIKey) []any {
+	return []any{&k.ID, &k.Name, &k.Prefix, (*roleArray)(&k.Roles), &k.CreatedAt, &k.LastUsedAt, &k.RevokedAt}
+}
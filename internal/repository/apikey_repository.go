@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/auth"
+	"github.com/lib/pq"
+)
+
+const apiKeyColumns = "id, name, prefix, roles, created_at, last_used_at, revoked_at"
+
+type APIKeyRepositoryDB struct {
+	DB *sql.DB
+}
+
+// CreateAPIKey stores the hash of key for the caller name.
+func (ar *APIKeyRepositoryDB) CreateAPIKey(ctx context.Context, name string, key string, roles []auth.Role) (*APIKey, error) {
+	if name == "" {
+		return nil, validationError("name", "name must not be empty")
+	}
+	if len(key) < auth.APIKeyPrefixLength {
+		return nil, validationError("key", "key is too short")
+	}
+	for _, role := range roles {
+		if !role.IsValid() {
+			return nil, validationError("roles", "unknown role "+string(role))
+		}
+	}
+
+	var apiKey APIKey
+	err := conn(ctx, ar.DB).QueryRowContext(ctx, `
+		INSERT INTO api_keys (name, prefix, key_hash, roles)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+apiKeyColumns,
+		name, key[:auth.APIKeyPrefixLength], auth.HashAPIKey(key), pq.Array(roles)).
+		Scan(apiKeyFields(&apiKey)...)
+	if err != nil {
+		return nil, dbError(err)
+	}
+
+	return &apiKey, nil
+}
+
+func (ar *APIKeyRepositoryDB) GetAPIKeys(ctx context.Context) ([]*APIKey, error) {
+	rows, err := conn(ctx, ar.DB).QueryContext(ctx, "SELECT "+apiKeyColumns+" FROM api_keys ORDER BY name, id")
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	apiKeys := []*APIKey{}
+	for rows.Next() {
+		var apiKey APIKey
+		if err := rows.Scan(apiKeyFields(&apiKey)...); err != nil {
+			return nil, dbError(err)
+		}
+		apiKeys = append(apiKeys, &apiKey)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, dbError(err)
+	}
+
+	return apiKeys, nil
+}
+
+// RevokeAPIKey stops the live key of name from being accepted.
+func (ar *APIKeyRepositoryDB) RevokeAPIKey(ctx context.Context, name string) (*APIKey, error) {
+	var apiKey APIKey
+	err := conn(ctx, ar.DB).QueryRowContext(ctx,
+		"UPDATE api_keys SET revoked_at = now() WHERE name = $1 AND revoked_at IS NULL RETURNING "+apiKeyColumns, name).
+		Scan(apiKeyFields(&apiKey)...)
+	if err != nil {
+		return nil, notFoundOr(err, "api key", "name", name)
+	}
+
+	return &apiKey, nil
+}
+
+// PrincipalForAPIKey also records when the key was last used.
+func (ar *APIKeyRepositoryDB) PrincipalForAPIKey(ctx context.Context, keyHash string) (*auth.Principal, error) {
+	var principal auth.Principal
+	err := conn(ctx, ar.DB).QueryRowContext(ctx,
+		"UPDATE api_keys SET last_used_at = now() WHERE key_hash = $1 AND revoked_at IS NULL RETURNING name, roles", keyHash).
+		Scan(&principal.Name, (*roleArray)(&principal.Roles))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, dbError(err)
+	}
+
+	return &principal, nil
+}
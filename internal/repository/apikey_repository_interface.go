@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/auth"
+)
+
+// APIKey describes an issued key. The key itself is never stored.
+type APIKey struct {
+	ID         string
+	Name       string
+	Prefix     string
+	Roles      []auth.Role
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+type APIKeyRepositoryInterface interface {
+	auth.APIKeyStore
+	CreateAPIKey(ctx context.Context, name string, key string, roles []auth.Role) (*APIKey, error)
+	GetAPIKeys(ctx context.Context) ([]*APIKey, error)
+	RevokeAPIKey(ctx context.Context, name string) (*APIKey, error)
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/lib/pq"
+)
+
+// attach links audio to the entry whose id is stored in ownerColumn and
+// returns the blob key of the recording it replaces. The caller must hold a
+// lock on the entry.
+func (ar *AudioRepositoryDB) attach(ctx context.Context, ownerColumn string, ownerID string, audio *model.Audio) (string, error) {
+	var replaced string
+
+	err := conn(ctx, ar.DB).QueryRowContext(ctx,
+		"SELECT blob_key FROM audio_recordings WHERE "+ownerColumn+" = $1", ownerID).Scan(&replaced)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", dbError(err)
+	}
+
+	_, err = conn(ctx, ar.DB).ExecContext(ctx,
+		"INSERT INTO audio_recordings ("+ownerColumn+", blob_key, content_type, size) VALUES ($1, $2, $3, $4) "+
+			"ON CONFLICT ("+ownerColumn+") DO UPDATE SET blob_key = EXCLUDED.blob_key, content_type = EXCLUDED.content_type, size = EXCLUDED.size, created_at = now()",
+		ownerID, audio.Key, audio.ContentType, audio.Size)
+	if err != nil {
+		return "", dbError(err)
+	}
+
+	return replaced, nil
+}
+
+func getAudioByOwnerIDs(ctx context.Context, db DBTX, ownerColumn string, ownerIDs []string) (map[string]*model.Audio, error) {
+	rows, err := db.QueryContext(ctx,
+		"SELECT "+ownerColumn+", blob_key, content_type, size FROM audio_recordings WHERE "+ownerColumn+" = ANY($1::int[])",
+		pq.Array(ownerIDs))
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	recordings := make(map[string]*model.Audio, len(ownerIDs))
+	for rows.Next() {
+		var ownerID string
+		var audio model.Audio
+
+		if err := rows.Scan(&ownerID, &audio.Key, &audio.ContentType, &audio.Size); err != nil {
+			return nil, dbError(err)
+		}
+
+		recordings[ownerID] = &audio
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, dbError(err)
+	}
+
+	return recordings, nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+)
+
+type AudioRepositoryDB struct {
+	DB *sql.DB
+}
+
+func (ar *AudioRepositoryDB) AttachPolishWordAudio(ctx context.Context, polishWordID *string, polishWord *string, audio *model.Audio) (*model.PolishWord, string, error) {
+	var replaced string
+
+	pw, err := inTx(ctx, ar.DB, func(ctx context.Context) (*model.PolishWord, error) {
+		targetPolishWordID, err := getTargetPolishWordID(ctx, conn(ctx, ar.DB), polishWordID, polishWord)
+		if err != nil {
+			return nil, err
+		}
+
+		pw := &model.PolishWord{ID: *targetPolishWordID, Audio: audio}
+
+		// Locking the word serializes concurrent attaches, so every replaced
+		// recording is reported exactly once.
+		err = conn(ctx, ar.DB).QueryRowContext(ctx, "SELECT word, version FROM lexemes WHERE id = $1 AND "+notDeleted+" AND "+visibleTo(ctx, "")+" FOR UPDATE",
+			pw.ID).Scan(&pw.Word, &pw.Version)
+		if err != nil {
+			return nil, notFoundOr(err, "polish word", "id", pw.ID)
+		}
+
+		replaced, err = ar.attach(ctx, "polish_word_id", pw.ID, audio)
+		if err != nil {
+			return nil, err
+		}
+
+		return pw, nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return pw, replaced, nil
+}
+
+func (ar *AudioRepositoryDB) AttachExampleSentenceAudio(ctx context.Context, exampleSentenceID string, audio *model.Audio) (*model.ExampleSentence, string, error) {
+	var replaced string
+
+	es, err := inTx(ctx, ar.DB, func(ctx context.Context) (*model.ExampleSentence, error) {
+		es := &model.ExampleSentence{ID: exampleSentenceID, Audio: audio}
+
+		err := conn(ctx, ar.DB).QueryRowContext(ctx,
+			"SELECT sentence_pl, sentence_en, translation_id, version FROM example_sentences WHERE id = $1 AND "+notDeleted+" AND "+visibleTo(ctx, "")+" FOR UPDATE",
+			exampleSentenceID).Scan(&es.SentencePl, &es.SentenceEn, &es.TranslationID, &es.Version)
+		if err != nil {
+			return nil, notFoundOr(err, "example sentence", "id", exampleSentenceID)
+		}
+
+		replaced, err = ar.attach(ctx, "example_sentence_id", exampleSentenceID, audio)
+		if err != nil {
+			return nil, err
+		}
+
+		return es, nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return es, replaced, nil
+}
+
+func (ar *AudioRepositoryDB) GetAudioByPolishWordIDs(ctx context.Context, polishWordIDs []string) (map[string]*model.Audio, error) {
+	return getAudioByOwnerIDs(ctx, conn(ctx, ar.DB), "polish_word_id", polishWordIDs)
+}
+
+func (ar *AudioRepositoryDB) GetAudioByExampleSentenceIDs(ctx context.Context, exampleSentenceIDs []string) (map[string]*model.Audio, error) {
+	return getAudioByOwnerIDs(ctx, conn(ctx, ar.DB), "example_sentence_id", exampleSentenceIDs)
+}
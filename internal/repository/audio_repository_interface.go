@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+)
+
+// AudioRepositoryInterface links recordings in the blob store to dictionary
+// entries. The attach methods also return the blob key of the recording that
+// was replaced, or "" when there was none, so it can be removed from the store.
+type AudioRepositoryInterface interface {
+	AttachPolishWordAudio(ctx context.Context, polishWordID *string, polishWord *string, audio *model.Audio) (*model.PolishWord, string, error)
+	AttachExampleSentenceAudio(ctx context.Context, exampleSentenceID string, audio *model.Audio) (*model.ExampleSentence, string, error)
+	GetAudioByPolishWordIDs(ctx context.Context, polishWordIDs []string) (map[string]*model.Audio, error)
+	GetAudioByExampleSentenceIDs(ctx context.Context, exampleSentenceIDs []string) (map[string]*model.Audio, error)
+}
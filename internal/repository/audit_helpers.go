@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/lib/pq"
+)
+
+const auditLogColumns = "id, occurred_at, operation, operation_name, arguments, affected_ids, before, after, actor, client_ip, request_id, error"
+
+// historyTables lists the tables written by the history triggers with the
+// entity prefix of their keys in Changes.
+var historyTables = []struct {
+	entity string
+	table  string
+}{
+	{"polish_word", "polish_word_history"},
+	{"translation", "translation_history"},
+	{"example_sentence", "example_sentence_history"},
+}
+
+func auditEntryFields(e *model.AuditEntry) []any {
+	return []any{&e.ID, &e.OccurredAt, &e.Operation, &e.OperationName, &e.Arguments, (*pq.StringArray)(&e.AffectedIds),
+		&e.Before, &e.After, &e.Actor, &e.ClientIP, &e.RequestID, &e.Error}
+}
+
+func scanChanges(rows *sql.Rows, entity string, changes *Changes) error {
+	for rows.Next() {
+		var id int
+		var before, after []byte
+		if err := rows.Scan(&id, &before, &after); err != nil {
+			return err
+		}
+
+		key := entity + ":" + strconv.Itoa(id)
+		if before != nil {
+			changes.Before[key] = before
+		}
+		changes.After[key] = after
+	}
+	return rows.Err()
+}
+
+// scanEntryChanges reads the first and last state of entries without a
+// history. An entry the mutation deleted is kept with a null After, so it is
+// still listed among the affected ids.
+func scanEntryChanges(rows *sql.Rows, changes *Changes) error {
+	for rows.Next() {
+		var entity, id string
+		var before, after []byte
+		if err := rows.Scan(&entity, &id, &before, &after); err != nil {
+			return err
+		}
+
+		key := entity + ":" + id
+		if before != nil {
+			changes.Before[key] = before
+		}
+		changes.After[key] = after
+	}
+	return rows.Err()
+}
+
+// nonNilSnapshots makes a missing map encode as an empty object.
+func nonNilSnapshots(snapshots map[string]json.RawMessage) map[string]json.RawMessage {
+	if snapshots == nil {
+		return map[string]json.RawMessage{}
+	}
+	return snapshots
+}
+
+// auditLogFilterConditions turns filter into conditions on audit_log,
+// numbering placeholders from $1.
+func auditLogFilterConditions(filter *model.AuditLogFilter) ([]string, []any) {
+	var conditions []string
+	var args []any
+
+	if filter == nil {
+		return conditions, args
+	}
+
+	add := func(format string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	if filter.Actor != nil {
+		add("actor = $%d", *filter.Actor)
+	}
+	if filter.Operation != nil {
+		add("operation = $%d", *filter.Operation)
+	}
+	if filter.AffectedID != nil {
+		add("affected_ids @> ARRAY[$%d::text]", *filter.AffectedID)
+	}
+	if filter.Since != nil {
+		add("occurred_at >= $%d", *filter.Since)
+	}
+	if filter.Until != nil {
+		add("occurred_at < $%d", *filter.Until)
+	}
+
+	return conditions, args
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/lib/pq"
+)
+
+const auditLogCursorPrefix = "auditlog:"
+
+type AuditRepositoryDB struct {
+	DB *sql.DB
+}
+
+func (ar *AuditRepositoryDB) RecordAudit(ctx context.Context, record *AuditRecord) error {
+	changes := record.Changes
+	if changes == nil {
+		changes = &Changes{}
+	}
+
+	before, err := json.Marshal(nonNilSnapshots(changes.Before))
+	if err != nil {
+		return err
+	}
+	after, err := json.Marshal(nonNilSnapshots(changes.After))
+	if err != nil {
+		return err
+	}
+
+	affectedIDs := record.AffectedIDs
+	if affectedIDs == nil {
+		affectedIDs = []string{}
+	}
+
+	_, err = conn(ctx, ar.DB).ExecContext(ctx, `
+		INSERT INTO audit_log (operation, operation_name, arguments, affected_ids, before, after, actor, client_ip, request_id, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		record.Operation, record.OperationName, []byte(record.Arguments), pq.Array(affectedIDs),
+		before, after, record.Actor, record.ClientIP, record.RequestID, record.Error)
+	return dbError(err)
+}
+
+// GetChanges collects the revisions tagged with changeID, with the revision
+// each entry had before them, and the changes recorded for entries without a
+// history. Those are only kept until they are read here.
+func (ar *AuditRepositoryDB) GetChanges(ctx context.Context, changeID string) (*Changes, error) {
+	changes := &Changes{Before: map[string]json.RawMessage{}, After: map[string]json.RawMessage{}}
+
+	for _, history := range historyTables {
+		rows, err := conn(ctx, ar.DB).QueryContext(ctx, fmt.Sprintf(`
+			SELECT c.entity_id,
+				(SELECT b.snapshot FROM %[1]s b WHERE b.entity_id = c.entity_id AND b.id < c.first_id ORDER BY b.id DESC LIMIT 1),
+				(SELECT a.snapshot FROM %[1]s a WHERE a.id = c.last_id)
+			FROM (
+				SELECT entity_id, MIN(id) AS first_id, MAX(id) AS last_id
+				FROM %[1]s
+				WHERE change_id = $1
+				GROUP BY entity_id
+			) c
+			ORDER BY c.entity_id`, history.table), changeID)
+		if err != nil {
+			return nil, dbError(err)
+		}
+
+		err = scanChanges(rows, history.entity, changes)
+		rows.Close()
+		if err != nil {
+			return nil, dbError(err)
+		}
+	}
+
+	rows, err := conn(ctx, ar.DB).QueryContext(ctx, `
+		WITH consumed AS (
+			DELETE FROM entry_changes WHERE change_id = $1
+			RETURNING id, entity, entity_id, before, after
+		)
+		SELECT entity, entity_id, (array_agg(before ORDER BY id))[1], (array_agg(after ORDER BY id DESC))[1]
+		FROM consumed
+		GROUP BY entity, entity_id
+		ORDER BY entity, entity_id`, changeID)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	if err := scanEntryChanges(rows, changes); err != nil {
+		return nil, dbError(err)
+	}
+
+	return changes, nil
+}
+
+func (ar *AuditRepositoryDB) GetAuditLog(ctx context.Context, filter *model.AuditLogFilter, first *int, after *string) (*model.AuditLogConnection, error) {
+	size := defaultPageSize
+	if first != nil {
+		if *first < 0 {
+			return nil, validationError("first", "first must not be negative")
+		}
+		size = min(*first, maxPageSize)
+	}
+
+	conditions, args := auditLogFilterConditions(filter)
+	if after != nil {
+		afterID, err := decodeCursor(auditLogCursorPrefix, *after)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, afterID)
+		conditions = append(conditions, fmt.Sprintf("id < $%d", len(args)))
+	}
+
+	query := "SELECT " + auditLogColumns + " FROM audit_log"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, size+1)
+	query += fmt.Sprintf(" ORDER BY id DESC LIMIT $%d", len(args))
+
+	rows, err := conn(ctx, ar.DB).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	connection := &model.AuditLogConnection{Edges: []*model.AuditEntryEdge{}, PageInfo: &model.PageInfo{}}
+	for rows.Next() {
+		var entry model.AuditEntry
+		if err := rows.Scan(auditEntryFields(&entry)...); err != nil {
+			return nil, dbError(err)
+		}
+		connection.Edges = append(connection.Edges, &model.AuditEntryEdge{
+			Cursor: encodeCursor(auditLogCursorPrefix, entry.ID),
+			Node:   &entry,
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, dbError(err)
+	}
+
+	if len(connection.Edges) > size {
+		connection.Edges = connection.Edges[:size]
+		connection.PageInfo.HasNextPage = true
+	}
+	if len(connection.Edges) > 0 {
+		connection.PageInfo.StartCursor = &connection.Edges[0].Cursor
+		connection.PageInfo.EndCursor = &connection.Edges[len(connection.Edges)-1].Cursor
+	}
+
+	return connection, nil
+}
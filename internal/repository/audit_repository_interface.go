@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+)
+
+// AuditRecord is an audit log entry about to be written.
+type AuditRecord struct {
+	Operation     string
+	OperationName *string
+	Arguments     json.RawMessage
+	AffectedIDs   []string
+	Changes       *Changes
+	Actor         *string
+	ClientIP      *string
+	RequestID     string
+	Error         *string
+}
+
+// Changes holds the entries a mutation wrote, keyed like polish_word:12 or
+// polish_word_tag:12:3. Entries it created have no Before, and entries it
+// deleted a null After.
+type Changes struct {
+	Before map[string]json.RawMessage
+	After  map[string]json.RawMessage
+}
+
+type AuditRepositoryInterface interface {
+	RecordAudit(ctx context.Context, record *AuditRecord) error
+	GetChanges(ctx context.Context, changeID string) (*Changes, error)
+	GetAuditLog(ctx context.Context, filter *model.AuditLogFilter, first *int, after *string) (*model.AuditLogConnection, error)
+}
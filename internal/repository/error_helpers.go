@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/apperror"
+	"github.com/lib/pq"
+)
+
+const (
+	pqUniqueViolation           = "23505"
+	pqForeignKeyViolation       = "23503"
+	pqNotNullViolation          = "23502"
+	pqStringDataTruncation      = "22001"
+	pqInvalidTextRepresentation = "22P02"
+	pqCheckViolation            = "23514"
+)
+
+var uniqueConstraintEntities = map[string]string{
+	"uq_lexeme_language_word":             "lexeme",
+	"uq_translation_pwid_language_text":   "translation",
+	"uq_example_sentence_tid_senpl_senen": "example sentence",
+	"uq_inflection_form":                  "inflection",
+	"uq_api_key_name":                     "api key",
+}
+
+const (
+	visibilityWithinParentMessage = "an entry may not be seen by more callers than the entry it belongs to"
+	privateWithoutOwnerMessage    = "an entry without an owner cannot be private"
+)
+
+// checkConstraintErrors explains the check constraints a client can run into.
+// Other check violations are bugs and reported as internal errors.
+var checkConstraintErrors = map[string]apperror.ValidationError{
+	"chk_visibility_within_parent":       {Field: "visibility", Message: visibilityWithinParentMessage},
+	"chk_polish_word_private_owner":      {Field: "visibility", Message: privateWithoutOwnerMessage},
+	"chk_translation_private_owner":      {Field: "visibility", Message: privateWithoutOwnerMessage},
+	"chk_example_sentence_private_owner": {Field: "visibility", Message: privateWithoutOwnerMessage},
+}
+
+// dbError turns driver errors into domain errors. Errors that are already
+// domain errors pass through untouched, anything unrecognised becomes internal.
+func dbError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var domainErr apperror.Error
+	if errors.As(err, &domainErr) {
+		return err
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return &apperror.NotFoundError{Entity: "entity"}
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case pqUniqueViolation:
+			entity, ok := uniqueConstraintEntities[pqErr.Constraint]
+			if !ok {
+				entity = "entry"
+			}
+			return &apperror.DuplicateError{Entity: entity, Constraint: pqErr.Constraint}
+		case pqForeignKeyViolation:
+			return &apperror.ValidationError{Message: "referenced entry does not exist"}
+		case pqNotNullViolation:
+			return &apperror.ValidationError{Field: pqErr.Column, Message: "a required value is missing"}
+		case pqStringDataTruncation:
+			return &apperror.ValidationError{Message: "value is too long"}
+		case pqInvalidTextRepresentation:
+			return &apperror.ValidationError{Message: "invalid identifier or value"}
+		case pqCheckViolation:
+			if validationErr, ok := checkConstraintErrors[pqErr.Constraint]; ok {
+				return &validationErr
+			}
+		}
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	return &apperror.InternalError{Err: err}
+}
+
+// notFoundOr reports a missing row as a NotFoundError for the given lookup and
+// translates every other error with dbError.
+func notFoundOr(err error, entity string, field string, value string) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return &apperror.NotFoundError{Entity: entity, Field: field, Value: value}
+	}
+	return dbError(err)
+}
+
+func validationError(field string, message string) error {
+	return &apperror.ValidationError{Field: field, Message: message}
+}
+
+// versionConflict reports a failed optimistic update. It looks up the version
+// currently stored so the client can refetch and retry.
+func versionConflict(ctx context.Context, db DBTX, table string, entity string, id string, expectedVersion int) error {
+	var currentVersion int
+	err := db.QueryRowContext(ctx, "SELECT version FROM "+table+" WHERE id = $1", id).Scan(&currentVersion)
+	if err != nil {
+		return notFoundOr(err, entity, "id", id)
+	}
+
+	return &apperror.VersionConflictError{
+		Entity:          entity,
+		ID:              id,
+		ExpectedVersion: expectedVersion,
+		CurrentVersion:  currentVersion,
+	}
+}
+
+// missingReferenceOr reports a foreign key violation as the referenced entity
+// not being found and translates every other error with dbError.
+func missingReferenceOr(err error, entity string, field string, value string) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == pqForeignKeyViolation {
+		return &apperror.NotFoundError{Entity: entity, Field: field, Value: value}
+	}
+	return dbError(err)
+}
@@ -7,23 +7,28 @@ import (
 	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
 )
 
-func (esr *ExampleSentenceRepositoryDB) insertExampleSentence(ctx context.Context, translationID, sentencePl, sentenceEn string) (string, int, error) {
+// The DO UPDATE is a no-op that makes RETURNING yield the existing row.
+const exampleSentenceUpsertConflict = "ON CONFLICT (tenant, translation_id, sentence_pl, sentence_en) WHERE deleted_at IS NULL DO UPDATE SET translation_id = EXCLUDED.translation_id"
+
+// insertExampleSentence makes a new sentence as visible as its translation
+// unless visibility says otherwise.
+func (esr *ExampleSentenceRepositoryDB) insertExampleSentence(ctx context.Context, translationID, sentencePl, sentenceEn string, visibility *model.Visibility) (string, int, error) {
 	var id string
 	var version int
 
-	err := esr.DB.QueryRowContext(ctx,
+	err := conn(ctx, esr.DB).QueryRowContext(ctx,
 		`
 		
-			INSERT INTO example_sentences (sentence_pl, sentence_en, translation_id)
-			VALUES($1, $2, $3)
-			ON CONFLICT (translation_id, sentence_pl, sentence_en) DO UPDATE SET translation_id = EXCLUDED.translation_id
+			INSERT INTO example_sentences (sentence_pl, sentence_en, translation_id, visibility)
+			VALUES($1, $2, $3, COALESCE($4, (SELECT visibility FROM translations WHERE id = $3)))
+			`+exampleSentenceUpsertConflict+`
 			RETURNING id, version
 		
 		`,
-		sentencePl, sentenceEn, translationID,
+		sentencePl, sentenceEn, translationID, visibilityValue(visibility),
 	).Scan(&id, &version)
 	if err != nil {
-		return "", -1, fmt.Errorf("failed to upsert example sentence: %w", err)
+		return "", -1, missingReferenceOr(fmt.Errorf("failed to upsert example sentence: %w", err), "translation", "id", translationID)
 	}
 	return id, version, nil
 }
@@ -32,16 +37,18 @@ func (esr *ExampleSentenceRepositoryDB) fetchTranslationAndPolishWord(ctx contex
 	var translation model.Translation
 	var polishWord model.PolishWord
 
-	err := esr.DB.QueryRowContext(ctx, `
-		SELECT t.id, t.english_word, t.version, p.id, p.word, p.version
+	err := conn(ctx, esr.DB).QueryRowContext(ctx, `
+		SELECT t.id, t.english_word, t.language, t.version, `+qualifiedTranslationGrammarColumns+`, p.id, p.word, p.version
 		FROM translations t
-		JOIN polish_words p ON t.polish_word_id = p.id
-		WHERE t.id = $1`, translationID,
-	).Scan(&translation.ID, &translation.EnglishWord, &translation.Version, &polishWord.ID, &polishWord.Word, &polishWord.Version)
+		JOIN lexemes p ON t.polish_word_id = p.id
+		WHERE t.id = $1 AND t.deleted_at IS NULL AND `+visibleTo(ctx, "t"), translationID,
+	).Scan(append(append([]any{&translation.ID, &translation.EnglishWord, &translation.Language, &translation.Version}, translationGrammarFields(&translation)...),
+		&polishWord.ID, &polishWord.Word, &polishWord.Version)...)
 	if err != nil {
-		return nil, err
+		return nil, notFoundOr(err, "translation", "id", translationID)
 	}
 
 	translation.PolishWord = &polishWord
+	translation.PolishWordID = polishWord.ID
 	return &translation, nil
 }
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 
 	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/lib/pq"
 )
 
 type ExampleSentenceRepositoryDB struct {
@@ -12,126 +13,216 @@ type ExampleSentenceRepositoryDB struct {
 }
 
 func (esr *ExampleSentenceRepositoryDB) AddExampleSentence(ctx context.Context, translationID string, exampleSentence model.AddExampleSentenceInput) (*model.ExampleSentence, error) {
+	return inTx(ctx, esr.DB, func(ctx context.Context) (*model.ExampleSentence, error) {
+		newExampleSentence := &model.ExampleSentence{
+			SentencePl:    exampleSentence.SentencePl,
+			SentenceEn:    exampleSentence.SentenceEn,
+			TranslationID: translationID,
+		}
 
-	newExampleSentence := &model.ExampleSentence{
-		SentencePl: exampleSentence.SentencePl,
-		SentenceEn: exampleSentence.SentenceEn,
-	}
-
-	id, version, err := esr.insertExampleSentence(ctx, translationID, newExampleSentence.SentencePl, newExampleSentence.SentenceEn)
-	if err != nil {
-		return nil, err
-	}
-	newExampleSentence.ID = id
-	newExampleSentence.Version = version
+		id, version, err := esr.insertExampleSentence(ctx, translationID, newExampleSentence.SentencePl, newExampleSentence.SentenceEn, exampleSentence.Visibility)
+		if err != nil {
+			return nil, err
+		}
+		newExampleSentence.ID = id
+		newExampleSentence.Version = version
 
-	translation, err := esr.fetchTranslationAndPolishWord(ctx, translationID)
-	if err != nil {
-		return nil, err
-	}
-	newExampleSentence.Translation = translation
+		if err := addSentenceTexts(ctx, conn(ctx, esr.DB), id, exampleSentence.Texts); err != nil {
+			return nil, err
+		}
 
-	return newExampleSentence, nil
+		translation, err := esr.fetchTranslationAndPolishWord(ctx, translationID)
+		if err != nil {
+			return nil, err
+		}
+		newExampleSentence.Translation = translation
 
+		return newExampleSentence, nil
+	})
 }
 
 func (esr *ExampleSentenceRepositoryDB) DeleteExampleSentence(ctx context.Context, id string) (*model.ExampleSentence, error) {
+	return inTx(ctx, esr.DB, func(ctx context.Context) (*model.ExampleSentence, error) {
+		if err := authorizeDelete(ctx, conn(ctx, esr.DB), ownsExampleSentence, "example sentence", id); err != nil {
+			return nil, err
+		}
 
-	deletedEs := &model.ExampleSentence{
-		ID:          id,
-		Translation: &model.Translation{},
-	}
-	err := esr.DB.QueryRowContext(ctx, "DELETE FROM example_sentences WHERE id = $1 RETURNING sentence_pl, sentence_en, translation_id, version", id).
-		Scan(&deletedEs.SentencePl, &deletedEs.SentenceEn, &deletedEs.Translation.ID, &deletedEs.Version)
+		deletedEs := &model.ExampleSentence{
+			ID:          id,
+			Translation: &model.Translation{},
+		}
+		err := conn(ctx, esr.DB).QueryRowContext(ctx, "UPDATE example_sentences SET deleted_at = now() WHERE id = $1 AND "+notDeleted+" AND "+visibleTo(ctx, "")+" RETURNING sentence_pl, sentence_en, translation_id, version", id).
+			Scan(&deletedEs.SentencePl, &deletedEs.SentenceEn, &deletedEs.Translation.ID, &deletedEs.Version)
 
-	if err != nil {
-		return nil, err
-	}
+		if err != nil {
+			return nil, notFoundOr(err, "example sentence", "id", id)
+		}
+		deletedEs.TranslationID = deletedEs.Translation.ID
 
-	translation, err := esr.fetchTranslationAndPolishWord(ctx, deletedEs.Translation.ID)
-	if err != nil {
-		return nil, err
-	}
+		translation, err := esr.fetchTranslationAndPolishWord(ctx, deletedEs.Translation.ID)
+		if err != nil {
+			return nil, err
+		}
 
-	deletedEs.Translation = translation
+		deletedEs.Translation = translation
 
-	return deletedEs, nil
+		return deletedEs, nil
+	})
 }
 
 func (esr *ExampleSentenceRepositoryDB) UpdateExampleSentence(ctx context.Context, id string, edits model.EditExampleSentenceInput) (*model.ExampleSentence, error) {
-
-	es := &model.ExampleSentence{
-		ID:          id,
-		Translation: &model.Translation{},
+	if edits.ID != nil && *edits.ID != id {
+		return nil, validationError("id", "edits id does not match the example sentence being updated")
 	}
 
-	var translationID string
+	return inTx(ctx, esr.DB, func(ctx context.Context) (*model.ExampleSentence, error) {
+		es := &model.ExampleSentence{
+			ID:          id,
+			Translation: &model.Translation{},
+		}
 
-	err := esr.DB.QueryRowContext(ctx, "SELECT sentence_pl, sentence_en, translation_id, version FROM example_sentences WHERE id = $1", id).
-		Scan(&es.SentencePl, &es.SentenceEn, &translationID, &es.Version)
+		var translationID string
 
-	if err != nil {
-		return nil, err
-	}
+		err := conn(ctx, esr.DB).QueryRowContext(ctx, "SELECT sentence_pl, sentence_en, translation_id, version FROM example_sentences WHERE id = $1 AND "+notDeleted+" AND "+visibleTo(ctx, ""), id).
+			Scan(&es.SentencePl, &es.SentenceEn, &translationID, &es.Version)
 
-	if err := UpdateSingleExampleSentence(ctx, esr.DB, es, &edits); err != nil {
-		return nil, err
-	}
+		if err != nil {
+			return nil, notFoundOr(err, "example sentence", "id", id)
+		}
+		es.TranslationID = translationID
 
-	translation, err := esr.fetchTranslationAndPolishWord(ctx, translationID)
-	if err != nil {
-		return nil, err
-	}
+		if err := UpdateSingleExampleSentence(ctx, conn(ctx, esr.DB), es, &edits); err != nil {
+			return nil, err
+		}
 
-	es.Translation = translation
+		translation, err := esr.fetchTranslationAndPolishWord(ctx, translationID)
+		if err != nil {
+			return nil, err
+		}
 
-	return es, nil
+		es.Translation = translation
+
+		return es, nil
+	})
 }
 
 func (esr *ExampleSentenceRepositoryDB) GetSingleExampleSentence(ctx context.Context, id string) (*model.ExampleSentence, error) {
 
 	es := &model.ExampleSentence{
-		ID:          id,
-		Translation: &model.Translation{},
+		ID: id,
 	}
 
-	var translationID string
-
-	err := esr.DB.QueryRowContext(ctx, "SELECT sentence_pl, sentence_en, translation_id, version FROM example_sentences WHERE id = $1", id).
-		Scan(&es.SentencePl, &es.SentenceEn, &translationID, &es.Version)
+	err := conn(ctx, esr.DB).QueryRowContext(ctx, "SELECT sentence_pl, sentence_en, translation_id, version FROM example_sentences WHERE id = $1 AND "+notDeleted+" AND "+visibleTo(ctx, ""), id).
+		Scan(&es.SentencePl, &es.SentenceEn, &es.TranslationID, &es.Version)
 
 	if err != nil {
-		return nil, err
+		return nil, notFoundOr(err, "example sentence", "id", id)
 	}
 
-	translation, err := esr.fetchTranslationAndPolishWord(ctx, translationID)
+	return es, nil
+}
+
+func (esr *ExampleSentenceRepositoryDB) GetExampleSentencesByIDs(ctx context.Context, ids []string) (map[string]*model.ExampleSentence, error) {
+	rows, err := conn(ctx, esr.DB).QueryContext(ctx,
+		"SELECT id, sentence_pl, sentence_en, translation_id, version FROM example_sentences WHERE id = ANY($1::int[]) AND "+notDeleted+" AND "+visibleTo(ctx, ""), pq.Array(ids))
 	if err != nil {
-		return nil, err
+		return nil, dbError(err)
 	}
+	defer rows.Close()
 
-	es.Translation = translation
+	exampleSentences := make(map[string]*model.ExampleSentence, len(ids))
+	for rows.Next() {
+		var es model.ExampleSentence
+		if err := rows.Scan(&es.ID, &es.SentencePl, &es.SentenceEn, &es.TranslationID, &es.Version); err != nil {
+			return nil, dbError(err)
+		}
+		exampleSentences[es.ID] = &es
+	}
 
-	return es, nil
+	if err = rows.Err(); err != nil {
+		return nil, dbError(err)
+	}
+
+	return exampleSentences, nil
 }
 
 func (esr *ExampleSentenceRepositoryDB) GetExampleSentencesByTranslationId(ctx context.Context, translationID string) ([]*model.ExampleSentence, error) {
+	exampleSentences, err := GetCurrentExampleSentencesFromDB(ctx, conn(ctx, esr.DB), translationID)
+	if err != nil {
+		return nil, dbError(err)
+	}
 
-	exampleSentences, err := GetCurrentExampleSentencesFromDB(ctx, esr.DB, translationID)
+	return exampleSentences, nil
+}
 
+func (esr *ExampleSentenceRepositoryDB) GetExampleSentencesByTranslationIDs(ctx context.Context, translationIDs []string) (map[string][]*model.ExampleSentence, error) {
+	rows, err := conn(ctx, esr.DB).QueryContext(ctx,
+		"SELECT id, sentence_pl, sentence_en, translation_id, version FROM example_sentences WHERE translation_id = ANY($1::int[]) AND "+notDeleted+" AND "+visibleTo(ctx, "")+" ORDER BY id",
+		pq.Array(translationIDs))
 	if err != nil {
-		return nil, err
+		return nil, dbError(err)
 	}
+	defer rows.Close()
 
-	for _, es := range exampleSentences {
-
-		translation, err := esr.fetchTranslationAndPolishWord(ctx, translationID)
+	exampleSentences := make(map[string][]*model.ExampleSentence, len(translationIDs))
+	for _, translationID := range translationIDs {
+		exampleSentences[translationID] = []*model.ExampleSentence{}
+	}
 
-		if err != nil {
-			return nil, err
+	for rows.Next() {
+		var es model.ExampleSentence
+		if err := rows.Scan(&es.ID, &es.SentencePl, &es.SentenceEn, &es.TranslationID, &es.Version); err != nil {
+			return nil, dbError(err)
 		}
+		exampleSentences[es.TranslationID] = append(exampleSentences[es.TranslationID], &es)
+	}
 
-		es.Translation = translation
+	if err = rows.Err(); err != nil {
+		return nil, dbError(err)
 	}
 
 	return exampleSentences, nil
 }
+
+func (esr *ExampleSentenceRepositoryDB) GetAccessByExampleSentenceIDs(ctx context.Context, ids []string) (map[string]*Access, error) {
+	return getAccessByIDs(ctx, conn(ctx, esr.DB), "example_sentences", ids)
+}
+
+// GetTextsByExampleSentenceIDs lists the texts of each sentence in the
+// language of its lexeme first, then that of its translation, then the
+// further languages in alphabetical order.
+func (esr *ExampleSentenceRepositoryDB) GetTextsByExampleSentenceIDs(ctx context.Context, ids []string) (map[string][]*model.SentenceText, error) {
+	rows, err := conn(ctx, esr.DB).QueryContext(ctx, `
+		SELECT st.example_sentence_id, st.language, st.text
+		FROM sentence_texts st
+		JOIN example_sentences es ON es.id = st.example_sentence_id
+		JOIN translations t ON t.id = es.translation_id
+		JOIN lexemes l ON l.id = t.polish_word_id
+		WHERE st.example_sentence_id = ANY($1::int[]) AND `+visibleTo(ctx, "es")+`
+		ORDER BY st.example_sentence_id, st.language = l.language DESC, st.language = t.language DESC, st.language`,
+		pq.Array(ids))
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	texts := make(map[string][]*model.SentenceText, len(ids))
+	for _, id := range ids {
+		texts[id] = []*model.SentenceText{}
+	}
+
+	for rows.Next() {
+		var exampleSentenceID string
+		var text model.SentenceText
+		if err := rows.Scan(&exampleSentenceID, &text.Language, &text.Text); err != nil {
+			return nil, dbError(err)
+		}
+		texts[exampleSentenceID] = append(texts[exampleSentenceID], &text)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, dbError(err)
+	}
+
+	return texts, nil
+}
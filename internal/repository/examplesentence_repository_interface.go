@@ -12,4 +12,7 @@ type ExampleSentenceRepositoryInterface interface {
 	UpdateExampleSentence(ctx context.Context, id string, edits model.EditExampleSentenceInput) (*model.ExampleSentence, error)
 	GetSingleExampleSentence(ctx context.Context, id string) (*model.ExampleSentence, error)
 	GetExampleSentencesByTranslationId(ctx context.Context, translationID string) ([]*model.ExampleSentence, error)
+	GetExampleSentencesByTranslationIDs(ctx context.Context, translationIDs []string) (map[string][]*model.ExampleSentence, error)
+	GetAccessByExampleSentenceIDs(ctx context.Context, ids []string) (map[string]*Access, error)
+	GetTextsByExampleSentenceIDs(ctx context.Context, ids []string) (map[string][]*model.SentenceText, error)
 }
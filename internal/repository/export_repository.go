@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/auth"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+)
+
+type ExportRepositoryDB struct {
+	DB *sql.DB
+}
+
+// ForEachEntry streams every Polish word the caller may see with its translations and example
+// sentences to fn, in id order; admins get every entry. Sentences carry their texts in further languages. Lexemes in other languages are left out. The rows are read from a single REPEATABLE
+// READ snapshot, so an export is consistent even while the dictionary is
+// being edited.
+func (er *ExportRepositoryDB) ForEachEntry(ctx context.Context, fn func(entry *model.AddPolishWordInput) error) error {
+	tx, err := er.DB.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return dbError(err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT p.id, p.word, p.ipa, p.syllabification, p.stressed_syllable, p.cefr_level, p.frequency_rank, p.visibility,
+			t.id, t.english_word, t.language, `+qualifiedTranslationGrammarColumns+`, t.cefr_level, t.frequency_rank, t.visibility,
+			es.sentence_pl, es.sentence_en, es.visibility,
+			(SELECT json_agg(json_build_object('language', st.language, 'text', st.text) ORDER BY st.language)
+			FROM sentence_texts st
+			WHERE st.example_sentence_id = es.id AND st.language <> p.language AND st.language <> t.language)
+		FROM lexemes p
+		LEFT JOIN translations t ON t.polish_word_id = p.id AND t.deleted_at IS NULL AND `+exportableTo(ctx, "t")+`
+		LEFT JOIN example_sentences es ON es.translation_id = t.id AND es.deleted_at IS NULL AND `+exportableTo(ctx, "es")+`
+		WHERE p.language = 'pl' AND p.deleted_at IS NULL AND `+exportableTo(ctx, "p")+`
+		ORDER BY p.id, t.id, es.id`)
+	if err != nil {
+		return dbError(err)
+	}
+	defer rows.Close()
+
+	var entry *model.AddPolishWordInput
+	var translation *model.AddTranslationInput
+	var entryID, translationID string
+	var entryVisibility, translationVisibility string
+
+	for rows.Next() {
+		var polishWordID string
+		var word, wordVisibility string
+		var currentTranslationID, englishWord, language, currentTranslationVisibility, sentencePl, sentenceEn, sentenceVisibility sql.NullString
+		var pron model.Pronunciation
+		var grammar model.Translation
+		var wordLevel, translationLevel Level
+		var texts []byte
+
+		dest := append(append([]any{&polishWordID, &word}, pronunciationFields(&pron)...), &wordLevel.CefrLevel, &wordLevel.FrequencyRank, &wordVisibility, &currentTranslationID, &englishWord, &language)
+		dest = append(dest, translationGrammarFields(&grammar)...)
+		if err := rows.Scan(append(dest, &translationLevel.CefrLevel, &translationLevel.FrequencyRank, &currentTranslationVisibility, &sentencePl, &sentenceEn, &sentenceVisibility, &texts)...); err != nil {
+			return dbError(err)
+		}
+
+		if entry == nil || polishWordID != entryID {
+			if entry != nil {
+				if err := fn(entry); err != nil {
+					return err
+				}
+			}
+
+			entry = &model.AddPolishWordInput{
+				Word:          word,
+				CefrLevel:     wordLevel.CefrLevel,
+				FrequencyRank: wordLevel.FrequencyRank,
+				Visibility:    exportVisibility(wordVisibility, "public"),
+				Translations:  []*model.AddTranslationInput{},
+			}
+			if pronunciationOrNil(&pron) != nil {
+				entry.Pronunciation = &model.PronunciationInput{Ipa: pron.Ipa, Syllabification: pron.Syllabification, StressedSyllable: pron.StressedSyllable}
+			}
+			entryID = polishWordID
+			entryVisibility = wordVisibility
+			translation = nil
+		}
+
+		if !currentTranslationID.Valid {
+			continue
+		}
+
+		if translation == nil || currentTranslationID.String != translationID {
+			translation = &model.AddTranslationInput{
+				EnglishWord:      englishWord.String,
+				PartOfSpeech:     grammar.PartOfSpeech,
+				Gender:           grammar.Gender,
+				Aspect:           grammar.Aspect,
+				AspectPartner:    grammar.AspectPartner,
+				UsageNote:        grammar.UsageNote,
+				CefrLevel:        translationLevel.CefrLevel,
+				FrequencyRank:    translationLevel.FrequencyRank,
+				Visibility:       exportVisibility(currentTranslationVisibility.String, entryVisibility),
+				ExampleSentences: []*model.AddExampleSentenceInput{},
+			}
+			// English is the default, so it is only written for other languages.
+			if language.String != englishLanguage {
+				translation.Language = &language.String
+			}
+			translationID = currentTranslationID.String
+			translationVisibility = currentTranslationVisibility.String
+			entry.Translations = append(entry.Translations, translation)
+		}
+
+		if sentencePl.Valid {
+			es := &model.AddExampleSentenceInput{
+				SentencePl: sentencePl.String,
+				SentenceEn: sentenceEn.String,
+				Visibility: exportVisibility(sentenceVisibility.String, translationVisibility),
+			}
+			if texts != nil {
+				if err := json.Unmarshal(texts, &es.Texts); err != nil {
+					return fmt.Errorf("failed to decode sentence texts: %w", err)
+				}
+			}
+			translation.ExampleSentences = append(translation.ExampleSentences, es)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return dbError(err)
+	}
+
+	if entry != nil {
+		return fn(entry)
+	}
+
+	return nil
+}
+
+// exportableTo restricts an export to the entries the caller may see. An
+// admin's export is a full dump, with the private entries of every owner.
+func exportableTo(ctx context.Context, alias string) string {
+	if auth.PrincipalFrom(ctx).HasRole(auth.RoleAdmin) {
+		return "true"
+	}
+	return visibleTo(ctx, alias)
+}
+
+// exportVisibility leaves out a visibility that an import would give the entry
+// anyway, which is the one of the entry it belongs to.
+func exportVisibility(visibility string, parent string) *model.Visibility {
+	if visibility == parent {
+		return nil
+	}
+	v := model.Visibility(strings.ToUpper(visibility))
+	return &v
+}
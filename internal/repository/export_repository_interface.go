@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+)
+
+type ExportRepositoryInterface interface {
+	ForEachEntry(ctx context.Context, fn func(entry *model.AddPolishWordInput) error) error
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+type FrequencyRepositoryDB struct {
+	DB *sql.DB
+}
+
+// LoadFrequencyRanks ranks the lexemes and translations in language by their
+// position in words, which are lowercase. The list replaces the previous one:
+// entries it does not contain lose their rank.
+func (fr *FrequencyRepositoryDB) LoadFrequencyRanks(ctx context.Context, language string, words []string) (*FrequencyReport, error) {
+	language, err := normalizeLanguage("language", language)
+	if err != nil {
+		return nil, err
+	}
+
+	return inTx(ctx, fr.DB, func(ctx context.Context) (*FrequencyReport, error) {
+		var report FrequencyReport
+
+		for _, target := range []struct {
+			table  string
+			column string
+			count  *int
+		}{
+			{"lexemes", "word", &report.Lexemes},
+			{"translations", "english_word", &report.Translations},
+		} {
+			_, err := conn(ctx, fr.DB).ExecContext(ctx,
+				"UPDATE "+target.table+" SET frequency_rank = NULL WHERE language = $1 AND frequency_rank IS NOT NULL", language)
+			if err != nil {
+				return nil, dbError(err)
+			}
+
+			result, err := conn(ctx, fr.DB).ExecContext(ctx, `
+				UPDATE `+target.table+` e SET frequency_rank = f.rank
+				FROM unnest($2::text[]) WITH ORDINALITY AS f(word, rank)
+				WHERE e.language = $1 AND LOWER(e.`+target.column+`) = f.word`, language, pq.Array(words))
+			if err != nil {
+				return nil, dbError(err)
+			}
+
+			ranked, err := result.RowsAffected()
+			if err != nil {
+				return nil, dbError(err)
+			}
+			*target.count = int(ranked)
+		}
+
+		return &report, nil
+	})
+}
@@ -0,0 +1,13 @@
+package repository
+
+import "context"
+
+// FrequencyReport counts the entries that were given a rank.
+type FrequencyReport struct {
+	Lexemes      int
+	Translations int
+}
+
+type FrequencyRepositoryInterface interface {
+	LoadFrequencyRanks(ctx context.Context, language string, words []string) (*FrequencyReport, error)
+}
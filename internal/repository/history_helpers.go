@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/apperror"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/auth"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/lib/pq"
+)
+
+// The history tables are filled by triggers, so every write is recorded
+// whichever path it takes. Snapshots are the rows as JSON, keyed by column.
+
+type polishWordSnapshot struct {
+	ID               int              `json:"id"`
+	Word             string           `json:"word"`
+	Version          int              `json:"version"`
+	IPA              *string          `json:"ipa"`
+	Syllabification  *string          `json:"syllabification"`
+	StressedSyllable *int             `json:"stressed_syllable"`
+	CefrLevel        *model.CefrLevel `json:"cefr_level"`
+	FrequencyRank    *int             `json:"frequency_rank"`
+	DeletedAt        *time.Time       `json:"deleted_at"`
+	entryAccess
+}
+
+type translationSnapshot struct {
+	ID            int                 `json:"id"`
+	EnglishWord   string              `json:"english_word"`
+	Language      string              `json:"language"`
+	Version       int                 `json:"version"`
+	PartOfSpeech  *model.PartOfSpeech `json:"part_of_speech"`
+	Gender        *model.Gender       `json:"gender"`
+	Aspect        *model.Aspect       `json:"aspect"`
+	AspectPartner *string             `json:"aspect_partner"`
+	UsageNote     *string             `json:"usage_note"`
+	CefrLevel     *model.CefrLevel    `json:"cefr_level"`
+	FrequencyRank *int                `json:"frequency_rank"`
+	entryAccess
+}
+
+type exampleSentenceSnapshot struct {
+	ID            int    `json:"id"`
+	TranslationID int    `json:"translation_id"`
+	SentencePl    string `json:"sentence_pl"`
+	SentenceEn    string `json:"sentence_en"`
+	Version       int    `json:"version"`
+	entryAccess
+}
+
+// entryAccess is who owned an entry and who could see it. Snapshots taken
+// before entries had a visibility were public.
+type entryAccess struct {
+	Owner      *string `json:"owner"`
+	Visibility string  `json:"visibility"`
+}
+
+func (a entryAccess) visibility() model.Visibility {
+	if a.Visibility == "" {
+		return model.VisibilityPublic
+	}
+	return model.Visibility(strings.ToUpper(a.Visibility))
+}
+
+// visibleTo mirrors the visibleTo condition for snapshots, which are only
+// filtered once decoded.
+func (a entryAccess) visibleTo(ctx context.Context) bool {
+	switch a.visibility() {
+	case model.VisibilityPublic:
+		return true
+	case model.VisibilityShared:
+		return auth.PrincipalFrom(ctx) != nil
+	default:
+		principal := auth.PrincipalFrom(ctx)
+		return principal != nil && a.Owner != nil && *a.Owner == principal.Name
+	}
+}
+
+func (s *polishWordSnapshot) polishWord() *model.PolishWord {
+	return &model.PolishWord{
+		ID:      strconv.Itoa(s.ID),
+		Word:    s.Word,
+		Version: s.Version,
+		Pronunciation: pronunciationOrNil(&model.Pronunciation{
+			Ipa:              s.IPA,
+			Syllabification:  s.Syllabification,
+			StressedSyllable: s.StressedSyllable,
+		}),
+		CefrLevel:     s.CefrLevel,
+		FrequencyRank: s.FrequencyRank,
+		Visibility:    s.visibility(),
+		Owner:         s.Owner,
+	}
+}
+
+// fetchPolishWordRevision returns the word as it was at version, or at the
+// time asOf, together with the time that revision was recorded. Revisions the
+// caller could not see are not found.
+func (pwr *PolishWordRepositoryDB) fetchPolishWordRevision(ctx context.Context, id string, version *int, asOf *time.Time) (*polishWordSnapshot, time.Time, error) {
+	var raw []byte
+	var changedAt time.Time
+
+	err := conn(ctx, pwr.DB).QueryRowContext(ctx, `
+		SELECT snapshot, changed_at
+		FROM polish_word_history
+		WHERE entity_id = $1 AND ($2::int IS NULL OR version = $2) AND ($3::timestamptz IS NULL OR changed_at <= $3)
+		ORDER BY id DESC
+		LIMIT 1`, id, version, asOf).Scan(&raw, &changedAt)
+	if err != nil {
+		if version != nil {
+			return nil, time.Time{}, notFoundOr(err, "polish word version", "asOfVersion", strconv.Itoa(*version))
+		}
+		return nil, time.Time{}, notFoundOr(err, "polish word", "id", id)
+	}
+
+	var snapshot polishWordSnapshot
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return nil, time.Time{}, err
+	}
+	if !snapshot.visibleTo(ctx) {
+		return nil, time.Time{}, &apperror.NotFoundError{Entity: "polish word", Field: "id", Value: id}
+	}
+
+	return &snapshot, changedAt, nil
+}
+
+// fetchTranslationsAsOf returns the translations of polishWordID that were
+// live at the time asOf and that the caller could see, with their example
+// sentences.
+func (pwr *PolishWordRepositoryDB) fetchTranslationsAsOf(ctx context.Context, polishWordID string, asOf time.Time, past *model.PolishWord) ([]*model.Translation, error) {
+	var snapshots []translationSnapshot
+	if err := fetchSnapshotsAsOf(ctx, conn(ctx, pwr.DB), "translation_history", "polish_word_id", []string{polishWordID}, asOf, &snapshots); err != nil {
+		return nil, err
+	}
+
+	snapshots = slices.DeleteFunc(snapshots, func(s translationSnapshot) bool { return !s.visibleTo(ctx) })
+
+	translations := make([]*model.Translation, len(snapshots))
+	translationsByID := make(map[string]*model.Translation, len(snapshots))
+	translationIDs := make([]string, len(snapshots))
+	for i, s := range snapshots {
+		translations[i] = &model.Translation{
+			ID:               strconv.Itoa(s.ID),
+			EnglishWord:      s.EnglishWord,
+			Language:         s.Language,
+			PartOfSpeech:     s.PartOfSpeech,
+			Gender:           s.Gender,
+			Aspect:           s.Aspect,
+			AspectPartner:    s.AspectPartner,
+			UsageNote:        s.UsageNote,
+			CefrLevel:        s.CefrLevel,
+			FrequencyRank:    s.FrequencyRank,
+			Visibility:       s.visibility(),
+			Owner:            s.Owner,
+			Version:          s.Version,
+			ExampleSentences: []*model.ExampleSentence{},
+			PolishWord:       past,
+			PolishWordID:     polishWordID,
+			Past:             true,
+		}
+		translationIDs[i] = translations[i].ID
+		translationsByID[translations[i].ID] = translations[i]
+	}
+
+	if len(translationIDs) == 0 {
+		return translations, nil
+	}
+
+	var sentences []exampleSentenceSnapshot
+	if err := fetchSnapshotsAsOf(ctx, conn(ctx, pwr.DB), "example_sentence_history", "translation_id", translationIDs, asOf, &sentences); err != nil {
+		return nil, err
+	}
+
+	for _, s := range sentences {
+		if !s.visibleTo(ctx) {
+			continue
+		}
+		translation := translationsByID[strconv.Itoa(s.TranslationID)]
+		translation.ExampleSentences = append(translation.ExampleSentences, &model.ExampleSentence{
+			ID:            strconv.Itoa(s.ID),
+			SentencePl:    s.SentencePl,
+			SentenceEn:    s.SentenceEn,
+			Version:       s.Version,
+			Visibility:    s.visibility(),
+			Owner:         s.Owner,
+			Translation:   translation,
+			TranslationID: translation.ID,
+			Past:          true,
+		})
+	}
+
+	return translations, nil
+}
+
+// fetchSnapshotsAsOf decodes into dest the latest snapshots recorded up to
+// asOf of the entries under parentIDs that were not deleted at the time.
+// table and parentColumn are never user input.
+func fetchSnapshotsAsOf(ctx context.Context, db DBTX, table string, parentColumn string, parentIDs []string, asOf time.Time, dest any) error {
+	var raw []byte
+	err := db.QueryRowContext(ctx, `
+		SELECT COALESCE(jsonb_agg(snapshot ORDER BY entity_id), '[]')
+		FROM (
+			SELECT DISTINCT ON (entity_id) entity_id, snapshot
+			FROM `+table+`
+			WHERE (snapshot ->> '`+parentColumn+`')::int = ANY($1::int[]) AND changed_at <= $2
+			ORDER BY entity_id, id DESC
+		) h
+		WHERE snapshot ->> 'deleted_at' IS NULL`, pq.Array(parentIDs), asOf).Scan(&raw)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw, dest)
+}
+
+// fieldChanges turns a revision diff into changes sorted by field name. Field
+// names are given as in the API, so stressed_syllable becomes stressedSyllable.
+// tenant follows from visibility and owner and is left out.
+func fieldChanges(raw []byte) ([]*model.FieldChange, error) {
+	var diff map[string]struct {
+		From json.RawMessage `json:"from"`
+		To   json.RawMessage `json:"to"`
+	}
+	if err := json.Unmarshal(raw, &diff); err != nil {
+		return nil, err
+	}
+
+	changes := make([]*model.FieldChange, 0, len(diff))
+	for column, change := range diff {
+		if column == "tenant" {
+			continue
+		}
+		changes = append(changes, &model.FieldChange{
+			Field: fieldName(column),
+			From:  fieldValue(change.From),
+			To:    fieldValue(change.To),
+		})
+	}
+
+	slices.SortFunc(changes, func(a, b *model.FieldChange) int {
+		return strings.Compare(a.Field, b.Field)
+	})
+
+	return changes, nil
+}
+
+func fieldName(column string) string {
+	parts := strings.Split(column, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// fieldValue writes a JSON value as text: strings without their quotes and
+// null as nil.
+func fieldValue(raw json.RawMessage) *string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return &s
+	}
+
+	s = string(raw)
+	return &s
+}
@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+)
+
+const (
+	// importChunkSize keeps multi-row statements well below the PostgreSQL
+	// limit of 65535 bind parameters.
+	importChunkSize = 1000
+
+	maxWordLength = 50
+)
+
+// upsertedRow is a row an import upserted, with the visibility it has now;
+// an existing row keeps its own.
+type upsertedRow struct {
+	id         string
+	visibility string
+	inserted   bool
+}
+
+// The keys name rows like the unique indexes do. private stands for the
+// tenant: a private entry lives in the dictionary of the importing caller,
+// next to a shared or public one with the same text.
+type lexemeKey struct {
+	word    string
+	private bool
+}
+
+type translationKey struct {
+	polishWordID string
+	language     string
+	englishWord  string
+	private      bool
+}
+
+type exampleSentenceKey struct {
+	translationID string
+	sentencePl    string
+	sentenceEn    string
+	private       bool
+}
+
+// lexemeValues and translationValues are what a new row is inserted with.
+type lexemeValues struct {
+	pronunciation *model.Pronunciation
+	level         *Level
+	visibility    string
+}
+
+type translationValues struct {
+	grammar    *model.Translation
+	level      *Level
+	visibility string
+}
+
+// validateImportRecord checks a record with the pronunciation its word would
+// be stored with.
+func validateImportRecord(input model.AddPolishWordInput, pron *model.Pronunciation) error {
+	if err := validateWord("word", input.Word); err != nil {
+		return err
+	}
+	if err := validatePronunciation(input.Word, pron); err != nil {
+		return err
+	}
+	if err := validateLevel(input.CefrLevel, input.FrequencyRank); err != nil {
+		return err
+	}
+
+	for _, t := range input.Translations {
+		if t == nil {
+			return validationError("translations", "translations must not contain null entries")
+		}
+		if err := validateWord("englishWord", t.EnglishWord); err != nil {
+			return err
+		}
+		language, err := translationLanguage(t.Language)
+		if err != nil {
+			return err
+		}
+		if err := validateTranslationGrammar(translationGrammarFromInput(t)); err != nil {
+			return err
+		}
+		if err := validateLevel(t.CefrLevel, t.FrequencyRank); err != nil {
+			return err
+		}
+
+		for _, es := range t.ExampleSentences {
+			if es == nil || strings.TrimSpace(es.SentencePl) == "" || strings.TrimSpace(es.SentenceEn) == "" {
+				return validationError("exampleSentences", "example sentences need both sentencePl and sentenceEn")
+			}
+			if _, err := furtherSentenceTexts(polishLanguage, language, es.Texts); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateWord(field string, word string) error {
+	if strings.TrimSpace(word) == "" {
+		return validationError(field, field+" must not be empty")
+	}
+	if utf8.RuneCountInString(word) > maxWordLength {
+		return validationError(field, fmt.Sprintf("%s must be at most %d characters long", field, maxWordLength))
+	}
+	return nil
+}
+
+// valuesPlaceholders returns "($1, $2), ($3, $4)" for rows of the given width.
+func valuesPlaceholders(rows int, width int) string {
+	var b strings.Builder
+	for r := 0; r < rows; r++ {
+		if r > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("(")
+		for c := 0; c < width; c++ {
+			if c > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(&b, "$%d", r*width+c+1)
+		}
+		b.WriteString(")")
+	}
+	return b.String()
+}
+
+// ON CONFLICT DO UPDATE cannot touch the same row twice in one statement, so
+// keys are deduplicated before they are sent.
+func uniqueKeys[K comparable](keys []K) []K {
+	seen := make(map[K]bool, len(keys))
+	unique := make([]K, 0, len(keys))
+	for _, key := range keys {
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, key)
+		}
+	}
+	return unique
+}
+
+// xmax is zero only for rows inserted by the current statement, which tells
+// new rows apart from ones that hit the ON CONFLICT branch. Like AddPolishWord,
+// an existing word keeps the pronunciation and visibility it already has and
+// takes the level given.
+func upsertPolishWords(ctx context.Context, db DBTX, keys []lexemeKey, values map[lexemeKey]*lexemeValues) (map[lexemeKey]upsertedRow, error) {
+	upserted := make(map[lexemeKey]upsertedRow, len(keys))
+
+	for chunk := range slices.Chunk(uniqueKeys(keys), importChunkSize) {
+		args := make([]any, 0, 7*len(chunk))
+		for _, key := range chunk {
+			v := values[key]
+			args = append(args, key.word)
+			args = append(args, pronunciationValues(v.pronunciation)...)
+			args = append(args, v.level.CefrLevel, v.level.FrequencyRank, v.visibility)
+		}
+
+		rows, err := db.QueryContext(ctx,
+			"INSERT INTO lexemes (word, "+pronunciationColumns+", "+levelColumns+", visibility) VALUES "+valuesPlaceholders(len(chunk), 7)+" "+
+				polishWordUpsertConflict+levelUpsertUpdate("lexemes")+" RETURNING id, word, tenant <> '', visibility, (xmax = 0)", args...)
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			var key lexemeKey
+			var row upsertedRow
+			if err := rows.Scan(&row.id, &key.word, &key.private, &row.visibility, &row.inserted); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			upserted[key] = row
+		}
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return upserted, nil
+}
+
+// Like AddTranslation, an existing translation keeps the grammar and
+// visibility it already has and takes the level given.
+func upsertTranslations(ctx context.Context, db DBTX, keys []translationKey, values map[translationKey]*translationValues) (map[translationKey]upsertedRow, error) {
+	upserted := make(map[translationKey]upsertedRow, len(keys))
+
+	for chunk := range slices.Chunk(uniqueKeys(keys), importChunkSize) {
+		args := make([]any, 0, 11*len(chunk))
+		for _, key := range chunk {
+			v := values[key]
+			args = append(args, key.englishWord, key.language, key.polishWordID)
+			args = append(args, translationGrammarValues(v.grammar)...)
+			args = append(args, v.level.CefrLevel, v.level.FrequencyRank, v.visibility)
+		}
+
+		rows, err := db.QueryContext(ctx,
+			"INSERT INTO translations (english_word, language, polish_word_id, "+translationGrammarColumns+", "+levelColumns+", visibility) VALUES "+valuesPlaceholders(len(chunk), 11)+" "+
+				translationUpsertConflict+levelUpsertUpdate("translations")+" RETURNING id, polish_word_id, language, english_word, tenant <> '', visibility, (xmax = 0)", args...)
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			var key translationKey
+			var row upsertedRow
+			if err := rows.Scan(&row.id, &key.polishWordID, &key.language, &key.englishWord, &key.private, &row.visibility, &row.inserted); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			upserted[key] = row
+		}
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return upserted, nil
+}
+
+func upsertExampleSentences(ctx context.Context, db DBTX, keys []exampleSentenceKey, visibilities map[exampleSentenceKey]string) (map[exampleSentenceKey]upsertedRow, error) {
+	upserted := make(map[exampleSentenceKey]upsertedRow, len(keys))
+
+	for chunk := range slices.Chunk(uniqueKeys(keys), importChunkSize) {
+		args := make([]any, 0, 4*len(chunk))
+		for _, key := range chunk {
+			args = append(args, key.sentencePl, key.sentenceEn, key.translationID, visibilities[key])
+		}
+
+		rows, err := db.QueryContext(ctx,
+			"INSERT INTO example_sentences (sentence_pl, sentence_en, translation_id, visibility) VALUES "+valuesPlaceholders(len(chunk), 4)+" "+
+				exampleSentenceUpsertConflict+" RETURNING id, translation_id, sentence_pl, sentence_en, tenant <> '', visibility, (xmax = 0)", args...)
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			var key exampleSentenceKey
+			var row upsertedRow
+			if err := rows.Scan(&row.id, &key.translationID, &key.sentencePl, &key.sentenceEn, &key.private, &row.visibility, &row.inserted); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			upserted[key] = row
+		}
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return upserted, nil
+}
+
+// importVisibility is the stored form of the visibility an imported entry is
+// given: the one in the record, or else that of the entry it belongs to.
+func importVisibility(visibility *model.Visibility, parent string) string {
+	if visibility == nil {
+		return parent
+	}
+	return strings.ToLower(string(*visibility))
+}
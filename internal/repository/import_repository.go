@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/apperror"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/pronunciation"
+)
+
+type ImportRepositoryDB struct {
+	DB *sql.DB
+	// Pronunciation is optional; without it only pronunciations given in the
+	// records are stored.
+	Pronunciation pronunciation.Generator
+}
+
+// ImportBatch upserts the records in one transaction, using one multi-row
+// statement per table and the same ON CONFLICT clauses as AddPolishWord,
+// AddTranslation and AddExampleSentence. When the batch fails as a whole it
+// is retried record by record, so a bad row only fails its own line.
+func (ir *ImportRepositoryDB) ImportBatch(ctx context.Context, records []ImportRecord) ([]*model.ImportRowResult, error) {
+	results := make([]*model.ImportRowResult, len(records))
+	var valid []int
+
+	for i := range records {
+		results[i] = &model.ImportRowResult{Line: records[i].Line, Word: &records[i].Input.Word}
+
+		if err := validateImportRecord(records[i].Input, ir.importPronunciation(records[i].Input)); err != nil {
+			markImportFailed(results[i], err)
+			continue
+		}
+		valid = append(valid, i)
+	}
+
+	if len(valid) == 0 {
+		return results, nil
+	}
+
+	err := RunInTx(ctx, ir.DB, func(ctx context.Context) error {
+		return ir.upsertRecords(ctx, records, valid, results)
+	})
+	if err == nil {
+		return results, nil
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	for _, i := range valid {
+		err := RunInTx(ctx, ir.DB, func(ctx context.Context) error {
+			return ir.upsertRecords(ctx, records, []int{i}, results)
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			markImportFailed(results[i], dbError(err))
+		}
+	}
+
+	return results, nil
+}
+
+func (ir *ImportRepositoryDB) upsertRecords(ctx context.Context, records []ImportRecord, indexes []int, results []*model.ImportRowResult) error {
+	db := conn(ctx, ir.DB)
+
+	var lexemeKeys []lexemeKey
+	lexemes := map[lexemeKey]*lexemeValues{}
+	for _, i := range indexes {
+		input := records[i].Input
+		key := importLexemeKey(input)
+		lexemeKeys = append(lexemeKeys, key)
+		if _, ok := lexemes[key]; !ok {
+			lexemes[key] = &lexemeValues{
+				pronunciation: ir.importPronunciation(input),
+				level:         &Level{CefrLevel: input.CefrLevel, FrequencyRank: input.FrequencyRank},
+				visibility:    importVisibility(input.Visibility, "public"),
+			}
+		}
+	}
+
+	polishWords, err := upsertPolishWords(ctx, db, lexemeKeys, lexemes)
+	if err != nil {
+		return err
+	}
+
+	var translationKeys []translationKey
+	translationInputs := map[translationKey]*translationValues{}
+	for _, i := range indexes {
+		polishWord := polishWords[importLexemeKey(records[i].Input)]
+		for _, t := range records[i].Input.Translations {
+			key := importTranslationKey(polishWord, t)
+			translationKeys = append(translationKeys, key)
+			if _, ok := translationInputs[key]; !ok {
+				translationInputs[key] = &translationValues{
+					grammar:    translationGrammarFromInput(t),
+					level:      &Level{CefrLevel: t.CefrLevel, FrequencyRank: t.FrequencyRank},
+					visibility: importVisibility(t.Visibility, polishWord.visibility),
+				}
+			}
+		}
+	}
+
+	translations, err := upsertTranslations(ctx, db, translationKeys, translationInputs)
+	if err != nil {
+		return err
+	}
+
+	var sentenceKeys []exampleSentenceKey
+	sentenceVisibilities := map[exampleSentenceKey]string{}
+	for _, i := range indexes {
+		polishWord := polishWords[importLexemeKey(records[i].Input)]
+		for _, t := range records[i].Input.Translations {
+			translation := translations[importTranslationKey(polishWord, t)]
+			for _, es := range t.ExampleSentences {
+				key := importSentenceKey(translation, es)
+				sentenceKeys = append(sentenceKeys, key)
+				if _, ok := sentenceVisibilities[key]; !ok {
+					sentenceVisibilities[key] = importVisibility(es.Visibility, translation.visibility)
+				}
+			}
+		}
+	}
+
+	sentences, err := upsertExampleSentences(ctx, db, sentenceKeys, sentenceVisibilities)
+	if err != nil {
+		return err
+	}
+
+	var texts []sentenceTextRow
+	for _, i := range indexes {
+		polishWord := polishWords[importLexemeKey(records[i].Input)]
+		for _, t := range records[i].Input.Translations {
+			key := importTranslationKey(polishWord, t)
+			for _, es := range t.ExampleSentences {
+				sentenceID := sentences[importSentenceKey(translations[key], es)].id
+				for _, text := range importSentenceTexts(key.language, es) {
+					texts = append(texts, sentenceTextRow{key: sentenceTextKey{exampleSentenceID: sentenceID, language: text.Language}, text: text.Text})
+				}
+			}
+		}
+	}
+
+	insertedTexts, err := insertSentenceTexts(ctx, db, texts)
+	if err != nil {
+		return err
+	}
+
+	// A row inserted by this batch is credited to the first record that
+	// mentions it; later records repeating it only count as skipped.
+	claimed := map[string]bool{}
+	claim := func(key string, row upsertedRow) bool {
+		if !row.inserted || claimed[key] {
+			return false
+		}
+		claimed[key] = true
+		return true
+	}
+
+	for _, i := range indexes {
+		input := records[i].Input
+		polishWord := polishWords[importLexemeKey(input)]
+
+		created := claim("lexemes:"+polishWord.id, polishWord)
+		merged := false
+
+		for _, t := range input.Translations {
+			translationKey := importTranslationKey(polishWord, t)
+			translation := translations[translationKey]
+			merged = claim("translations:"+translation.id, translation) || merged
+
+			for _, es := range t.ExampleSentences {
+				sentence := sentences[importSentenceKey(translation, es)]
+				merged = claim("example_sentences:"+sentence.id, sentence) || merged
+
+				for _, text := range importSentenceTexts(translationKey.language, es) {
+					key := sentenceTextKey{exampleSentenceID: sentence.id, language: text.Language}
+					merged = claim("sentence_texts:"+key.exampleSentenceID+":"+key.language, upsertedRow{inserted: insertedTexts[key]}) || merged
+				}
+			}
+		}
+
+		results[i].Error = nil
+		switch {
+		case created:
+			results[i].Status = model.ImportStatusCreated
+		case merged:
+			results[i].Status = model.ImportStatusMerged
+		default:
+			results[i].Status = model.ImportStatusSkipped
+		}
+	}
+
+	return nil
+}
+
+// importPronunciation is the pronunciation a new word is stored with: the one
+// in the record, with the fields it leaves out generated like AddPolishWord
+// does.
+func (ir *ImportRepositoryDB) importPronunciation(input model.AddPolishWordInput) *model.Pronunciation {
+	pron := pronunciationFromInput(input.Pronunciation)
+	generatePronunciation(ir.Pronunciation, input.Word, pron)
+	return pron
+}
+
+// importSentenceTexts expects a record that passed validateImportRecord, so
+// the further texts are known to be valid.
+func importSentenceTexts(translationLanguage string, es *model.AddExampleSentenceInput) []*model.SentenceText {
+	texts, _ := furtherSentenceTexts(polishLanguage, translationLanguage, es.Texts)
+	return texts
+}
+
+func importLexemeKey(input model.AddPolishWordInput) lexemeKey {
+	return lexemeKey{word: input.Word, private: importVisibility(input.Visibility, "public") == "private"}
+}
+
+// importTranslationKey expects a record that passed validateImportRecord, so
+// the language is known to be valid. A translation without a visibility of
+// its own is in the tenant of its word.
+func importTranslationKey(polishWord upsertedRow, t *model.AddTranslationInput) translationKey {
+	language, _ := translationLanguage(t.Language)
+	return translationKey{
+		polishWordID: polishWord.id,
+		language:     language,
+		englishWord:  t.EnglishWord,
+		private:      importVisibility(t.Visibility, polishWord.visibility) == "private",
+	}
+}
+
+func importSentenceKey(translation upsertedRow, es *model.AddExampleSentenceInput) exampleSentenceKey {
+	return exampleSentenceKey{
+		translationID: translation.id,
+		sentencePl:    es.SentencePl,
+		sentenceEn:    es.SentenceEn,
+		private:       importVisibility(es.Visibility, translation.visibility) == "private",
+	}
+}
+
+func markImportFailed(result *model.ImportRowResult, err error) {
+	message := apperror.PublicMessage(err)
+	result.Status = model.ImportStatusFailed
+	result.Error = &message
+}
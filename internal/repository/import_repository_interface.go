@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+)
+
+// ImportRecord is one entry of an import file together with the line it
+// started on.
+type ImportRecord struct {
+	Line  int
+	Input model.AddPolishWordInput
+}
+
+type ImportRepositoryInterface interface {
+	ImportBatch(ctx context.Context, records []ImportRecord) ([]*model.ImportRowResult, error)
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+)
+
+// "case" is a reserved word in SQL, hence grammatical_case.
+const inflectionColumns = "form, grammatical_case, number, person, tense, gender"
+
+// inflectionFields returns scan destinations matching inflectionColumns.
+func inflectionFields(i *model.Inflection) []any {
+	return []any{&i.Form, &i.Case, &i.Number, &i.Person, &i.Tense, &i.Gender}
+}
+
+func inflectionValues(i *model.Inflection) []any {
+	return []any{i.Form, i.Case, i.Number, i.Person, i.Tense, i.Gender}
+}
+
+// validateInflection accepts either a nominal form (case and number, with an
+// optional gender for adjectives) or a verb form (tense, person and number,
+// with an optional gender in the past tense).
+func validateInflection(i *model.Inflection) error {
+	if err := validateWord("form", i.Form); err != nil {
+		return err
+	}
+
+	switch {
+	case i.Case != nil && !i.Case.IsValid():
+		return validationError("case", fmt.Sprintf("unknown case %q", *i.Case))
+	case i.Number != nil && !i.Number.IsValid():
+		return validationError("number", fmt.Sprintf("unknown number %q", *i.Number))
+	case i.Person != nil && !i.Person.IsValid():
+		return validationError("person", fmt.Sprintf("unknown person %q", *i.Person))
+	case i.Tense != nil && !i.Tense.IsValid():
+		return validationError("tense", fmt.Sprintf("unknown tense %q", *i.Tense))
+	case i.Gender != nil && !i.Gender.IsValid():
+		return validationError("gender", fmt.Sprintf("unknown gender %q", *i.Gender))
+	}
+
+	if i.Case != nil {
+		if i.Person != nil || i.Tense != nil {
+			return validationError("case", "a form with a case cannot have a person or tense")
+		}
+		if i.Number == nil {
+			return validationError("number", "a form with a case needs a number")
+		}
+		return nil
+	}
+
+	if i.Tense == nil {
+		return validationError("", "an inflection needs either a case or a tense")
+	}
+	if i.Person == nil {
+		return validationError("person", "a verb form needs a person")
+	}
+	if i.Number == nil {
+		return validationError("number", "a verb form needs a number")
+	}
+	if i.Gender != nil && *i.Tense != model.TensePast {
+		return validationError("gender", "gender can only be set on past tense verb forms")
+	}
+
+	return nil
+}
+
+// applyInflectionEdits copies the fields set in edits onto i. Switching
+// between a nominal and a verb form clears the fields of the other kind, and
+// leaving the past tense clears the gender.
+func applyInflectionEdits(i *model.Inflection, edits *model.EditInflectionInput) {
+	if edits.Form != nil {
+		i.Form = *edits.Form
+	}
+
+	if edits.Case != nil {
+		i.Case = edits.Case
+		i.Person = nil
+		i.Tense = nil
+	}
+
+	if edits.Person != nil || edits.Tense != nil {
+		i.Case = nil
+	}
+	if edits.Person != nil {
+		i.Person = edits.Person
+	}
+	if edits.Tense != nil {
+		i.Tense = edits.Tense
+		if *i.Tense != model.TensePast {
+			i.Gender = nil
+		}
+	}
+
+	if edits.Number != nil {
+		i.Number = edits.Number
+	}
+	if edits.Gender != nil {
+		i.Gender = edits.Gender
+	}
+}
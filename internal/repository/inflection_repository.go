@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/lib/pq"
+)
+
+type InflectionRepositoryDB struct {
+	DB *sql.DB
+}
+
+func (ir *InflectionRepositoryDB) AddInflection(ctx context.Context, polishWordID *string, polishWord *string, inflection model.AddInflectionInput) (*model.Inflection, error) {
+	newInflection := &model.Inflection{
+		Form:   inflection.Form,
+		Case:   inflection.Case,
+		Number: inflection.Number,
+		Person: inflection.Person,
+		Tense:  inflection.Tense,
+		Gender: inflection.Gender,
+	}
+
+	if err := validateInflection(newInflection); err != nil {
+		return nil, err
+	}
+
+	return inTx(ctx, ir.DB, func(ctx context.Context) (*model.Inflection, error) {
+		targetPolishWordID, err := getTargetPolishWordID(ctx, conn(ctx, ir.DB), polishWordID, polishWord)
+		if err != nil {
+			return nil, err
+		}
+		newInflection.PolishWordID = *targetPolishWordID
+
+		err = conn(ctx, ir.DB).QueryRowContext(ctx,
+			"INSERT INTO inflections (polish_word_id, "+inflectionColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, version",
+			append([]any{*targetPolishWordID}, inflectionValues(newInflection)...)...).
+			Scan(&newInflection.ID, &newInflection.Version)
+		if err != nil {
+			return nil, missingReferenceOr(err, "polish word", "id", *targetPolishWordID)
+		}
+
+		newInflection.PolishWord, err = prepareWordWithId(ctx, conn(ctx, ir.DB), targetPolishWordID)
+		if err != nil {
+			return nil, err
+		}
+
+		return newInflection, nil
+	})
+}
+
+func (ir *InflectionRepositoryDB) DeleteInflection(ctx context.Context, id string) (*model.Inflection, error) {
+	deleted := &model.Inflection{ID: id}
+
+	err := conn(ctx, ir.DB).QueryRowContext(ctx,
+		"DELETE FROM inflections WHERE id = $1 AND polish_word_id IN (SELECT id FROM lexemes WHERE "+visibleTo(ctx, "")+") RETURNING polish_word_id, "+inflectionColumns+", version", id).
+		Scan(append(append([]any{&deleted.PolishWordID}, inflectionFields(deleted)...), &deleted.Version)...)
+	if err != nil {
+		return nil, notFoundOr(err, "inflection", "id", id)
+	}
+
+	return deleted, nil
+}
+
+func (ir *InflectionRepositoryDB) UpdateInflection(ctx context.Context, id string, edits model.EditInflectionInput) (*model.Inflection, error) {
+	return inTx(ctx, ir.DB, func(ctx context.Context) (*model.Inflection, error) {
+		inflection := &model.Inflection{ID: id}
+
+		err := conn(ctx, ir.DB).QueryRowContext(ctx,
+			"SELECT polish_word_id, "+inflectionColumns+", version FROM inflections WHERE id = $1 AND polish_word_id IN (SELECT id FROM lexemes WHERE "+visibleTo(ctx, "")+")", id).
+			Scan(append(append([]any{&inflection.PolishWordID}, inflectionFields(inflection)...), &inflection.Version)...)
+		if err != nil {
+			return nil, notFoundOr(err, "inflection", "id", id)
+		}
+
+		applyInflectionEdits(inflection, &edits)
+		if err := validateInflection(inflection); err != nil {
+			return nil, err
+		}
+
+		result, err := conn(ctx, ir.DB).ExecContext(ctx,
+			"UPDATE inflections SET form = $1, grammatical_case = $2, number = $3, person = $4, tense = $5, gender = $6, version = version + 1 WHERE id = $7 AND version = $8",
+			append(inflectionValues(inflection), id, edits.Version)...)
+		if err != nil {
+			return nil, dbError(err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return nil, dbError(err)
+		}
+		if rowsAffected == 0 {
+			return nil, versionConflict(ctx, conn(ctx, ir.DB), "inflections", "inflection", id, edits.Version)
+		}
+
+		inflection.Version = edits.Version + 1
+
+		return inflection, nil
+	})
+}
+
+func (ir *InflectionRepositoryDB) GetInflectionsByPolishWordIDs(ctx context.Context, polishWordIDs []string) (map[string][]*model.Inflection, error) {
+	rows, err := conn(ctx, ir.DB).QueryContext(ctx,
+		"SELECT id, polish_word_id, "+inflectionColumns+", version FROM inflections WHERE polish_word_id = ANY($1::int[]) ORDER BY id",
+		pq.Array(polishWordIDs))
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	inflections := make(map[string][]*model.Inflection, len(polishWordIDs))
+	for _, polishWordID := range polishWordIDs {
+		inflections[polishWordID] = []*model.Inflection{}
+	}
+
+	for rows.Next() {
+		var inflection model.Inflection
+		if err := rows.Scan(append(append([]any{&inflection.ID, &inflection.PolishWordID}, inflectionFields(&inflection)...), &inflection.Version)...); err != nil {
+			return nil, dbError(err)
+		}
+		inflections[inflection.PolishWordID] = append(inflections[inflection.PolishWordID], &inflection)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, dbError(err)
+	}
+
+	return inflections, nil
+}
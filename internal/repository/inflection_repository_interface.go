@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+)
+
+type InflectionRepositoryInterface interface {
+	AddInflection(ctx context.Context, polishWordID *string, polishWord *string, inflection model.AddInflectionInput) (*model.Inflection, error)
+	DeleteInflection(ctx context.Context, id string) (*model.Inflection, error)
+	UpdateInflection(ctx context.Context, id string, edits model.EditInflectionInput) (*model.Inflection, error)
+	GetInflectionsByPolishWordIDs(ctx context.Context, polishWordIDs []string) (map[string][]*model.Inflection, error)
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// The polishWord and englishWord API is a view of the lexemes in polishLanguage
+// and their translations into englishLanguage.
+const (
+	polishLanguage  = "pl"
+	englishLanguage = "en"
+
+	// notDeleted leaves out rows that are in the trash.
+	notDeleted = "deleted_at IS NULL"
+
+	// polishLexemesOnly restricts queries on lexemes to that view.
+	polishLexemesOnly = "language = 'pl' AND " + notDeleted
+
+	maxLanguageLength = 35
+)
+
+// languageTagPattern accepts the shape of a BCP-47 tag: a primary language
+// followed by script, region, variant and extension subtags.
+var languageTagPattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{1,8})*$`)
+
+// normalizeLanguage checks tag and writes it in the canonical case, so that
+// "PT-br" and "pt-BR" are stored as the same language.
+func normalizeLanguage(field string, tag string) (string, error) {
+	if len(tag) > maxLanguageLength || !languageTagPattern.MatchString(tag) {
+		return "", validationError(field, fmt.Sprintf("%q is not a BCP-47 language tag", tag))
+	}
+
+	subtags := strings.Split(tag, "-")
+	for i, subtag := range subtags {
+		switch {
+		case i > 0 && len(subtag) == 2 && isLetters(subtag):
+			subtags[i] = strings.ToUpper(subtag)
+		case i > 0 && len(subtag) == 4 && isLetters(subtag):
+			subtags[i] = strings.ToUpper(subtag[:1]) + strings.ToLower(subtag[1:])
+		default:
+			subtags[i] = strings.ToLower(subtag)
+		}
+	}
+
+	return strings.Join(subtags, "-"), nil
+}
+
+// translationLanguage defaults to English, so clients of the Polish–English
+// API do not have to name a language.
+func translationLanguage(language *string) (string, error) {
+	if language == nil {
+		return englishLanguage, nil
+	}
+	return normalizeLanguage("language", *language)
+}
+
+func isLetters(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
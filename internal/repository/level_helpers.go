@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/lib/pq"
+)
+
+const levelColumns = "cefr_level, frequency_rank"
+
+// Level is how difficult and how common a word or translation is.
+type Level struct {
+	CefrLevel     *model.CefrLevel
+	FrequencyRank *int
+}
+
+// validateFrequencyRank accepts positive ranks, and 0 too when editing, where
+// it clears the rank.
+func validateFrequencyRank(rank *int, editing bool) error {
+	if rank == nil || *rank > 0 || (editing && *rank == 0) {
+		return nil
+	}
+	return validationError("frequencyRank", "frequencyRank must be positive")
+}
+
+// validateLevel checks a level that did not come through GraphQL, which
+// would have rejected unknown CEFR levels already.
+func validateLevel(cefrLevel *model.CefrLevel, frequencyRank *int) error {
+	if cefrLevel != nil && !cefrLevel.IsValid() {
+		return validationError("cefrLevel", fmt.Sprintf("unknown CEFR level %q", *cefrLevel))
+	}
+	return validateFrequencyRank(frequencyRank, false)
+}
+
+func hasLevelEdits(cefrLevel *model.CefrLevel, frequencyRank *int) bool {
+	return cefrLevel != nil || frequencyRank != nil
+}
+
+// setLevel stores the level given when an entry is added. An entry that
+// already existed keeps the fields that are not given.
+func setLevel(ctx context.Context, db DBTX, table string, id string, cefrLevel *model.CefrLevel, frequencyRank *int) error {
+	if !hasLevelEdits(cefrLevel, frequencyRank) {
+		return nil
+	}
+
+	_, err := db.ExecContext(ctx,
+		"UPDATE "+table+" SET cefr_level = COALESCE($1, cefr_level), frequency_rank = COALESCE($2, frequency_rank) WHERE id = $3",
+		cefrLevel, frequencyRank, id)
+	if err != nil {
+		return dbError(err)
+	}
+	return nil
+}
+
+// levelUpsertUpdate extends the DO UPDATE of an upsert into table like
+// setLevel: an existing row takes the level given and keeps the fields that
+// are not.
+func levelUpsertUpdate(table string) string {
+	return ", cefr_level = COALESCE(EXCLUDED.cefr_level, " + table + ".cefr_level), frequency_rank = COALESCE(EXCLUDED.frequency_rank, " + table + ".frequency_rank)"
+}
+
+func getLevelsByIDs(ctx context.Context, db DBTX, table string, ids []string) (map[string]*Level, error) {
+	rows, err := db.QueryContext(ctx, "SELECT id, "+levelColumns+" FROM "+table+" WHERE id = ANY($1::int[])", pq.Array(ids))
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	levels := make(map[string]*Level, len(ids))
+	for _, id := range ids {
+		levels[id] = &Level{}
+	}
+
+	for rows.Next() {
+		var id string
+		var level Level
+		if err := rows.Scan(&id, &level.CefrLevel, &level.FrequencyRank); err != nil {
+			return nil, dbError(err)
+		}
+		levels[id] = &level
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, dbError(err)
+	}
+
+	return levels, nil
+}
+
+// levelFilterConditions restricts lexemes to the levels in filter,
+// numbering placeholders after the len(args) arguments already bound.
+func levelFilterConditions(filter *model.PolishWordFilter, args []any) ([]string, []any) {
+	var conditions []string
+
+	if len(filter.CefrLevels) > 0 {
+		levels := make([]string, len(filter.CefrLevels))
+		for i, level := range filter.CefrLevels {
+			levels[i] = level.String()
+		}
+		args = append(args, pq.Array(levels))
+		conditions = append(conditions, fmt.Sprintf("cefr_level = ANY($%d::text[])", len(args)))
+	}
+
+	if filter.MaxFrequencyRank != nil {
+		args = append(args, *filter.MaxFrequencyRank)
+		conditions = append(conditions, fmt.Sprintf("frequency_rank <= $%d", len(args)))
+	}
+
+	return conditions, args
+}
+
+// polishWordOrderClause sorts by the level column, with id breaking ties.
+// CEFR levels sort correctly as text.
+func polishWordOrderClause(orderBy *model.PolishWordOrder) string {
+	if orderBy == nil {
+		return ""
+	}
+
+	switch *orderBy {
+	case model.PolishWordOrderFrequencyRank:
+		return " ORDER BY frequency_rank ASC NULLS LAST, id"
+	case model.PolishWordOrderCefrLevel:
+		return " ORDER BY cefr_level ASC NULLS LAST, id"
+	}
+	return ""
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+)
+
+const lexemeColumns = "id, language, word, version"
+
+// lexemeFields returns scan destinations matching lexemeColumns.
+func lexemeFields(l *model.Lexeme) []any {
+	return []any{&l.ID, &l.Language, &l.Lemma, &l.Version}
+}
+
+func (lr *LexemeRepositoryDB) fetchLexemeByID(ctx context.Context, id string) (*model.Lexeme, error) {
+	var lexeme model.Lexeme
+
+	err := conn(ctx, lr.DB).QueryRowContext(ctx, "SELECT "+lexemeColumns+" FROM lexemes WHERE id = $1 AND "+notDeleted+" AND "+visibleTo(ctx, ""), id).
+		Scan(lexemeFields(&lexeme)...)
+	if err != nil {
+		return nil, notFoundOr(err, "lexeme", "id", id)
+	}
+
+	return &lexeme, nil
+}
+
+func (lr *LexemeRepositoryDB) addTranslation(ctx context.Context, lexeme *model.Lexeme, input *model.AddLexemeTranslationInput) (*model.Translation, error) {
+	translation, err := lexemeTranslationInput(lexeme.Language, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return lr.TranslationRepo.AddTranslation(ctx, &lexeme.ID, &lexeme.Lemma, translation)
+}
+
+// lexemeTranslationInput turns the per-language texts of the example
+// sentences into the sentence_pl and sentence_en pair the tables store: the
+// text in the lexeme's language and the text in the translation's language.
+// Texts in further languages are passed on as they are.
+func lexemeTranslationInput(lexemeLanguage string, input *model.AddLexemeTranslationInput) (*model.AddTranslationInput, error) {
+	language, err := normalizeLanguage("language", input.Language)
+	if err != nil {
+		return nil, err
+	}
+
+	if language == lexemeLanguage {
+		return nil, validationError("language", "a translation must be in a different language than its lexeme")
+	}
+
+	translation := &model.AddTranslationInput{
+		EnglishWord:      input.Text,
+		Language:         &language,
+		PartOfSpeech:     input.PartOfSpeech,
+		Gender:           input.Gender,
+		Aspect:           input.Aspect,
+		AspectPartner:    input.AspectPartner,
+		UsageNote:        input.UsageNote,
+		ExampleSentences: []*model.AddExampleSentenceInput{},
+	}
+
+	for _, es := range input.ExampleSentences {
+		texts := make(map[string]string, len(es.Texts))
+		var further []*model.SentenceTextInput
+		for _, text := range es.Texts {
+			textLanguage, err := normalizeLanguage("texts", text.Language)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := texts[textLanguage]; ok {
+				return nil, validationError("texts", fmt.Sprintf("an example sentence has more than one %s text", textLanguage))
+			}
+			texts[textLanguage] = text.Text
+			if textLanguage != lexemeLanguage && textLanguage != language {
+				further = append(further, &model.SentenceTextInput{Language: textLanguage, Text: text.Text})
+			}
+		}
+
+		lexemeText, hasLexemeText := texts[lexemeLanguage]
+		translationText, hasTranslationText := texts[language]
+		if !hasLexemeText || !hasTranslationText {
+			return nil, validationError("texts", fmt.Sprintf("an example sentence needs a %s and a %s text", lexemeLanguage, language))
+		}
+
+		translation.ExampleSentences = append(translation.ExampleSentences, &model.AddExampleSentenceInput{
+			SentencePl: lexemeText,
+			SentenceEn: translationText,
+			Texts:      further,
+		})
+	}
+
+	return translation, nil
+}
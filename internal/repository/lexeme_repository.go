@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/lib/pq"
+)
+
+type LexemeRepositoryDB struct {
+	DB              *sql.DB
+	TranslationRepo *TranslationRepositoryDB
+}
+
+// AddLexeme works like AddPolishWord for any language. No pronunciation is
+// generated, since the generator only knows Polish.
+func (lr *LexemeRepositoryDB) AddLexeme(ctx context.Context, lexeme model.AddLexemeInput) (*model.Lexeme, error) {
+	language, err := normalizeLanguage("language", lexeme.Language)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateWord("lemma", lexeme.Lemma); err != nil {
+		return nil, err
+	}
+
+	return inTx(ctx, lr.DB, func(ctx context.Context) (*model.Lexeme, error) {
+		newLexeme := &model.Lexeme{
+			Language:     language,
+			Lemma:        lexeme.Lemma,
+			Translations: []*model.Translation{},
+		}
+
+		err := conn(ctx, lr.DB).QueryRowContext(ctx, `
+			INSERT INTO lexemes (language, word)
+			VALUES ($1, $2)
+			`+polishWordUpsertConflict+`
+			RETURNING id, version`, language, lexeme.Lemma).
+			Scan(&newLexeme.ID, &newLexeme.Version)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to upsert lexeme: %w", err)
+		}
+
+		for _, t := range lexeme.Translations {
+			newTranslation, err := lr.addTranslation(ctx, newLexeme, t)
+			if err != nil {
+				return nil, err
+			}
+
+			newLexeme.Translations = append(newLexeme.Translations, newTranslation)
+		}
+
+		return newLexeme, nil
+	})
+}
+
+func (lr *LexemeRepositoryDB) DeleteLexeme(ctx context.Context, id string) (*model.Lexeme, error) {
+	return inTx(ctx, lr.DB, func(ctx context.Context) (*model.Lexeme, error) {
+		if err := authorizeDelete(ctx, conn(ctx, lr.DB), ownsPolishWordTree, "lexeme", id); err != nil {
+			return nil, err
+		}
+
+		translations, err := lr.TranslationRepo.GetTranslationsByPolishWordIDs(ctx, []string{id})
+		if err != nil {
+			return nil, err
+		}
+
+		deletedLexeme := model.Lexeme{Translations: translations[id]}
+
+		if err := trashTranslationsOf(ctx, conn(ctx, lr.DB), id); err != nil {
+			return nil, err
+		}
+
+		err = conn(ctx, lr.DB).QueryRowContext(ctx, "UPDATE lexemes SET deleted_at = now() WHERE id = $1 AND "+notDeleted+" AND "+visibleTo(ctx, "")+" RETURNING "+lexemeColumns, id).
+			Scan(lexemeFields(&deletedLexeme)...)
+		if err != nil {
+			return nil, notFoundOr(err, "lexeme", "id", id)
+		}
+
+		return &deletedLexeme, nil
+	})
+}
+
+func (lr *LexemeRepositoryDB) AddLexemeTranslation(ctx context.Context, lexemeID string, translation model.AddLexemeTranslationInput) (*model.Translation, error) {
+	return inTx(ctx, lr.DB, func(ctx context.Context) (*model.Translation, error) {
+		lexeme, err := lr.fetchLexemeByID(ctx, lexemeID)
+		if err != nil {
+			return nil, err
+		}
+
+		return lr.addTranslation(ctx, lexeme, &translation)
+	})
+}
+
+func (lr *LexemeRepositoryDB) GetLexeme(ctx context.Context, id *string, language *string, lemma *string) (*model.Lexeme, error) {
+	if id != nil {
+		return lr.fetchLexemeByID(ctx, *id)
+	}
+
+	if language == nil || lemma == nil {
+		return nil, validationError("", "either id or language and lemma must be provided")
+	}
+
+	normalized, err := normalizeLanguage("language", *language)
+	if err != nil {
+		return nil, err
+	}
+
+	var lexeme model.Lexeme
+	err = conn(ctx, lr.DB).QueryRowContext(ctx, "SELECT "+lexemeColumns+" FROM lexemes WHERE language = $1 AND word = $2 AND "+notDeleted+" AND "+visibleTo(ctx, "")+" ORDER BY "+privateFirst+" LIMIT 1",
+		normalized, *lemma).Scan(lexemeFields(&lexeme)...)
+	if err != nil {
+		return nil, notFoundOr(err, "lexeme", "lemma", *lemma)
+	}
+
+	return &lexeme, nil
+}
+
+func (lr *LexemeRepositoryDB) GetLexemes(ctx context.Context, language *string) ([]*model.Lexeme, error) {
+	if language != nil {
+		normalized, err := normalizeLanguage("language", *language)
+		if err != nil {
+			return nil, err
+		}
+		language = &normalized
+	}
+
+	rows, err := conn(ctx, lr.DB).QueryContext(ctx,
+		"SELECT "+lexemeColumns+" FROM lexemes WHERE ($1::text IS NULL OR language = $1) AND "+notDeleted+" AND "+visibleTo(ctx, "")+" ORDER BY id", language)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	lexemes := []*model.Lexeme{}
+	for rows.Next() {
+		var lexeme model.Lexeme
+		if err := rows.Scan(lexemeFields(&lexeme)...); err != nil {
+			return nil, dbError(err)
+		}
+		lexemes = append(lexemes, &lexeme)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, dbError(err)
+	}
+
+	return lexemes, nil
+}
+
+func (lr *LexemeRepositoryDB) GetLexemesByIDs(ctx context.Context, ids []string) (map[string]*model.Lexeme, error) {
+	rows, err := conn(ctx, lr.DB).QueryContext(ctx, "SELECT "+lexemeColumns+" FROM lexemes WHERE id = ANY($1::int[]) AND "+notDeleted+" AND "+visibleTo(ctx, ""), pq.Array(ids))
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	lexemes := make(map[string]*model.Lexeme, len(ids))
+	for rows.Next() {
+		var lexeme model.Lexeme
+		if err := rows.Scan(lexemeFields(&lexeme)...); err != nil {
+			return nil, dbError(err)
+		}
+		lexemes[lexeme.ID] = &lexeme
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, dbError(err)
+	}
+
+	return lexemes, nil
+}
+
+// GetTranslationsByText is the language-neutral form of
+// GetTranslationsByEnglishWord.
+func (lr *LexemeRepositoryDB) GetTranslationsByText(ctx context.Context, text string, language string) ([]*model.Translation, error) {
+	normalized, err := normalizeLanguage("language", language)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn(ctx, lr.DB).QueryContext(ctx,
+		"SELECT id, english_word, language, polish_word_id, version, "+translationGrammarColumns+
+			" FROM translations WHERE lower(english_word) = lower($1) AND language = $2 AND "+notDeleted+" AND "+visibleTo(ctx, "")+" ORDER BY id", text, normalized)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	translations := []*model.Translation{}
+	for rows.Next() {
+		var translation model.Translation
+		if err := rows.Scan(append([]any{&translation.ID, &translation.EnglishWord, &translation.Language, &translation.PolishWordID, &translation.Version},
+			translationGrammarFields(&translation)...)...); err != nil {
+			return nil, dbError(err)
+		}
+		translations = append(translations, &translation)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, dbError(err)
+	}
+
+	return translations, nil
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+)
+
+// LexemeRepositoryInterface works on headwords in any language. Lexemes share
+// their ids with Polish words, which are the lexemes in Polish.
+type LexemeRepositoryInterface interface {
+	AddLexeme(ctx context.Context, lexeme model.AddLexemeInput) (*model.Lexeme, error)
+	DeleteLexeme(ctx context.Context, id string) (*model.Lexeme, error)
+	AddLexemeTranslation(ctx context.Context, lexemeID string, translation model.AddLexemeTranslationInput) (*model.Translation, error)
+	GetLexeme(ctx context.Context, id *string, language *string, lemma *string) (*model.Lexeme, error)
+	GetLexemes(ctx context.Context, language *string) ([]*model.Lexeme, error)
+	GetLexemesByIDs(ctx context.Context, ids []string) (map[string]*model.Lexeme, error)
+	GetTranslationsByText(ctx context.Context, text string, language string) ([]*model.Translation, error)
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/apperror"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/auth"
+)
+
+// Entries record the caller who created them in owner, see the column default
+// in migration 0018. Admins may delete any entry; anyone else only an entry
+// they own, and only when they also own everything that would be deleted
+// along with it.
+
+const (
+	ownsPolishWordTree = `
+		SELECT COALESCE(p.owner = $2, false)
+			AND NOT EXISTS (SELECT 1 FROM translations t
+				WHERE t.polish_word_id = p.id AND t.deleted_at IS NULL AND t.owner IS DISTINCT FROM $2)
+			AND NOT EXISTS (SELECT 1 FROM example_sentences es JOIN translations t ON t.id = es.translation_id
+				WHERE t.polish_word_id = p.id AND t.deleted_at IS NULL AND es.deleted_at IS NULL AND es.owner IS DISTINCT FROM $2)
+		FROM lexemes p
+		WHERE p.id = $1 AND p.deleted_at IS NULL
+		FOR UPDATE OF p`
+
+	ownsTranslationTree = `
+		SELECT COALESCE(t.owner = $2, false)
+			AND NOT EXISTS (SELECT 1 FROM example_sentences es
+				WHERE es.translation_id = t.id AND es.deleted_at IS NULL AND es.owner IS DISTINCT FROM $2)
+		FROM translations t
+		WHERE t.id = $1 AND t.deleted_at IS NULL
+		FOR UPDATE OF t`
+
+	ownsExampleSentence = `
+		SELECT COALESCE(es.owner = $2, false)
+		FROM example_sentences es
+		WHERE es.id = $1 AND es.deleted_at IS NULL
+		FOR UPDATE OF es`
+)
+
+// authorizeDelete checks that the caller may delete the entity with id, using
+// ownsQuery to tell whether it owns the entry and its dependents. The entry is
+// locked, so no one can add to it before it is deleted.
+func authorizeDelete(ctx context.Context, db DBTX, ownsQuery string, entity string, id string) error {
+	principal := auth.PrincipalFrom(ctx)
+	if principal.HasRole(auth.RoleAdmin) {
+		return nil
+	}
+
+	forbidden := &apperror.ForbiddenError{Action: "delete " + entity + " " + id}
+	if principal == nil {
+		return forbidden
+	}
+
+	var owned bool
+	if err := db.QueryRowContext(ctx, ownsQuery, id, principal.Name).Scan(&owned); err != nil {
+		return notFoundOr(err, entity, "id", id)
+	}
+	if !owned {
+		return forbidden
+	}
+
+	return nil
+}
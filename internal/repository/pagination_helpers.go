@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+
+	polishWordCursorPrefix = "polishword:"
+)
+
+// pageRequest describes a keyset page over an integer id column. Cursors only
+// carry the id of the edge, so pages stay stable when new rows are inserted.
+type pageRequest struct {
+	size     int
+	backward bool
+	afterID  *int
+	beforeID *int
+}
+
+func newPageRequest(first *int, after *string, last *int, before *string, cursorPrefix string) (*pageRequest, error) {
+	if first != nil && last != nil {
+		return nil, validationError("", "first and last cannot be used together")
+	}
+
+	page := &pageRequest{size: defaultPageSize}
+
+	if first != nil {
+		if *first < 0 {
+			return nil, validationError("first", "first must not be negative")
+		}
+		page.size = *first
+	}
+
+	if last != nil {
+		if *last < 0 {
+			return nil, validationError("last", "last must not be negative")
+		}
+		page.size = *last
+		page.backward = true
+	} else if first == nil && before != nil && after == nil {
+		page.backward = true
+	}
+
+	if page.size > maxPageSize {
+		page.size = maxPageSize
+	}
+
+	if after != nil {
+		id, err := decodeCursor(cursorPrefix, *after)
+		if err != nil {
+			return nil, err
+		}
+		page.afterID = &id
+	}
+
+	if before != nil {
+		id, err := decodeCursor(cursorPrefix, *before)
+		if err != nil {
+			return nil, err
+		}
+		page.beforeID = &id
+	}
+
+	return page, nil
+}
+
+// buildQuery appends the keyset conditions, ordering and limit to selectFrom.
+// One extra row is requested so the caller can tell whether more rows exist.
+func (p *pageRequest) buildQuery(selectFrom string, column string, conditions []string, args []any) (string, []any) {
+	if p.afterID != nil {
+		args = append(args, *p.afterID)
+		conditions = append(conditions, fmt.Sprintf("%s > $%d", column, len(args)))
+	}
+
+	if p.beforeID != nil {
+		args = append(args, *p.beforeID)
+		conditions = append(conditions, fmt.Sprintf("%s < $%d", column, len(args)))
+	}
+
+	query := selectFrom
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	order := "ASC"
+	if p.backward {
+		order = "DESC"
+	}
+
+	args = append(args, p.size+1)
+	query += fmt.Sprintf(" ORDER BY %s %s LIMIT $%d", column, order, len(args))
+
+	return query, args
+}
+
+func encodeCursor(prefix string, id string) string {
+	return base64.StdEncoding.EncodeToString([]byte(prefix + id))
+}
+
+func decodeCursor(prefix string, cursor string) (int, error) {
+	raw, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), prefix) {
+		return 0, validationError("cursor", fmt.Sprintf("invalid cursor %q", cursor))
+	}
+
+	id, err := strconv.Atoi(strings.TrimPrefix(string(raw), prefix))
+	if err != nil {
+		return 0, validationError("cursor", fmt.Sprintf("invalid cursor %q", cursor))
+	}
+
+	return id, nil
+}
@@ -3,48 +3,169 @@ package repository
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/apperror"
 	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/lib/pq"
 )
 
 func (pwr *PolishWordRepositoryDB) fetchPolishWords(ctx context.Context, id *string, word *string) (*model.PolishWord, error) {
 
 	var fetchedPolishWord model.PolishWord
 	if id != nil {
-		err := pwr.DB.QueryRowContext(ctx, "SELECT id, word, version FROM polish_words WHERE id = $1",
+		err := conn(ctx, pwr.DB).QueryRowContext(ctx, "SELECT id, word, version FROM lexemes WHERE id = $1 AND "+polishLexemesOnly+" AND "+visibleTo(ctx, ""),
 			*id).Scan(&fetchedPolishWord.ID, &fetchedPolishWord.Word, &fetchedPolishWord.Version)
 		if err != nil {
-			return nil, err
+			return nil, notFoundOr(err, "polish word", "id", *id)
 		}
 	} else if word != nil {
-		err := pwr.DB.QueryRowContext(ctx, "SELECT id, word, version FROM polish_words WHERE word = $1",
+		err := conn(ctx, pwr.DB).QueryRowContext(ctx, "SELECT id, word, version FROM lexemes WHERE word = $1 AND "+polishLexemesOnly+" AND "+visibleTo(ctx, "")+" ORDER BY "+privateFirst+" LIMIT 1",
 			*word).Scan(&fetchedPolishWord.ID, &fetchedPolishWord.Word, &fetchedPolishWord.Version)
 		if err != nil {
-			return nil, err
+			return nil, notFoundOr(err, "polish word", "word", *word)
 		}
 	} else {
-		return nil, fmt.Errorf("either id or word must be provided")
+		return nil, validationError("", "either id or word must be provided")
 	}
 
 	return &fetchedPolishWord, nil
 }
 
+// fetchPolishWordByLemma tries the lemmas of form in the lemmatizer's order
+// of preference.
+func (pwr *PolishWordRepositoryDB) fetchPolishWordByLemma(ctx context.Context, form string) (*model.PolishWord, error) {
+	var lemmas []string
+	if pwr.Lemmatizer != nil {
+		for _, lemma := range pwr.Lemmatizer.Lemmas(form) {
+			if lemma != form {
+				lemmas = append(lemmas, lemma)
+			}
+		}
+	}
+
+	if len(lemmas) == 0 {
+		return nil, &apperror.NotFoundError{Entity: "polish word", Field: "word", Value: form}
+	}
+
+	var pw model.PolishWord
+	err := conn(ctx, pwr.DB).QueryRowContext(ctx, `
+		SELECT id, word, version
+		FROM lexemes
+		WHERE word = ANY($1::text[]) AND `+polishLexemesOnly+` AND `+visibleTo(ctx, "")+`
+		ORDER BY array_position($1::text[], word), `+privateFirst+`
+		LIMIT 1`, pq.Array(lemmas)).Scan(&pw.ID, &pw.Word, &pw.Version)
+	if err != nil {
+		return nil, notFoundOr(err, "polish word", "word", form)
+	}
+
+	return &pw, nil
+}
+
+// fetchPolishWordByForm finds the lemma of an inflected form. A form shared by
+// several lemmas resolves to the caller's private one, or else the oldest.
+func (pwr *PolishWordRepositoryDB) fetchPolishWordByForm(ctx context.Context, form string) (*model.PolishWord, error) {
+	var pw model.PolishWord
+
+	err := conn(ctx, pwr.DB).QueryRowContext(ctx, `
+		SELECT p.id, p.word, p.version
+		FROM inflections i
+		JOIN lexemes p ON p.id = i.polish_word_id
+		WHERE i.form = $1 AND p.language = 'pl' AND p.deleted_at IS NULL AND `+visibleTo(ctx, "p")+`
+		ORDER BY p.`+privateFirst+`, p.id
+		LIMIT 1`, form).Scan(&pw.ID, &pw.Word, &pw.Version)
+	if err != nil {
+		return nil, notFoundOr(err, "polish word", "word", form)
+	}
+
+	return &pw, nil
+}
+
+// editedPronunciation applies the pronunciation edits. A renamed word starts
+// from scratch, so fields that are not given are generated for the new
+// spelling or cleared.
+func (pwr *PolishWordRepositoryDB) editedPronunciation(ctx context.Context, pw *model.PolishWord, edits *model.EditPolishWordInput, renamed bool) (*model.Pronunciation, error) {
+	var p model.Pronunciation
+	word := pw.Word
+
+	if renamed {
+		word = *edits.Word
+	} else {
+		err := conn(ctx, pwr.DB).QueryRowContext(ctx, "SELECT "+pronunciationColumns+" FROM lexemes WHERE id = $1",
+			pw.ID).Scan(pronunciationFields(&p)...)
+		if err != nil {
+			return nil, notFoundOr(err, "polish word", "id", pw.ID)
+		}
+	}
+
+	applyPronunciationEdits(&p, edits.Pronunciation)
+	if renamed {
+		generatePronunciation(pwr.Pronunciation, word, &p)
+	}
+
+	if err := validatePronunciation(word, &p); err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
 func (pwr *PolishWordRepositoryDB) updateTranslations(
 	ctx context.Context,
 	polishWordID string,
 	editTranslations []*model.EditTranslationInput,
+	remove []string,
 ) ([]*model.Translation, error) {
 
+	editIDs := make([]*string, len(editTranslations))
+	for i, editTr := range editTranslations {
+		editIDs[i] = editTr.ID
+	}
+	if err := checkNestedEditIDs(editIDs, remove, "translations"); err != nil {
+		return nil, err
+	}
+
 	currentTranslationsFromDB, err := pwr.getCurrentTranslationsFromDB(ctx, polishWordID)
 	if err != nil {
 		return nil, err
 	}
 
-	for i, editTr := range editTranslations {
+	currentTranslationsFromDB, err = withoutRemoved(currentTranslationsFromDB,
+		func(t *model.Translation) string { return t.ID }, remove, "translation")
+	if err != nil {
+		return nil, err
+	}
 
-		if i < len(currentTranslationsFromDB) {
+	// Removing a translation deletes it, so it takes the same rights as
+	// deleteTranslation.
+	for _, id := range remove {
+		if err := authorizeDelete(ctx, conn(ctx, pwr.DB), ownsTranslationTree, "translation", id); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(remove) > 0 {
+		if err := trashTranslations(ctx, conn(ctx, pwr.DB), polishWordID, remove); err != nil {
+			return nil, err
+		}
+	}
+
+	translationsByID := make(map[string]*model.Translation, len(currentTranslationsFromDB))
+	for _, t := range currentTranslationsFromDB {
+		translationsByID[t.ID] = t
+	}
+
+	for _, editTr := range editTranslations {
+
+		if editTr.ID != nil {
+
+			translation, ok := translationsByID[*editTr.ID]
+			if !ok {
+				return nil, &apperror.NotFoundError{Entity: "translation", Field: "id", Value: *editTr.ID}
+			}
 
-			err := UpdateSingleTranslation(ctx, pwr.DB, currentTranslationsFromDB[i], editTr)
+			err := UpdateSingleTranslation(ctx, conn(ctx, pwr.DB), translation, editTr)
 
 			if err != nil {
 				return nil, err
@@ -65,39 +186,12 @@ func (pwr *PolishWordRepositoryDB) updateTranslations(
 
 }
 
-func (pwr *PolishWordRepositoryDB) insertExampleSentences(
-	ctx context.Context,
-	translationID string,
-	editExamples []*model.EditExampleSentenceInput,
-) ([]*model.ExampleSentence, error) {
-
-	var exampleSentences []*model.ExampleSentence
-	for _, editEs := range editExamples {
-		var newExampleSentenceID string
-		err := pwr.DB.QueryRowContext(ctx,
-			"INSERT INTO example_sentences (sentence_pl, sentence_en, translation_id) VALUES ($1, $2, $3) RETURNING id",
-			editEs.SentencePl, editEs.SentenceEn, translationID).Scan(&newExampleSentenceID)
-
-		if err != nil {
-			return nil, err
-		}
-
-		exampleSentences = append(exampleSentences, &model.ExampleSentence{
-			ID:         newExampleSentenceID,
-			SentencePl: *editEs.SentencePl,
-			SentenceEn: *editEs.SentenceEn,
-		})
-	}
-
-	return exampleSentences, nil
-}
-
 func (pwr *PolishWordRepositoryDB) getCurrentTranslationsFromDB(
 	ctx context.Context,
 	polishWordID string,
 ) ([]*model.Translation, error) {
-	rows, err := pwr.DB.QueryContext(ctx,
-		"SELECT id, english_word, version FROM translations WHERE polish_word_id = $1 ORDER BY id", polishWordID)
+	rows, err := conn(ctx, pwr.DB).QueryContext(ctx,
+		"SELECT id, english_word, language, version, "+translationGrammarColumns+" FROM translations WHERE polish_word_id = $1 AND "+notDeleted+" AND "+visibleTo(ctx, "")+" ORDER BY id", polishWordID)
 
 	if err != nil {
 		return nil, err
@@ -106,8 +200,8 @@ func (pwr *PolishWordRepositoryDB) getCurrentTranslationsFromDB(
 
 	var currentTranslationsFromDB []*model.Translation
 	for rows.Next() {
-		var t model.Translation
-		if err := rows.Scan(&t.ID, &t.EnglishWord, &t.Version); err != nil {
+		t := model.Translation{PolishWordID: polishWordID}
+		if err := rows.Scan(append([]any{&t.ID, &t.EnglishWord, &t.Language, &t.Version}, translationGrammarFields(&t)...)...); err != nil {
 			return nil, err
 		}
 		currentTranslationsFromDB = append(currentTranslationsFromDB, &t)
@@ -122,26 +216,49 @@ func (pwr *PolishWordRepositoryDB) insertTranslation(
 	editTr *model.EditTranslationInput) (*model.Translation, error) {
 
 	if editTr.EnglishWord == nil {
-		return nil, fmt.Errorf("EnglishWord is required for inserting a new translation")
+		return nil, validationError("englishWord", "englishWord is required for inserting a new translation")
 	}
 
-	var newTranslationID string
-	err := pwr.DB.QueryRowContext(ctx,
-		"INSERT INTO translations (english_word, polish_word_id) VALUES ($1, $2) RETURNING id",
-		*editTr.EnglishWord, polishWordID).Scan(&newTranslationID)
-
+	language, err := translationLanguage(editTr.Language)
 	if err != nil {
 		return nil, err
 	}
 
 	newTranslation := &model.Translation{
-		ID:               newTranslationID,
 		EnglishWord:      *editTr.EnglishWord,
+		Language:         language,
 		ExampleSentences: []*model.ExampleSentence{},
+		PolishWordID:     polishWordID,
+	}
+
+	applyTranslationGrammarEdits(newTranslation, editTr)
+	if err := validateTranslationGrammar(newTranslation); err != nil {
+		return nil, err
+	}
+	if err := validateFrequencyRank(editTr.FrequencyRank, true); err != nil {
+		return nil, err
+	}
+
+	err = conn(ctx, pwr.DB).QueryRowContext(ctx,
+		"INSERT INTO translations (english_word, language, polish_word_id, "+translationGrammarColumns+", visibility) "+
+			"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, COALESCE($9, (SELECT visibility FROM lexemes WHERE id = $3))) RETURNING id, version",
+		append(append([]any{newTranslation.EnglishWord, language, polishWordID}, translationGrammarValues(newTranslation)...), visibilityValue(editTr.Visibility))...).
+		Scan(&newTranslation.ID, &newTranslation.Version)
+
+	if err != nil {
+		return nil, err
+	}
+
+	newTranslation.CefrLevel = editTr.CefrLevel
+	if editTr.FrequencyRank != nil {
+		newTranslation.FrequencyRank = nilIfZero(*editTr.FrequencyRank)
+	}
+	if err := setLevel(ctx, conn(ctx, pwr.DB), "translations", newTranslation.ID, newTranslation.CefrLevel, newTranslation.FrequencyRank); err != nil {
+		return nil, err
 	}
 
-	if editTr.ExampleSentences != nil {
-		exampleSentences, err := pwr.insertExampleSentences(ctx, newTranslationID, editTr.ExampleSentences)
+	if editTr.ExampleSentences != nil || editTr.Remove != nil {
+		exampleSentences, err := UpdateExampleSentences(ctx, conn(ctx, pwr.DB), newTranslation.ID, editTr.ExampleSentences, editTr.Remove)
 
 		if err != nil {
 			return nil, err
@@ -154,7 +271,8 @@ func (pwr *PolishWordRepositoryDB) insertTranslation(
 }
 
 func (pwr *PolishWordRepositoryDB) getTranslationsWithExampleSentences(ctx context.Context, polishWordID string) ([]*model.Translation, error) {
-	rows, err := pwr.DB.QueryContext(ctx, "SELECT id, english_word, version FROM translations WHERE polish_word_id = $1 ORDER BY id", polishWordID)
+	rows, err := conn(ctx, pwr.DB).QueryContext(ctx,
+		"SELECT id, english_word, language, version, "+translationGrammarColumns+" FROM translations WHERE polish_word_id = $1 AND "+notDeleted+" AND "+visibleTo(ctx, "")+" ORDER BY id", polishWordID)
 	if err != nil {
 		return nil, err
 	}
@@ -163,21 +281,73 @@ func (pwr *PolishWordRepositoryDB) getTranslationsWithExampleSentences(ctx conte
 	var translations []*model.Translation
 	for rows.Next() {
 
-		var tr model.Translation
-		if err := rows.Scan(&tr.ID, &tr.EnglishWord, &tr.Version); err != nil {
+		tr := model.Translation{PolishWordID: polishWordID}
+		if err := rows.Scan(append([]any{&tr.ID, &tr.EnglishWord, &tr.Language, &tr.Version}, translationGrammarFields(&tr)...)...); err != nil {
 			return nil, err
 		}
 
-		examples, err := GetCurrentExampleSentencesFromDB(ctx, pwr.DB, tr.ID)
+		translations = append(translations, &tr)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Inside a transaction every query shares one connection, which cannot
+	// run another query until the translations have been read.
+	rows.Close()
+
+	for _, tr := range translations {
+		examples, err := GetCurrentExampleSentencesFromDB(ctx, conn(ctx, pwr.DB), tr.ID)
 		if err != nil {
 			return nil, err
 		}
 		tr.ExampleSentences = examples
+	}
+	return translations, nil
+}
 
-		translations = append(translations, &tr)
+// polishWordsExistBeyond reports whether rows matching the filter conditions
+// exist on the other side of a page edge. keyset is a condition on id with a
+// %d verb for its placeholder number.
+func (pwr *PolishWordRepositoryDB) polishWordsExistBeyond(ctx context.Context, conditions []string, args []any, keyset string, id int) (bool, error) {
+	args = append(slices.Clone(args), id)
+	conditions = append(slices.Clone(conditions), fmt.Sprintf(keyset, len(args)))
+
+	var exists bool
+	err := conn(ctx, pwr.DB).QueryRowContext(ctx,
+		"SELECT EXISTS(SELECT 1 FROM lexemes WHERE "+strings.Join(conditions, " AND ")+")", args...).Scan(&exists)
+	if err != nil {
+		return false, err
 	}
-	if err = rows.Err(); err != nil {
-		return nil, err
+
+	return exists, nil
+}
+
+// polishWordFilterConditions turns filter into conditions on lexemes,
+// numbering placeholders from $1. Only Polish lexemes the caller may see are
+// listed.
+func polishWordFilterConditions(ctx context.Context, filter *model.PolishWordFilter) ([]string, []any) {
+	conditions := []string{polishLexemesOnly, visibleTo(ctx, "")}
+	var args []any
+
+	if filter == nil {
+		return conditions, args
 	}
-	return translations, nil
+
+	if filter.PartOfSpeech != nil {
+		args = append(args, *filter.PartOfSpeech)
+		conditions = append(conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM translations t WHERE t.polish_word_id = lexemes.id AND t.deleted_at IS NULL AND "+visibleTo(ctx, "t")+" AND t.part_of_speech = $%d)", len(args)))
+	}
+
+	var tagCondition string
+	if tagCondition, args = tagFilterCondition(filter.Tags, filter.TagMatch, args); tagCondition != "" {
+		conditions = append(conditions, tagCondition)
+	}
+
+	var levelConditions []string
+	levelConditions, args = levelFilterConditions(filter, args)
+	conditions = append(conditions, levelConditions...)
+
+	return conditions, args
 }
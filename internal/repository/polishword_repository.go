@@ -3,163 +3,524 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/apperror"
 	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/lemmatizer"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/pronunciation"
+	"github.com/lib/pq"
 )
 
+const polishWordUpsertConflict = "ON CONFLICT (tenant, language, word) WHERE deleted_at IS NULL DO UPDATE SET word = EXCLUDED.word"
+
 type PolishWordRepositoryDB struct {
 	DB              *sql.DB
 	TranslationRepo *TranslationRepositoryDB
+	// Lemmatizer is optional; without it lookups only use the inflection tables.
+	Lemmatizer lemmatizer.Lemmatizer
+	// Pronunciation is optional; without it only pronunciations given in the
+	// input are stored.
+	Pronunciation pronunciation.Generator
 }
 
+// AddPolishWord keeps the stored pronunciation and visibility of a word that
+// already exists.
 func (pwr *PolishWordRepositoryDB) AddPolishWord(ctx context.Context, polishWord model.AddPolishWordInput) (*model.PolishWord, error) {
+	pron := pronunciationFromInput(polishWord.Pronunciation)
+	generatePronunciation(pwr.Pronunciation, polishWord.Word, pron)
+	if err := validatePronunciation(polishWord.Word, pron); err != nil {
+		return nil, err
+	}
+	if err := validateFrequencyRank(polishWord.FrequencyRank, false); err != nil {
+		return nil, err
+	}
 
-	var pw model.PolishWord
+	return inTx(ctx, pwr.DB, func(ctx context.Context) (*model.PolishWord, error) {
+		var pw model.PolishWord
+		var stored model.Pronunciation
 
-	err := pwr.DB.QueryRowContext(ctx, `
-			
-				INSERT INTO polish_words (word)
-				VALUES ($1)
-				ON CONFLICT (word) DO UPDATE SET word = EXCLUDED.word
-				RETURNING id, version
-			
-			
-			`, polishWord.Word).Scan(&pw.ID, &pw.Version)
+		err := conn(ctx, pwr.DB).QueryRowContext(ctx, `
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to upsert polish word: %w", err)
-	}
-
-	pw.Word = polishWord.Word
-	pw.Translations = []*model.Translation{}
+					INSERT INTO lexemes (word, `+pronunciationColumns+`, visibility)
+					VALUES ($1, $2, $3, $4, COALESCE($5, 'public'))
+					`+polishWordUpsertConflict+`
+					RETURNING id, version, `+pronunciationColumns+`
 
-	for _, t := range polishWord.Translations {
 
-		newTranslation, err := pwr.TranslationRepo.AddTranslation(ctx, &pw.ID, &pw.Word, t)
+				`, append(append([]any{polishWord.Word}, pronunciationValues(pron)...), visibilityValue(polishWord.Visibility))...).
+			Scan(append([]any{&pw.ID, &pw.Version}, pronunciationFields(&stored)...)...)
 
 		if err != nil {
+			return nil, fmt.Errorf("failed to upsert polish word: %w", err)
+		}
+
+		pw.Word = polishWord.Word
+		pw.Pronunciation = pronunciationOrNil(&stored)
+		pw.Translations = []*model.Translation{}
+
+		if err := setLevel(ctx, conn(ctx, pwr.DB), "lexemes", pw.ID, polishWord.CefrLevel, polishWord.FrequencyRank); err != nil {
 			return nil, err
 		}
+		pw.CefrLevel = polishWord.CefrLevel
+		pw.FrequencyRank = polishWord.FrequencyRank
 
-		pw.Translations = append(pw.Translations, newTranslation)
-	}
+		for _, t := range polishWord.Translations {
 
-	return &pw, nil
+			newTranslation, err := pwr.TranslationRepo.AddTranslation(ctx, &pw.ID, &pw.Word, t)
+
+			if err != nil {
+				return nil, err
+			}
+
+			pw.Translations = append(pw.Translations, newTranslation)
+		}
+
+		return &pw, nil
+	})
 }
 
 func (pwr *PolishWordRepositoryDB) DeletePolishWord(ctx context.Context, id *string, word *string) (*model.PolishWord, error) {
-	var deletedPolishWord model.PolishWord
+	return inTx(ctx, pwr.DB, func(ctx context.Context) (*model.PolishWord, error) {
+		var deletedPolishWord model.PolishWord
 
-	id, err := pwr.TranslationRepo.getTargetPolishWordID(ctx, id, word)
+		id, err := getTargetPolishWordID(ctx, conn(ctx, pwr.DB), id, word)
 
-	if err != nil {
+		if err != nil {
+			return nil, err
+		}
+
+		deletedPolishWord.ID = *id
+
+		if err := authorizeDelete(ctx, conn(ctx, pwr.DB), ownsPolishWordTree, "polish word", *id); err != nil {
+			return nil, err
+		}
+
+		translations, err := pwr.getTranslationsWithExampleSentences(ctx, *id)
+		if err != nil {
+			return nil, err
+		}
+
+		deletedPolishWord.Translations = translations
+
+		if err := trashTranslationsOf(ctx, conn(ctx, pwr.DB), *id); err != nil {
+			return nil, err
+		}
+
+		err = conn(ctx, pwr.DB).QueryRowContext(ctx, "UPDATE lexemes SET deleted_at = now() WHERE id = $1 AND "+polishLexemesOnly+" AND "+visibleTo(ctx, "")+" RETURNING id, word, version",
+			*id).Scan(id, &deletedPolishWord.Word, &deletedPolishWord.Version)
+		if err != nil {
+			return nil, notFoundOr(err, "polish word", "id", deletedPolishWord.ID)
+		}
+
+		return &deletedPolishWord, nil
+	})
+}
+
+func (pwr *PolishWordRepositoryDB) UpdatePolishWord(ctx context.Context, id *string, word *string, edits *model.EditPolishWordInput) (*model.PolishWord, error) {
+	if edits == nil {
+		return nil, validationError("edits", "edits must be provided")
+	}
+	if err := validateFrequencyRank(edits.FrequencyRank, true); err != nil {
 		return nil, err
 	}
 
-	deletedPolishWord.ID = *id
+	return inTx(ctx, pwr.DB, func(ctx context.Context) (*model.PolishWord, error) {
+		polishWordToEdit, err := pwr.fetchPolishWords(ctx, id, word)
+		if err != nil {
+			return nil, err
+		}
+
+		if edits.Visibility != nil {
+			if err := authorizeVisibilityChange(ctx, conn(ctx, pwr.DB), "lexemes", "polish word", polishWordToEdit.ID); err != nil {
+				return nil, err
+			}
+		}
+
+		renamed := word == nil && edits.Word != nil
+		if renamed || edits.Pronunciation != nil || hasLevelEdits(edits.CefrLevel, edits.FrequencyRank) || edits.Visibility != nil {
+			pron, err := pwr.editedPronunciation(ctx, polishWordToEdit, edits, renamed)
+			if err != nil {
+				return nil, err
+			}
+
+			newWord := polishWordToEdit.Word
+			if renamed {
+				newWord = *edits.Word
+			}
+
+			result, err := conn(ctx, pwr.DB).ExecContext(ctx,
+				`UPDATE lexemes
+				SET word = $1, ipa = $2, syllabification = $3, stressed_syllable = $4,
+					cefr_level = COALESCE($5, cefr_level), frequency_rank = NULLIF(COALESCE($6, frequency_rank), 0),
+					visibility = COALESCE($7, visibility), version = version + 1
+				WHERE id = $8 AND version = $9`,
+				append(append([]any{newWord}, pronunciationValues(pron)...), edits.CefrLevel, edits.FrequencyRank, visibilityValue(edits.Visibility), polishWordToEdit.ID, edits.Version)...)
+
+			if err != nil {
+				return nil, dbError(err)
+			}
+
+			rowsAffected, err := result.RowsAffected()
+			if err != nil {
+				return nil, err
+			}
+			if rowsAffected == 0 {
+				return nil, versionConflict(ctx, conn(ctx, pwr.DB), "lexemes", "polish word", polishWordToEdit.ID, edits.Version)
+			}
+
+			polishWordToEdit.Word = newWord
+			polishWordToEdit.Pronunciation = pronunciationOrNil(pron)
+			polishWordToEdit.CefrLevel = edits.CefrLevel
+			if edits.FrequencyRank != nil {
+				polishWordToEdit.FrequencyRank = nilIfZero(*edits.FrequencyRank)
+			}
+			if edits.Visibility != nil {
+				polishWordToEdit.Visibility = *edits.Visibility
+			}
+			polishWordToEdit.Version = edits.Version + 1
+		}
 
-	translations, err := pwr.getTranslationsWithExampleSentences(ctx, *id)
+		if edits.Translations != nil || edits.Remove != nil {
+			translations, err := pwr.updateTranslations(ctx, polishWordToEdit.ID, edits.Translations, edits.Remove)
+			if err != nil {
+				return nil, err
+			}
+			polishWordToEdit.Translations = translations
+		}
+
+		return polishWordToEdit, nil
+	})
+}
+
+func (pwr *PolishWordRepositoryDB) GetAllPolishWords(ctx context.Context, filter *model.PolishWordFilter, orderBy *model.PolishWordOrder) ([]*model.PolishWord, error) {
+	filterConditions, filterArgs := polishWordFilterConditions(ctx, filter)
+	rows, err := conn(ctx, pwr.DB).QueryContext(ctx,
+		"SELECT id, word, version FROM lexemes WHERE "+strings.Join(filterConditions, " AND ")+polishWordOrderClause(orderBy), filterArgs...)
 	if err != nil {
-		return nil, err
+		return nil, dbError(err)
 	}
+	defer rows.Close()
 
-	deletedPolishWord.Translations = translations
+	var polishWords []*model.PolishWord
+	for rows.Next() {
+		var pw model.PolishWord
 
-	err = pwr.DB.QueryRowContext(ctx, "DELETE FROM polish_words WHERE id = $1 RETURNING id, word, version",
-		*id).Scan(id, &deletedPolishWord.Word, &deletedPolishWord.Version)
-	if err != nil {
-		return nil, err
+		if err := rows.Scan(&pw.ID, &pw.Word, &pw.Version); err != nil {
+			return nil, dbError(err)
+		}
+
+		polishWords = append(polishWords, &pw)
 	}
 
-	return &deletedPolishWord, nil
+	if err = rows.Err(); err != nil {
+		return nil, dbError(err)
+	}
+
+	return polishWords, nil
 }
 
-func (pwr *PolishWordRepositoryDB) UpdatePolishWord(ctx context.Context, id *string, word *string, edits *model.EditPolishWordInput) (*model.PolishWord, error) {
+func (pwr *PolishWordRepositoryDB) GetPolishWordsPage(ctx context.Context, first *int, after *string, last *int, before *string, filter *model.PolishWordFilter) (*model.PolishWordConnection, error) {
+	page, err := newPageRequest(first, after, last, before, polishWordCursorPrefix)
+	if err != nil {
+		return nil, dbError(err)
+	}
 
-	polishWordToEdit, err := pwr.fetchPolishWords(ctx, id, word)
+	filterConditions, filterArgs := polishWordFilterConditions(ctx, filter)
+	query, args := page.buildQuery("SELECT id, word, version FROM lexemes", "id", slices.Clone(filterConditions), slices.Clone(filterArgs))
+	rows, err := conn(ctx, pwr.DB).QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, dbError(err)
 	}
+	defer rows.Close()
 
-	if word == nil && edits.Word != nil {
-		result, err := pwr.DB.ExecContext(ctx,
-			"UPDATE polish_words SET word = $1, version = version + 1 WHERE id = $2 AND version = $3",
-			*edits.Word, polishWordToEdit.ID, polishWordToEdit.Version)
+	var polishWords []*model.PolishWord
+	for rows.Next() {
+		var pw model.PolishWord
 
-		if err != nil {
-			return nil, err
+		if err := rows.Scan(&pw.ID, &pw.Word, &pw.Version); err != nil {
+			return nil, dbError(err)
 		}
 
-		rowsAffected, err := result.RowsAffected()
-		if err != nil {
-			return nil, err
+		polishWords = append(polishWords, &pw)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, dbError(err)
+	}
+
+	hasMore := len(polishWords) > page.size
+	if hasMore {
+		polishWords = polishWords[:page.size]
+	}
+
+	if page.backward {
+		slices.Reverse(polishWords)
+	}
+
+	connection := &model.PolishWordConnection{
+		Edges:    []*model.PolishWordEdge{},
+		PageInfo: &model.PageInfo{},
+	}
+
+	for _, pw := range polishWords {
+		connection.Edges = append(connection.Edges, &model.PolishWordEdge{
+			Cursor: encodeCursor(polishWordCursorPrefix, pw.ID),
+			Node:   pw,
+		})
+	}
+
+	if len(connection.Edges) > 0 {
+		connection.PageInfo.StartCursor = &connection.Edges[0].Cursor
+		connection.PageInfo.EndCursor = &connection.Edges[len(connection.Edges)-1].Cursor
+	}
+
+	if page.backward {
+		connection.PageInfo.HasPreviousPage = hasMore
+		if page.beforeID != nil {
+			connection.PageInfo.HasNextPage, err = pwr.polishWordsExistBeyond(ctx, filterConditions, filterArgs, "id >= $%d", *page.beforeID)
 		}
-		if rowsAffected == 0 {
-			return nil, fmt.Errorf("this polish word has been modified by a different process")
+	} else {
+		connection.PageInfo.HasNextPage = hasMore
+		if page.afterID != nil {
+			connection.PageInfo.HasPreviousPage, err = pwr.polishWordsExistBeyond(ctx, filterConditions, filterArgs, "id <= $%d", *page.afterID)
 		}
+	}
 
-		polishWordToEdit.Word = *edits.Word
-		polishWordToEdit.Version++
+	if err != nil {
+		return nil, dbError(err)
 	}
 
-	if edits.Translations != nil {
-		translations, err := pwr.updateTranslations(ctx, polishWordToEdit.ID, edits.Translations)
-		if err != nil {
-			return nil, err
-		}
-		polishWordToEdit.Translations = translations
+	return connection, nil
+}
+
+// GetSinglePolishWord looks a word up as typed, then by the lemmas the
+// lemmatizer knows for it and finally in the inflection tables, so "zamku"
+// finds "zamek".
+func (pwr *PolishWordRepositoryDB) GetSinglePolishWord(ctx context.Context, id *string, word *string) (*model.PolishWord, error) {
+	pw, err := pwr.fetchPolishWords(ctx, id, word)
+
+	var notFound *apperror.NotFoundError
+	if id != nil || word == nil || !errors.As(err, &notFound) {
+		return pw, err
 	}
 
-	return polishWordToEdit, nil
+	pw, err = pwr.fetchPolishWordByLemma(ctx, *word)
+	if !errors.As(err, &notFound) {
+		return pw, err
+	}
 
+	return pwr.fetchPolishWordByForm(ctx, *word)
 }
 
-func (pwr *PolishWordRepositoryDB) GetAllPolishWords(ctx context.Context) ([]*model.PolishWord, error) {
-	rows, err := pwr.DB.QueryContext(ctx, "SELECT id, word, version FROM polish_words")
+func (pwr *PolishWordRepositoryDB) GetPolishWordsByIDs(ctx context.Context, ids []string) (map[string]*model.PolishWord, error) {
+	rows, err := conn(ctx, pwr.DB).QueryContext(ctx, "SELECT id, word, version FROM lexemes WHERE id = ANY($1::int[]) AND "+notDeleted+" AND "+visibleTo(ctx, ""), pq.Array(ids))
 	if err != nil {
-		return nil, err
+		return nil, dbError(err)
 	}
 	defer rows.Close()
 
-	var polishWords []*model.PolishWord
+	polishWords := make(map[string]*model.PolishWord, len(ids))
 	for rows.Next() {
 		var pw model.PolishWord
 
 		if err := rows.Scan(&pw.ID, &pw.Word, &pw.Version); err != nil {
-			return nil, err
+			return nil, dbError(err)
 		}
 
-		translations, err := pwr.getTranslationsWithExampleSentences(ctx, pw.ID)
-		if err != nil {
-			return nil, err
+		polishWords[pw.ID] = &pw
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, dbError(err)
+	}
+
+	return polishWords, nil
+}
+
+func (pwr *PolishWordRepositoryDB) GetPronunciationsByPolishWordIDs(ctx context.Context, ids []string) (map[string]*model.Pronunciation, error) {
+	rows, err := conn(ctx, pwr.DB).QueryContext(ctx,
+		"SELECT id, "+pronunciationColumns+" FROM lexemes WHERE id = ANY($1::int[])", pq.Array(ids))
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	pronunciations := make(map[string]*model.Pronunciation, len(ids))
+	for rows.Next() {
+		var id string
+		var p model.Pronunciation
+
+		if err := rows.Scan(append([]any{&id}, pronunciationFields(&p)...)...); err != nil {
+			return nil, dbError(err)
 		}
 
-		pw.Translations = translations
+		pronunciations[id] = pronunciationOrNil(&p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, dbError(err)
+	}
+
+	return pronunciations, nil
+}
+
+func (pwr *PolishWordRepositoryDB) GetLevelsByPolishWordIDs(ctx context.Context, ids []string) (map[string]*Level, error) {
+	return getLevelsByIDs(ctx, conn(ctx, pwr.DB), "lexemes", ids)
+}
+
+func (pwr *PolishWordRepositoryDB) GetAccessByPolishWordIDs(ctx context.Context, ids []string) (map[string]*Access, error) {
+	return getAccessByIDs(ctx, conn(ctx, pwr.DB), "lexemes", ids)
+}
+
+// GetMyWords lists the Polish words the caller sees, one per spelling: the
+// caller's private word where there is one, otherwise the shared or public
+// word.
+func (pwr *PolishWordRepositoryDB) GetMyWords(ctx context.Context) ([]*model.PolishWord, error) {
+	rows, err := conn(ctx, pwr.DB).QueryContext(ctx, `
+		SELECT DISTINCT ON (word) id, word, version
+		FROM lexemes
+		WHERE `+polishLexemesOnly+` AND `+visibleTo(ctx, "")+`
+		ORDER BY word, `+privateFirst)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	polishWords := []*model.PolishWord{}
+	for rows.Next() {
+		var pw model.PolishWord
+
+		if err := rows.Scan(&pw.ID, &pw.Word, &pw.Version); err != nil {
+			return nil, dbError(err)
+		}
 
 		polishWords = append(polishWords, &pw)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, err
+		return nil, dbError(err)
 	}
 
 	return polishWords, nil
 }
 
-func (pwr *PolishWordRepositoryDB) GetSinglePolishWord(ctx context.Context, id *string, word *string) (*model.PolishWord, error) {
-	pw, err := pwr.fetchPolishWords(ctx, id, word)
+// GetPolishWordAsOf returns the word as it was at version, or at the time
+// asOf, with the translations and example sentences it had then. A word that
+// was in the trash at asOf is not found; by version it can be looked up by
+// id even after it was deleted.
+func (pwr *PolishWordRepositoryDB) GetPolishWordAsOf(ctx context.Context, id *string, word *string, version *int, asOf *time.Time) (*model.PolishWord, error) {
+	if (version == nil) == (asOf == nil) {
+		return nil, validationError("", "exactly one of asOfVersion and asOf must be provided")
+	}
+
+	return inTx(ctx, pwr.DB, func(ctx context.Context) (*model.PolishWord, error) {
+		if id == nil {
+			current, err := pwr.fetchPolishWords(ctx, nil, word)
+			if err != nil {
+				return nil, err
+			}
+			id = &current.ID
+		}
+
+		snapshot, changedAt, err := pwr.fetchPolishWordRevision(ctx, *id, version, asOf)
+		if err != nil {
+			return nil, err
+		}
+		if asOf != nil && snapshot.DeletedAt != nil {
+			return nil, &apperror.NotFoundError{Entity: "polish word", Field: "id", Value: *id}
+		}
+
+		past := snapshot.polishWord()
+		past.Past = true
+
+		past.Translations, err = pwr.fetchTranslationsAsOf(ctx, *id, changedAt, past)
+		if err != nil {
+			return nil, err
+		}
+
+		return past, nil
+	})
+}
+
+// RevertPolishWord writes the word's own fields as they were at toVersion as
+// a new version. Translations are left as they are.
+func (pwr *PolishWordRepositoryDB) RevertPolishWord(ctx context.Context, id string, toVersion int) (*model.PolishWord, error) {
+	return inTx(ctx, pwr.DB, func(ctx context.Context) (*model.PolishWord, error) {
+		current, err := pwr.fetchPolishWords(ctx, &id, nil)
+		if err != nil {
+			return nil, err
+		}
+		if toVersion >= current.Version {
+			return nil, validationError("toVersion", "toVersion must be an earlier version of the word")
+		}
+
+		snapshot, _, err := pwr.fetchPolishWordRevision(ctx, id, &toVersion, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		reverted := snapshot.polishWord()
+		result, err := conn(ctx, pwr.DB).ExecContext(ctx,
+			`UPDATE lexemes
+			SET word = $1, ipa = $2, syllabification = $3, stressed_syllable = $4,
+				cefr_level = $5, frequency_rank = $6, version = version + 1
+			WHERE id = $7 AND version = $8`,
+			append(append([]any{reverted.Word}, pronunciationValues(reverted.Pronunciation)...), reverted.CefrLevel, reverted.FrequencyRank, id, current.Version)...)
+		if err != nil {
+			return nil, dbError(err)
+		}
 
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return nil, dbError(err)
+		}
+		if rowsAffected == 0 {
+			return nil, versionConflict(ctx, conn(ctx, pwr.DB), "lexemes", "polish word", id, current.Version)
+		}
+
+		reverted.Version = current.Version + 1
+		return reverted, nil
+	})
+}
+
+func (pwr *PolishWordRepositoryDB) GetRevisionsByPolishWordIDs(ctx context.Context, ids []string) (map[string][]*model.Revision, error) {
+	rows, err := conn(ctx, pwr.DB).QueryContext(ctx,
+		"SELECT entity_id, version, author, changed_at, diff FROM polish_word_history WHERE entity_id = ANY($1::int[]) ORDER BY id",
+		pq.Array(ids))
 	if err != nil {
-		return nil, err
+		return nil, dbError(err)
 	}
+	defer rows.Close()
 
-	translations, err := pwr.getTranslationsWithExampleSentences(ctx, pw.ID)
-	if err != nil {
-		return nil, err
+	revisions := make(map[string][]*model.Revision, len(ids))
+	for _, id := range ids {
+		revisions[id] = []*model.Revision{}
+	}
+
+	for rows.Next() {
+		var id string
+		var revision model.Revision
+		var diff []byte
+		if err := rows.Scan(&id, &revision.Version, &revision.Author, &revision.ChangedAt, &diff); err != nil {
+			return nil, dbError(err)
+		}
+
+		revision.Changes, err = fieldChanges(diff)
+		if err != nil {
+			return nil, err
+		}
+
+		revisions[id] = append(revisions[id], &revision)
 	}
 
-	pw.Translations = translations
+	if err = rows.Err(); err != nil {
+		return nil, dbError(err)
+	}
 
-	return pw, nil
+	return revisions, nil
 }
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
 )
@@ -10,6 +11,15 @@ type PolishWordRepositoryInterface interface {
 	AddPolishWord(ctx context.Context, polishWord model.AddPolishWordInput) (*model.PolishWord, error)
 	DeletePolishWord(ctx context.Context, id *string, word *string) (*model.PolishWord, error)
 	UpdatePolishWord(ctx context.Context, id *string, word *string, edits *model.EditPolishWordInput) (*model.PolishWord, error)
-	GetAllPolishWords(ctx context.Context) ([]*model.PolishWord, error)
+	GetAllPolishWords(ctx context.Context, filter *model.PolishWordFilter, orderBy *model.PolishWordOrder) ([]*model.PolishWord, error)
+	GetPolishWordsPage(ctx context.Context, first *int, after *string, last *int, before *string, filter *model.PolishWordFilter) (*model.PolishWordConnection, error)
 	GetSinglePolishWord(ctx context.Context, id *string, word *string) (*model.PolishWord, error)
+	GetPolishWordsByIDs(ctx context.Context, ids []string) (map[string]*model.PolishWord, error)
+	GetPronunciationsByPolishWordIDs(ctx context.Context, ids []string) (map[string]*model.Pronunciation, error)
+	GetLevelsByPolishWordIDs(ctx context.Context, ids []string) (map[string]*Level, error)
+	GetAccessByPolishWordIDs(ctx context.Context, ids []string) (map[string]*Access, error)
+	GetMyWords(ctx context.Context) ([]*model.PolishWord, error)
+	GetPolishWordAsOf(ctx context.Context, id *string, word *string, version *int, asOf *time.Time) (*model.PolishWord, error)
+	RevertPolishWord(ctx context.Context, id string, toVersion int) (*model.PolishWord, error)
+	GetRevisionsByPolishWordIDs(ctx context.Context, ids []string) (map[string][]*model.Revision, error)
 }
@@ -62,6 +62,12 @@ func TestAddPolishWord_ConcurrentTranslations(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	translationsByPolishWordID, err := translationRepo.GetTranslationsByPolishWordIDs(context.Background(), []string{pw.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pw.Translations = translationsByPolishWordID[pw.ID]
+
 	if len(pw.Translations) != 2 {
 		t.Fatalf("Expected 2 translations, got %d", len(pw.Translations))
 	}
@@ -156,8 +162,13 @@ func TestAddWithExampleSentence_Concurrent(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	translationsByPolishWordID, err := translationRepo.GetTranslationsByPolishWordIDs(context.Background(), []string{pw.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	var foundTrans *model.Translation
-	for _, t := range pw.Translations {
+	for _, t := range translationsByPolishWordID[pw.ID] {
 		if t.EnglishWord == "write" {
 			foundTrans = t
 			break
@@ -167,6 +178,12 @@ func TestAddWithExampleSentence_Concurrent(t *testing.T) {
 		t.Fatalf("Translation 'write' not found")
 	}
 
+	exampleSentencesByTranslationID, err := exampleSentenceRepo.GetExampleSentencesByTranslationIDs(context.Background(), []string{foundTrans.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	foundTrans.ExampleSentences = exampleSentencesByTranslationID[foundTrans.ID]
+
 	if len(foundTrans.ExampleSentences) != len(exampleSentences) {
 		t.Fatalf("Expected %d example sentences, got %d", len(exampleSentences), len(foundTrans.ExampleSentences))
 	}
@@ -185,3 +202,66 @@ func TestAddWithExampleSentence_Concurrent(t *testing.T) {
 		}
 	}
 }
+
+func TestDeletePolishWordWithTranslations(t *testing.T) {
+
+	err := godotenv.Load("../../.env")
+	if err != nil {
+		t.Fatal("Error loading .env file")
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	exampleSentenceRepo := &ExampleSentenceRepositoryDB{DB: db}
+
+	translationRepo := &TranslationRepositoryDB{
+		ExampleSentenceRepo: exampleSentenceRepo,
+		DB:                  db,
+	}
+
+	polishRepo := &PolishWordRepositoryDB{
+		DB:              db,
+		TranslationRepo: translationRepo,
+	}
+
+	word := "usuwać"
+
+	_, err = polishRepo.AddPolishWord(context.Background(), model.AddPolishWordInput{
+		Word: word,
+		Translations: []*model.AddTranslationInput{
+			{
+				EnglishWord: "delete",
+				ExampleSentences: []*model.AddExampleSentenceInput{
+					{SentencePl: "Usuwam plik.", SentenceEn: "I am deleting the file."},
+				},
+			},
+			{EnglishWord: "remove"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error setting up Polish word: %v", err)
+	}
+
+	deleted, err := polishRepo.DeletePolishWord(context.Background(), nil, &word)
+	if err != nil {
+		t.Fatalf("Unexpected error deleting Polish word: %v", err)
+	}
+
+	if len(deleted.Translations) != 2 {
+		t.Fatalf("Expected 2 deleted translations, got %d", len(deleted.Translations))
+	}
+
+	for _, trans := range deleted.Translations {
+		if trans.EnglishWord == "delete" && len(trans.ExampleSentences) != 1 {
+			t.Errorf("Expected 1 deleted example sentence, got %d", len(trans.ExampleSentences))
+		}
+	}
+
+	if _, err := polishRepo.GetSinglePolishWord(context.Background(), nil, &word); err == nil {
+		t.Errorf("Polish word %q is still found after being deleted", word)
+	}
+}
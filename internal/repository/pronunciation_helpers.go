@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/pronunciation"
+)
+
+const (
+	pronunciationColumns = "ipa, syllabification, stressed_syllable"
+
+	maxIPALength = 100
+)
+
+// pronunciationFields returns scan destinations matching pronunciationColumns.
+func pronunciationFields(p *model.Pronunciation) []any {
+	return []any{&p.Ipa, &p.Syllabification, &p.StressedSyllable}
+}
+
+func pronunciationValues(p *model.Pronunciation) []any {
+	if p == nil {
+		return []any{nil, nil, nil}
+	}
+	return []any{p.Ipa, p.Syllabification, p.StressedSyllable}
+}
+
+// pronunciationOrNil returns nil for a pronunciation without any fields, which
+// the API shows as null.
+func pronunciationOrNil(p *model.Pronunciation) *model.Pronunciation {
+	if p == nil || (p.Ipa == nil && p.Syllabification == nil && p.StressedSyllable == nil) {
+		return nil
+	}
+	return p
+}
+
+func pronunciationFromInput(input *model.PronunciationInput) *model.Pronunciation {
+	var p model.Pronunciation
+	applyPronunciationEdits(&p, input)
+	return &p
+}
+
+// applyPronunciationEdits copies the fields set in edits onto p. An empty
+// string or a zero stressedSyllable clears the field.
+func applyPronunciationEdits(p *model.Pronunciation, edits *model.PronunciationInput) {
+	if edits == nil {
+		return
+	}
+
+	if edits.Ipa != nil {
+		p.Ipa = nilIfZero(*edits.Ipa)
+	}
+	if edits.Syllabification != nil {
+		p.Syllabification = nilIfZero(*edits.Syllabification)
+	}
+	if edits.StressedSyllable != nil {
+		p.StressedSyllable = nilIfZero(*edits.StressedSyllable)
+	}
+}
+
+func nilIfZero[T comparable](value T) *T {
+	var zero T
+	if value == zero {
+		return nil
+	}
+	return &value
+}
+
+// generatePronunciation fills the fields of p that are still empty from the
+// spelling of word. Words the generator cannot transcribe are left as they are.
+func generatePronunciation(generator pronunciation.Generator, word string, p *model.Pronunciation) {
+	if generator == nil {
+		return
+	}
+
+	result, ok := generator.Generate(word)
+	if !ok {
+		return
+	}
+
+	if p.Ipa == nil {
+		p.Ipa = &result.IPA
+	}
+
+	if p.Syllabification == nil {
+		syllabification := strings.Join(result.Syllables, "-")
+		p.Syllabification = &syllabification
+	}
+
+	// Stress is on the penultimate syllable of whichever syllabification is
+	// stored, which may be one given by hand.
+	if p.StressedSyllable == nil {
+		stressed := max(len(strings.Split(*p.Syllabification, "-"))-1, 1)
+		p.StressedSyllable = &stressed
+	}
+}
+
+func validatePronunciation(word string, p *model.Pronunciation) error {
+	if p.Ipa != nil && utf8.RuneCountInString(*p.Ipa) > maxIPALength {
+		return validationError("ipa", fmt.Sprintf("ipa must be at most %d characters long", maxIPALength))
+	}
+
+	syllables := 0
+	if p.Syllabification != nil {
+		parts := strings.Split(*p.Syllabification, "-")
+		if slices.Contains(parts, "") || !strings.EqualFold(strings.Join(parts, ""), word) {
+			return validationError("syllabification", "syllabification must spell the word with its syllables separated by hyphens")
+		}
+		syllables = len(parts)
+	}
+
+	if p.StressedSyllable != nil {
+		if *p.StressedSyllable < 1 {
+			return validationError("stressedSyllable", "stressedSyllable must be at least 1")
+		}
+		if syllables > 0 && *p.StressedSyllable > syllables {
+			return validationError("stressedSyllable", fmt.Sprintf("stressedSyllable must not exceed the number of syllables (%d)", syllables))
+		}
+	}
+
+	return nil
+}
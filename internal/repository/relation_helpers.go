@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+)
+
+// The DO UPDATE is a no-op that makes RETURNING yield the existing row.
+const wordRelationUpsertConflict = "ON CONFLICT (polish_word_id, related_word_id, type) DO UPDATE SET type = EXCLUDED.type"
+
+// exclusiveRelationTypes lists the types two words cannot be linked by at
+// the same time.
+var exclusiveRelationTypes = map[model.RelationType]model.RelationType{
+	model.RelationTypeSynonym: model.RelationTypeAntonym,
+	model.RelationTypeAntonym: model.RelationTypeSynonym,
+}
+
+func isSymmetricRelation(relationType model.RelationType) bool {
+	switch relationType {
+	case model.RelationTypeSynonym, model.RelationTypeAntonym, model.RelationTypeAspectPair:
+		return true
+	}
+	return false
+}
+
+func directedRelationTypes() []string {
+	var types []string
+	for _, relationType := range model.AllRelationType {
+		if !isSymmetricRelation(relationType) {
+			types = append(types, string(relationType))
+		}
+	}
+	return types
+}
+
+// checkRelation rejects links that contradict existing ones: synonyms that
+// are also antonyms, and directed links that would close a cycle, such as a
+// word becoming its own hypernym through other words. The table is locked
+// for directed links, so two concurrent additions cannot close a cycle
+// together.
+func (rr *RelationRepositoryDB) checkRelation(ctx context.Context, polishWordID string, relatedWordID string, relationType model.RelationType) error {
+	if exclusive, ok := exclusiveRelationTypes[relationType]; ok {
+		var conflicting bool
+		err := conn(ctx, rr.DB).QueryRowContext(ctx,
+			"SELECT EXISTS(SELECT 1 FROM word_relations WHERE polish_word_id = $1 AND related_word_id = $2 AND type = $3)",
+			polishWordID, relatedWordID, exclusive).Scan(&conflicting)
+		if err != nil {
+			return dbError(err)
+		}
+		if conflicting {
+			return validationError("type", fmt.Sprintf("the words are already linked as %s", exclusive))
+		}
+	}
+
+	if isSymmetricRelation(relationType) {
+		return nil
+	}
+
+	if _, err := conn(ctx, rr.DB).ExecContext(ctx, "LOCK TABLE word_relations IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		return dbError(err)
+	}
+
+	var cycle bool
+	err := conn(ctx, rr.DB).QueryRowContext(ctx, `
+		WITH RECURSIVE reachable(id) AS (
+			SELECT related_word_id FROM word_relations WHERE polish_word_id = $1 AND type = $3
+			UNION
+			SELECT wr.related_word_id FROM word_relations wr JOIN reachable r ON wr.polish_word_id = r.id WHERE wr.type = $3
+		)
+		SELECT EXISTS(SELECT 1 FROM reachable WHERE id = $2)`, relatedWordID, polishWordID, relationType).Scan(&cycle)
+	if err != nil {
+		return dbError(err)
+	}
+	if cycle {
+		return validationError("relatedWord", fmt.Sprintf("the link would make a %s cycle", relationType))
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/lib/pq"
+)
+
+type RelationRepositoryDB struct {
+	DB *sql.DB
+}
+
+// AddWordRelation returns the existing link when the words are already
+// related in that way.
+func (rr *RelationRepositoryDB) AddWordRelation(ctx context.Context, relation model.AddWordRelationInput) (*model.WordRelation, error) {
+	if !relation.Type.IsValid() {
+		return nil, validationError("type", "unknown relation type "+relation.Type.String())
+	}
+
+	if relation.RelatedWordID == nil && relation.RelatedWord == nil {
+		return nil, validationError("", "either relatedWordId or relatedWord must be provided")
+	}
+
+	return inTx(ctx, rr.DB, func(ctx context.Context) (*model.WordRelation, error) {
+		polishWordID, err := getTargetPolishWordID(ctx, conn(ctx, rr.DB), relation.PolishWordID, relation.PolishWord)
+		if err != nil {
+			return nil, err
+		}
+
+		relatedWordID, err := getTargetPolishWordID(ctx, conn(ctx, rr.DB), relation.RelatedWordID, relation.RelatedWord)
+		if err != nil {
+			return nil, err
+		}
+
+		if *polishWordID == *relatedWordID {
+			return nil, validationError("relatedWord", "a word cannot be related to itself")
+		}
+
+		if err := rr.checkRelation(ctx, *polishWordID, *relatedWordID, relation.Type); err != nil {
+			return nil, err
+		}
+
+		relatedWord, err := prepareWordWithId(ctx, conn(ctx, rr.DB), relatedWordID)
+		if err != nil {
+			return nil, err
+		}
+
+		newRelation := &model.WordRelation{Type: relation.Type, Word: relatedWord, WordID: *relatedWordID}
+
+		err = conn(ctx, rr.DB).QueryRowContext(ctx, `
+			INSERT INTO word_relations (polish_word_id, related_word_id, type)
+			VALUES ($1, $2, $3)
+			`+wordRelationUpsertConflict+`
+			RETURNING id`, *polishWordID, *relatedWordID, relation.Type).Scan(&newRelation.ID)
+		if err != nil {
+			return nil, missingReferenceOr(err, "polish word", "id", *polishWordID)
+		}
+
+		if isSymmetricRelation(relation.Type) {
+			_, err = conn(ctx, rr.DB).ExecContext(ctx, `
+				INSERT INTO word_relations (polish_word_id, related_word_id, type)
+				VALUES ($1, $2, $3)
+				ON CONFLICT (polish_word_id, related_word_id, type) DO NOTHING`, *relatedWordID, *polishWordID, relation.Type)
+			if err != nil {
+				return nil, dbError(err)
+			}
+		}
+
+		return newRelation, nil
+	})
+}
+
+// RemoveWordRelation only finds links between two words the caller may see,
+// so a link to a private word of someone else cannot be removed.
+func (rr *RelationRepositoryDB) RemoveWordRelation(ctx context.Context, id string) (*model.WordRelation, error) {
+	return inTx(ctx, rr.DB, func(ctx context.Context) (*model.WordRelation, error) {
+		removed := &model.WordRelation{ID: id}
+		var polishWordID string
+
+		err := conn(ctx, rr.DB).QueryRowContext(ctx, `
+			DELETE FROM word_relations
+			WHERE id = $1
+			AND polish_word_id IN (SELECT id FROM lexemes WHERE `+visibleTo(ctx, "")+`)
+			AND related_word_id IN (SELECT id FROM lexemes WHERE `+visibleTo(ctx, "")+`)
+			RETURNING polish_word_id, related_word_id, type`, id).
+			Scan(&polishWordID, &removed.WordID, &removed.Type)
+		if err != nil {
+			return nil, notFoundOr(err, "word relation", "id", id)
+		}
+
+		if isSymmetricRelation(removed.Type) {
+			_, err = conn(ctx, rr.DB).ExecContext(ctx,
+				"DELETE FROM word_relations WHERE polish_word_id = $1 AND related_word_id = $2 AND type = $3",
+				removed.WordID, polishWordID, removed.Type)
+			if err != nil {
+				return nil, dbError(err)
+			}
+		}
+
+		return removed, nil
+	})
+}
+
+// GetRelationsByPolishWordIDs returns the links of each word, including the
+// inverse side of directed links that point at it. Links to words in the
+// trash or that the caller may not see are left out.
+func (rr *RelationRepositoryDB) GetRelationsByPolishWordIDs(ctx context.Context, polishWordIDs []string) (map[string][]*model.WordRelation, error) {
+	rows, err := conn(ctx, rr.DB).QueryContext(ctx, `
+		SELECT id, polish_word_id, related_word_id, type, false
+		FROM word_relations
+		WHERE polish_word_id = ANY($1::int[])
+		AND related_word_id IN (SELECT id FROM lexemes WHERE deleted_at IS NULL AND `+visibleTo(ctx, "")+`)
+		UNION ALL
+		SELECT id, related_word_id, polish_word_id, type, true
+		FROM word_relations
+		WHERE related_word_id = ANY($1::int[]) AND type = ANY($2::text[])
+		AND polish_word_id IN (SELECT id FROM lexemes WHERE deleted_at IS NULL AND `+visibleTo(ctx, "")+`)
+		ORDER BY 1`, pq.Array(polishWordIDs), pq.Array(directedRelationTypes()))
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	relations := make(map[string][]*model.WordRelation, len(polishWordIDs))
+	for _, polishWordID := range polishWordIDs {
+		relations[polishWordID] = []*model.WordRelation{}
+	}
+
+	for rows.Next() {
+		var polishWordID string
+		var relation model.WordRelation
+		if err := rows.Scan(&relation.ID, &polishWordID, &relation.WordID, &relation.Type, &relation.Inverse); err != nil {
+			return nil, dbError(err)
+		}
+		relations[polishWordID] = append(relations[polishWordID], &relation)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, dbError(err)
+	}
+
+	return relations, nil
+}
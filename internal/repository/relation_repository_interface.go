@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+)
+
+type RelationRepositoryInterface interface {
+	AddWordRelation(ctx context.Context, relation model.AddWordRelationInput) (*model.WordRelation, error)
+	RemoveWordRelation(ctx context.Context, id string) (*model.WordRelation, error)
+	GetRelationsByPolishWordIDs(ctx context.Context, polishWordIDs []string) (map[string][]*model.WordRelation, error)
+}
@@ -2,10 +2,20 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/apperror"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/auth"
 	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/pronunciation"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -23,15 +33,20 @@ func TestUpdatePolishWordVersionConflict(t *testing.T) {
 	ctx := context.Background()
 	id := "1"
 
-	mock.ExpectQuery("SELECT id, word, version FROM polish_words WHERE id = \\$1").
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, word, version FROM lexemes WHERE id = \\$1").
 		WithArgs(id).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "word", "version"}).
 			AddRow(id, "old_word", 1))
 
 	newWord := "new_word"
-	mock.ExpectExec("UPDATE polish_words SET word = \\$1, version = version \\+ 1 WHERE id = \\$2 AND version = \\$3").
-		WithArgs(newWord, id, 1).
+	mock.ExpectExec("UPDATE lexemes SET word = \\$1, ipa = \\$2, syllabification = \\$3, stressed_syllable = \\$4, cefr_level = COALESCE\\(\\$5, cefr_level\\), frequency_rank = NULLIF\\(COALESCE\\(\\$6, frequency_rank\\), 0\\), visibility = COALESCE\\(\\$7, visibility\\), version = version \\+ 1 WHERE id = \\$8 AND version = \\$9").
+		WithArgs(newWord, nil, nil, nil, nil, nil, nil, id, 1).
 		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version FROM lexemes WHERE id = \\$1").
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+	mock.ExpectRollback()
 
 	edits := &model.EditPolishWordInput{
 		Word:    &newWord,
@@ -42,6 +57,11 @@ func TestUpdatePolishWordVersionConflict(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "this polish word has been modified by a different process")
 
+	var conflict *apperror.VersionConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, 1, conflict.ExpectedVersion)
+	assert.Equal(t, 2, conflict.CurrentVersion)
+
 	require.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -58,25 +78,36 @@ func TestUpdateTranslationVersionConflict(t *testing.T) {
 	ctx := context.Background()
 	id := "1"
 
-	mock.ExpectQuery("SELECT id, english_word, polish_word_id, version FROM translations WHERE id = \\$1").
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, english_word, language, polish_word_id, version, part_of_speech, gender, aspect, aspect_partner, usage_note FROM translations WHERE id = \\$1").
 		WithArgs(id).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "english_word", "polish_word_id", "version"}).
-			AddRow(id, "old_translation", "1", 1))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "english_word", "language", "polish_word_id", "version", "part_of_speech", "gender", "aspect", "aspect_partner", "usage_note"}).
+			AddRow(id, "old_translation", "en", "1", 1, nil, nil, nil, nil, nil))
 
 	newTranslation := "new_translation"
-	mock.ExpectExec("UPDATE translations SET english_word = \\$1, version = version \\+ 1 WHERE id = \\$2 AND version = \\$3").
-		WithArgs(newTranslation, id, 1).
+	mock.ExpectExec("UPDATE translations SET english_word = \\$1, language = \\$2, part_of_speech = \\$3, gender = \\$4, aspect = \\$5, aspect_partner = \\$6, usage_note = \\$7, cefr_level = COALESCE\\(\\$8, cefr_level\\), frequency_rank = NULLIF\\(COALESCE\\(\\$9, frequency_rank\\), 0\\), visibility = COALESCE\\(\\$10, visibility\\), version = version \\+ 1 WHERE id = \\$11 AND version = \\$12").
+		WithArgs(newTranslation, "en", nil, nil, nil, nil, nil, nil, nil, nil, id, 1).
 		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version FROM translations WHERE id = \\$1").
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+	mock.ExpectRollback()
 
+	version := 1
 	edits := model.EditTranslationInput{
 		EnglishWord: &newTranslation,
-		Version:     1,
+		Version:     &version,
 	}
 
 	_, err = repo.UpdateTranslation(ctx, id, edits)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "this translation has been modified by a different process")
 
+	var conflict *apperror.VersionConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, 1, conflict.ExpectedVersion)
+	assert.Equal(t, 2, conflict.CurrentVersion)
+
 	require.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -93,6 +124,7 @@ func TestUpdateExampleSentenceVersionConflict(t *testing.T) {
 	ctx := context.Background()
 	id := "1"
 
+	mock.ExpectBegin()
 	mock.ExpectQuery("SELECT sentence_pl, sentence_en, translation_id, version FROM example_sentences WHERE id = \\$1").
 		WithArgs(id).
 		WillReturnRows(sqlmock.NewRows([]string{"sentence_pl", "sentence_en", "translation_id", "version"}).
@@ -100,19 +132,2289 @@ func TestUpdateExampleSentenceVersionConflict(t *testing.T) {
 
 	newSentencePl := "new_sentence_pl"
 	newSentenceEn := "new_sentence_en"
-	mock.ExpectExec("UPDATE example_sentences SET sentence_pl = \\$1, sentence_en = \\$2, version = version \\+ 1 WHERE id = \\$3 AND version = \\$4").
-		WithArgs(newSentencePl, newSentenceEn, id, 1).
+	mock.ExpectExec("UPDATE example_sentences SET sentence_pl = \\$1, sentence_en = \\$2, visibility = COALESCE\\(\\$3, visibility\\), version = version \\+ 1 WHERE id = \\$4 AND version = \\$5").
+		WithArgs(newSentencePl, newSentenceEn, nil, id, 1).
 		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version FROM example_sentences WHERE id = \\$1").
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+	mock.ExpectRollback()
 
+	version := 1
 	edits := model.EditExampleSentenceInput{
 		SentencePl: &newSentencePl,
 		SentenceEn: &newSentenceEn,
-		Version:    1,
+		Version:    &version,
 	}
 
 	_, err = repo.UpdateExampleSentence(ctx, id, edits)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "this example sentence has been modified by a different process")
 
+	var conflict *apperror.VersionConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, 1, conflict.ExpectedVersion)
+	assert.Equal(t, 2, conflict.CurrentVersion)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPolishWordsPageForward(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &PolishWordRepositoryDB{
+		DB: db,
+	}
+
+	ctx := context.Background()
+	first := 2
+	after := encodeCursor(polishWordCursorPrefix, "3")
+
+	mock.ExpectQuery("SELECT id, word, version FROM lexemes WHERE language = 'pl' AND deleted_at IS NULL AND visibility = 'public' AND id > \\$1 ORDER BY id ASC LIMIT \\$2").
+		WithArgs(3, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "word", "version"}).
+			AddRow("4", "kot", 1).
+			AddRow("7", "pies", 1).
+			AddRow("9", "dom", 1))
+
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM lexemes WHERE language = 'pl' AND deleted_at IS NULL AND visibility = 'public' AND id <= \\$1\\)").
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	connection, err := repo.GetPolishWordsPage(ctx, &first, &after, nil, nil, nil)
+	require.NoError(t, err)
+
+	require.Len(t, connection.Edges, 2)
+	assert.Equal(t, "kot", connection.Edges[0].Node.Word)
+	assert.Equal(t, "pies", connection.Edges[1].Node.Word)
+	assert.True(t, connection.PageInfo.HasNextPage)
+	assert.True(t, connection.PageInfo.HasPreviousPage)
+	assert.Equal(t, encodeCursor(polishWordCursorPrefix, "7"), *connection.PageInfo.EndCursor)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPolishWordsPageBackward(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &PolishWordRepositoryDB{
+		DB: db,
+	}
+
+	ctx := context.Background()
+	last := 2
+	before := encodeCursor(polishWordCursorPrefix, "9")
+
+	mock.ExpectQuery("SELECT id, word, version FROM lexemes WHERE language = 'pl' AND deleted_at IS NULL AND visibility = 'public' AND id < \\$1 ORDER BY id DESC LIMIT \\$2").
+		WithArgs(9, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "word", "version"}).
+			AddRow("7", "pies", 1).
+			AddRow("4", "kot", 1))
+
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM lexemes WHERE language = 'pl' AND deleted_at IS NULL AND visibility = 'public' AND id >= \\$1\\)").
+		WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	connection, err := repo.GetPolishWordsPage(ctx, nil, nil, &last, &before, nil)
+	require.NoError(t, err)
+
+	require.Len(t, connection.Edges, 2)
+	assert.Equal(t, "kot", connection.Edges[0].Node.Word)
+	assert.Equal(t, "pies", connection.Edges[1].Node.Word)
+	assert.False(t, connection.PageInfo.HasPreviousPage)
+	assert.True(t, connection.PageInfo.HasNextPage)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPolishWordsPageFiltersByPartOfSpeech(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &PolishWordRepositoryDB{
+		DB: db,
+	}
+
+	ctx := context.Background()
+	first := 1
+	after := encodeCursor(polishWordCursorPrefix, "3")
+	filter := &model.PolishWordFilter{PartOfSpeech: ptr(model.PartOfSpeechVerb)}
+
+	mock.ExpectQuery("SELECT id, word, version FROM lexemes WHERE language = 'pl' AND deleted_at IS NULL AND visibility = 'public' AND EXISTS \\(SELECT 1 FROM translations t WHERE t.polish_word_id = lexemes.id AND t.deleted_at IS NULL AND t.visibility = 'public' AND t.part_of_speech = \\$1\\) AND id > \\$2 ORDER BY id ASC LIMIT \\$3").
+		WithArgs(model.PartOfSpeechVerb, 3, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "word", "version"}).
+			AddRow("5", "zamykać", 1))
+
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM lexemes WHERE language = 'pl' AND deleted_at IS NULL AND visibility = 'public' AND EXISTS \\(SELECT 1 FROM translations t WHERE t.polish_word_id = lexemes.id AND t.deleted_at IS NULL AND t.visibility = 'public' AND t.part_of_speech = \\$1\\) AND id <= \\$2\\)").
+		WithArgs(model.PartOfSpeechVerb, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	connection, err := repo.GetPolishWordsPage(ctx, &first, &after, nil, nil, filter)
+	require.NoError(t, err)
+
+	require.Len(t, connection.Edges, 1)
+	assert.Equal(t, "zamykać", connection.Edges[0].Node.Word)
+	assert.False(t, connection.PageInfo.HasNextPage)
+	assert.False(t, connection.PageInfo.HasPreviousPage)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPolishWordsPageInvalidArguments(t *testing.T) {
+
+	repo := &PolishWordRepositoryDB{}
+
+	first := 1
+	last := 1
+	_, err := repo.GetPolishWordsPage(context.Background(), &first, nil, &last, nil, nil)
+	require.Error(t, err)
+
+	invalidCursor := "not-a-cursor"
+	_, err = repo.GetPolishWordsPage(context.Background(), &first, &invalidCursor, nil, nil, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid cursor")
+}
+
+func TestSearchTranslations(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &SearchRepositoryDB{
+		DB:              db,
+		TranslationRepo: &TranslationRepositoryDB{DB: db},
+	}
+
+	ctx := context.Background()
+
+	mock.ExpectQuery("SELECT scope, id, score, snippet FROM \\(.*websearch_to_tsquery\\('english', \\$1\\).*\\) hits ORDER BY score DESC, scope, id LIMIT \\$2").
+		WithArgs("castle", defaultSearchLimit).
+		WillReturnRows(sqlmock.NewRows([]string{"scope", "id", "score", "snippet"}).
+			AddRow("TRANSLATIONS", "5", 0.0607927, "<b>castle</b>"))
+
+	mock.ExpectQuery("SELECT id, english_word, language, polish_word_id, version, part_of_speech, gender, aspect, aspect_partner, usage_note FROM translations WHERE id = ANY\\(\\$1::int\\[\\]\\)").
+		WithArgs(pq.Array([]string{"5"})).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "english_word", "language", "polish_word_id", "version", "part_of_speech", "gender", "aspect", "aspect_partner", "usage_note"}).
+			AddRow("5", "castle", "en", "2", 1, nil, nil, nil, nil, nil))
+
+	hits, err := repo.Search(ctx, "castle", []model.SearchScope{model.SearchScopeTranslations}, nil)
+	require.NoError(t, err)
+
+	require.Len(t, hits, 1)
+	assert.Equal(t, "<b>castle</b>", hits[0].Snippet)
+	assert.InDelta(t, 0.0607927, hits[0].Score, 1e-6)
+
+	translation, ok := hits[0].Result.(*model.Translation)
+	require.True(t, ok)
+	assert.Equal(t, "castle", translation.EnglishWord)
+	assert.Equal(t, "2", translation.PolishWordID)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchLoadsEachScopeWithOneQuery(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &SearchRepositoryDB{
+		DB:                  db,
+		PolishWordRepo:      &PolishWordRepositoryDB{DB: db},
+		ExampleSentenceRepo: &ExampleSentenceRepositoryDB{DB: db},
+		Lemmatizer:          staticLemmatizer{},
+	}
+
+	scope := []model.SearchScope{model.SearchScopePolishWords, model.SearchScopeExampleSentences}
+
+	mock.ExpectQuery("SELECT scope, id, score, snippet FROM").
+		WithArgs("zamek", defaultSearchLimit, "zamek").
+		WillReturnRows(sqlmock.NewRows([]string{"scope", "id", "score", "snippet"}).
+			AddRow("POLISH_WORDS", "2", 0.3, "<b>zamek</b>").
+			AddRow("EXAMPLE_SENTENCES", "7", 0.2, "Stary <b>zamek</b>.").
+			AddRow("POLISH_WORDS", "4", 0.1, "<b>zamek</b> błyskawiczny").
+			AddRow("POLISH_WORDS", "9", 0.05, "<b>zamki</b>"))
+	mock.ExpectQuery("SELECT id, word, version FROM lexemes WHERE id = ANY\\(\\$1::int\\[\\]\\)").
+		WithArgs(pq.Array([]string{"2", "4", "9"})).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "word", "version"}).
+			AddRow("4", "zamek błyskawiczny", 1).
+			AddRow("2", "zamek", 1))
+	mock.ExpectQuery("SELECT id, sentence_pl, sentence_en, translation_id, version FROM example_sentences WHERE id = ANY\\(\\$1::int\\[\\]\\)").
+		WithArgs(pq.Array([]string{"7"})).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "sentence_pl", "sentence_en", "translation_id", "version"}).
+			AddRow("7", "Stary zamek.", "An old castle.", "5", 1))
+
+	hits, err := repo.Search(context.Background(), "zamek", scope, nil)
+	require.NoError(t, err)
+
+	require.Len(t, hits, 3)
+	assert.Equal(t, "zamek", hits[0].Result.(*model.PolishWord).Word)
+	assert.Equal(t, "Stary zamek.", hits[1].Result.(*model.ExampleSentence).SentencePl)
+	assert.Equal(t, "zamek błyskawiczny", hits[2].Result.(*model.PolishWord).Word)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchEmptyQuery(t *testing.T) {
+
+	repo := &SearchRepositoryDB{}
+
+	_, err := repo.Search(context.Background(), "   ", nil, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "search query must not be empty")
+}
+
+func TestGetTranslationsByEnglishWordIsCaseInsensitive(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &TranslationRepositoryDB{
+		DB: db,
+	}
+
+	ctx := context.Background()
+
+	mock.ExpectQuery("WHERE lower\\(t.english_word\\) = lower\\(\\$1\\)").
+		WithArgs("Lock", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "english_word", "language", "version", "part_of_speech", "gender", "aspect", "aspect_partner", "usage_note", "id", "word", "version"}).
+			AddRow("3", "lock", "en", 1, nil, nil, nil, nil, nil, "1", "zamek", 2).
+			AddRow("8", "lock", "en", 1, nil, nil, nil, nil, nil, "4", "kłódka", 1))
+
+	translations, err := repo.GetTranslationsByEnglishWord(ctx, "Lock", nil)
+	require.NoError(t, err)
+
+	require.Len(t, translations, 2)
+	assert.Equal(t, "zamek", translations[0].PolishWord.Word)
+	assert.Equal(t, "kłódka", translations[1].PolishWord.Word)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidateTranslationGrammar(t *testing.T) {
+
+	partner := "zamknąć"
+	longNote := strings.Repeat("a", maxUsageNoteLength+1)
+
+	tests := []struct {
+		name        string
+		translation model.Translation
+		field       string
+	}{
+		{name: "noun with gender", translation: model.Translation{PartOfSpeech: ptr(model.PartOfSpeechNoun), Gender: ptr(model.GenderMasculineInanimate)}},
+		{name: "verb with aspect pair", translation: model.Translation{PartOfSpeech: ptr(model.PartOfSpeechVerb), Aspect: ptr(model.AspectImperfective), AspectPartner: &partner}},
+		{name: "gender on a verb", translation: model.Translation{PartOfSpeech: ptr(model.PartOfSpeechVerb), Gender: ptr(model.GenderFeminine)}, field: "gender"},
+		{name: "gender without part of speech", translation: model.Translation{Gender: ptr(model.GenderNeuter)}, field: "gender"},
+		{name: "aspect on a noun", translation: model.Translation{PartOfSpeech: ptr(model.PartOfSpeechNoun), Aspect: ptr(model.AspectPerfective)}, field: "aspect"},
+		{name: "aspect partner without aspect", translation: model.Translation{PartOfSpeech: ptr(model.PartOfSpeechVerb), AspectPartner: &partner}, field: "aspectPartner"},
+		{name: "unknown part of speech", translation: model.Translation{PartOfSpeech: ptr(model.PartOfSpeech("ARTICLE"))}, field: "partOfSpeech"},
+		{name: "usage note too long", translation: model.Translation{UsageNote: &longNote}, field: "usageNote"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTranslationGrammar(&tt.translation)
+			if tt.field == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			var validation *apperror.ValidationError
+			require.ErrorAs(t, err, &validation)
+			assert.Equal(t, tt.field, validation.Field)
+		})
+	}
+}
+
+func TestApplyTranslationGrammarEditsClearsFieldsThatNoLongerApply(t *testing.T) {
+
+	partner := "zamknąć"
+	translation := &model.Translation{
+		PartOfSpeech:  ptr(model.PartOfSpeechVerb),
+		Aspect:        ptr(model.AspectImperfective),
+		AspectPartner: &partner,
+	}
+
+	empty := ""
+	applyTranslationGrammarEdits(translation, &model.EditTranslationInput{
+		PartOfSpeech: ptr(model.PartOfSpeechNoun),
+		Gender:       ptr(model.GenderMasculineInanimate),
+		UsageNote:    &empty,
+	})
+
+	assert.Equal(t, model.PartOfSpeechNoun, *translation.PartOfSpeech)
+	assert.Equal(t, model.GenderMasculineInanimate, *translation.Gender)
+	assert.Nil(t, translation.Aspect)
+	assert.Nil(t, translation.AspectPartner)
+	assert.Nil(t, translation.UsageNote)
+	require.NoError(t, validateTranslationGrammar(translation))
+}
+
+func TestAddPolishWordRollsBackWhenExampleSentenceFails(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	exampleSentenceRepo := &ExampleSentenceRepositoryDB{DB: db}
+	translationRepo := &TranslationRepositoryDB{DB: db, ExampleSentenceRepo: exampleSentenceRepo}
+	repo := &PolishWordRepositoryDB{DB: db, TranslationRepo: translationRepo}
+
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO lexemes").
+		WithArgs("zamek", nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version", "ipa", "syllabification", "stressed_syllable"}).AddRow("1", 1, nil, nil, nil))
+	mock.ExpectQuery("INSERT INTO translations").
+		WithArgs("castle", "en", "1", nil, nil, nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version", "part_of_speech", "gender", "aspect", "aspect_partner", "usage_note"}).AddRow("2", 1, nil, nil, nil, nil, nil))
+	mock.ExpectQuery("INSERT INTO example_sentences").
+		WithArgs("Zamek stoi na wzgórzu.", "The castle stands on a hill.", "2", nil).
+		WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	_, err = repo.AddPolishWord(ctx, model.AddPolishWordInput{
+		Word: "zamek",
+		Translations: []*model.AddTranslationInput{
+			{
+				EnglishWord: "castle",
+				ExampleSentences: []*model.AddExampleSentenceInput{
+					{SentencePl: "Zamek stoi na wzgórzu.", SentenceEn: "The castle stands on a hill."},
+				},
+			},
+		},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "connection reset")
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddPolishWordCommitsOnce(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	exampleSentenceRepo := &ExampleSentenceRepositoryDB{DB: db}
+	translationRepo := &TranslationRepositoryDB{DB: db, ExampleSentenceRepo: exampleSentenceRepo}
+	repo := &PolishWordRepositoryDB{DB: db, TranslationRepo: translationRepo}
+
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO lexemes").
+		WithArgs("kot", nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version", "ipa", "syllabification", "stressed_syllable"}).AddRow("1", 1, nil, nil, nil))
+	mock.ExpectQuery("INSERT INTO translations").
+		WithArgs("cat", "en", "1", nil, nil, nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version", "part_of_speech", "gender", "aspect", "aspect_partner", "usage_note"}).AddRow("2", 1, nil, nil, nil, nil, nil))
+	mock.ExpectCommit()
+
+	pw, err := repo.AddPolishWord(ctx, model.AddPolishWordInput{
+		Word:         "kot",
+		Translations: []*model.AddTranslationInput{{EnglishWord: "cat"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, pw.Translations, 1)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSinglePolishWordNotFound(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &PolishWordRepositoryDB{
+		DB: db,
+	}
+
+	word := "nieistniejące"
+
+	mock.ExpectQuery("SELECT id, word, version FROM lexemes WHERE word = \\$1").
+		WithArgs(word).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("FROM inflections i JOIN lexemes p ON p.id = i.polish_word_id WHERE i.form = \\$1").
+		WithArgs(word).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.GetSinglePolishWord(context.Background(), nil, &word)
+
+	var notFound *apperror.NotFoundError
+	require.ErrorAs(t, err, &notFound)
+	assert.Equal(t, "polish word", notFound.Entity)
+	assert.Equal(t, "word", notFound.Field)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSinglePolishWordResolvesInflectedForm(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &PolishWordRepositoryDB{
+		DB: db,
+	}
+
+	word := "zamku"
+
+	mock.ExpectQuery("SELECT id, word, version FROM lexemes WHERE word = \\$1").
+		WithArgs(word).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("SELECT p.id, p.word, p.version FROM inflections i JOIN lexemes p ON p.id = i.polish_word_id WHERE i.form = \\$1 AND p.language = 'pl' AND p.deleted_at IS NULL AND p.visibility = 'public' ORDER BY p.tenant <> '' DESC, p.id LIMIT 1").
+		WithArgs(word).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "word", "version"}).AddRow("1", "zamek", 2))
+
+	pw, err := repo.GetSinglePolishWord(context.Background(), nil, &word)
+	require.NoError(t, err)
+	assert.Equal(t, "zamek", pw.Word)
+	assert.Equal(t, "1", pw.ID)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+type staticLemmatizer map[string][]string
+
+func (sl staticLemmatizer) Lemmas(form string) []string {
+	return sl[form]
+}
+
+func TestGetSinglePolishWordResolvesLemma(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &PolishWordRepositoryDB{
+		DB:         db,
+		Lemmatizer: staticLemmatizer{"mamy": {"mamy", "mieć", "mama"}},
+	}
+
+	word := "mamy"
+
+	mock.ExpectQuery("SELECT id, word, version FROM lexemes WHERE word = \\$1").
+		WithArgs(word).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("SELECT id, word, version FROM lexemes WHERE word = ANY\\(\\$1::text\\[\\]\\) AND language = 'pl' AND deleted_at IS NULL AND visibility = 'public' ORDER BY array_position\\(\\$1::text\\[\\], word\\), tenant <> '' DESC LIMIT 1").
+		WithArgs(pq.Array([]string{"mieć", "mama"})).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "word", "version"}).AddRow("4", "mama", 1))
+
+	pw, err := repo.GetSinglePolishWord(context.Background(), nil, &word)
+	require.NoError(t, err)
+	assert.Equal(t, "mama", pw.Word)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchPolishWordsUsesLemmatizedQuery(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &SearchRepositoryDB{
+		DB:             db,
+		PolishWordRepo: &PolishWordRepositoryDB{DB: db},
+		Lemmatizer:     staticLemmatizer{"psów": {"pies"}},
+	}
+
+	mock.ExpectQuery("websearch_to_tsquery\\('polish', \\$1\\) \\|\\| websearch_to_tsquery\\('polish', \\$3\\)").
+		WithArgs("psów", defaultSearchLimit, "pies").
+		WillReturnRows(sqlmock.NewRows([]string{"scope", "id", "score", "snippet"}).
+			AddRow("POLISH_WORDS", "2", 0.1, "<b>pies</b>"))
+	mock.ExpectQuery("SELECT id, word, version FROM lexemes WHERE id = ANY\\(\\$1::int\\[\\]\\)").
+		WithArgs(pq.Array([]string{"2"})).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "word", "version"}).AddRow("2", "pies", 1))
+
+	hits, err := repo.Search(context.Background(), "psów", []model.SearchScope{model.SearchScopePolishWords}, nil)
+	require.NoError(t, err)
+
+	require.Len(t, hits, 1)
+	assert.Equal(t, "pies", hits[0].Result.(*model.PolishWord).Word)
+
 	require.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestAddInflectionValidatesFeatures(t *testing.T) {
+
+	repo := &InflectionRepositoryDB{}
+
+	tests := []struct {
+		name       string
+		inflection model.AddInflectionInput
+		field      string
+	}{
+		{name: "case without number", inflection: model.AddInflectionInput{Form: "zamku", Case: ptr(model.GrammaticalCaseGenitive)}, field: "number"},
+		{name: "case and tense", inflection: model.AddInflectionInput{Form: "zamku", Case: ptr(model.GrammaticalCaseGenitive), Number: ptr(model.GrammaticalNumberSingular), Tense: ptr(model.TensePresent)}, field: "case"},
+		{name: "no features", inflection: model.AddInflectionInput{Form: "zamku"}, field: ""},
+		{name: "verb without person", inflection: model.AddInflectionInput{Form: "robi", Tense: ptr(model.TensePresent), Number: ptr(model.GrammaticalNumberSingular)}, field: "person"},
+		{name: "gender in the present tense", inflection: model.AddInflectionInput{Form: "robi", Tense: ptr(model.TensePresent), Person: ptr(model.PersonThird), Number: ptr(model.GrammaticalNumberSingular), Gender: ptr(model.GenderFeminine)}, field: "gender"},
+		{name: "empty form", inflection: model.AddInflectionInput{Form: " ", Case: ptr(model.GrammaticalCaseGenitive), Number: ptr(model.GrammaticalNumberSingular)}, field: "form"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			polishWordID := "1"
+			_, err := repo.AddInflection(context.Background(), &polishWordID, nil, tt.inflection)
+
+			var validation *apperror.ValidationError
+			require.ErrorAs(t, err, &validation)
+			assert.Equal(t, tt.field, validation.Field)
+		})
+	}
+}
+
+func TestAddInflection(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &InflectionRepositoryDB{
+		DB: db,
+	}
+
+	polishWordID := "1"
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO inflections \\(polish_word_id, form, grammatical_case, number, person, tense, gender\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, \\$6, \\$7\\) RETURNING id, version").
+		WithArgs(polishWordID, "robiłam", nil, model.GrammaticalNumberSingular, model.PersonFirst, model.TensePast, model.GenderFeminine).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow("5", 1))
+	mock.ExpectQuery("SELECT word, version FROM lexemes WHERE id = \\$1").
+		WithArgs(polishWordID).
+		WillReturnRows(sqlmock.NewRows([]string{"word", "version"}).AddRow("robić", 1))
+	mock.ExpectCommit()
+
+	inflection, err := repo.AddInflection(context.Background(), &polishWordID, nil, model.AddInflectionInput{
+		Form:   "robiłam",
+		Number: ptr(model.GrammaticalNumberSingular),
+		Person: ptr(model.PersonFirst),
+		Tense:  ptr(model.TensePast),
+		Gender: ptr(model.GenderFeminine),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "5", inflection.ID)
+	assert.Equal(t, "robić", inflection.PolishWord.Word)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateInflectionSwitchesToVerbForm(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &InflectionRepositoryDB{
+		DB: db,
+	}
+
+	id := "5"
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT polish_word_id, form, grammatical_case, number, person, tense, gender, version FROM inflections WHERE id = \\$1").
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"polish_word_id", "form", "grammatical_case", "number", "person", "tense", "gender", "version"}).
+			AddRow("1", "robi", "NOMINATIVE", "SINGULAR", nil, nil, nil, 1))
+	mock.ExpectExec("UPDATE inflections SET form = \\$1, grammatical_case = \\$2, number = \\$3, person = \\$4, tense = \\$5, gender = \\$6, version = version \\+ 1 WHERE id = \\$7 AND version = \\$8").
+		WithArgs("robi", nil, model.GrammaticalNumberSingular, model.PersonThird, model.TensePresent, nil, id, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	inflection, err := repo.UpdateInflection(context.Background(), id, model.EditInflectionInput{
+		Person:  ptr(model.PersonThird),
+		Tense:   ptr(model.TensePresent),
+		Version: 1,
+	})
+	require.NoError(t, err)
+	assert.Nil(t, inflection.Case)
+	assert.Equal(t, model.TensePresent, *inflection.Tense)
+	assert.Equal(t, 2, inflection.Version)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDbErrorClassifiesDriverErrors(t *testing.T) {
+
+	var duplicate *apperror.DuplicateError
+	require.ErrorAs(t, dbError(&pq.Error{Code: pqUniqueViolation, Constraint: "uq_lexeme_language_word"}), &duplicate)
+	assert.Equal(t, "lexeme", duplicate.Entity)
+
+	var validation *apperror.ValidationError
+	require.ErrorAs(t, dbError(&pq.Error{Code: pqInvalidTextRepresentation}), &validation)
+
+	var internal *apperror.InternalError
+	require.ErrorAs(t, dbError(errors.New("connection refused")), &internal)
+
+	var notFound *apperror.NotFoundError
+	require.ErrorAs(t, missingReferenceOr(&pq.Error{Code: pqForeignKeyViolation}, "translation", "id", "7"), &notFound)
+	assert.Equal(t, "7", notFound.Value)
+}
+
+func TestUpdatePolishWordMatchesTranslationsByID(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &PolishWordRepositoryDB{
+		DB: db,
+	}
+
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Name: "ania", Roles: []auth.Role{auth.RoleEditor}})
+	id := "1"
+	translationID := "12"
+	removedID := "11"
+	updatedWord := "lock"
+	newWord := "castle"
+	version := 3
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, word, version FROM lexemes WHERE id = \\$1").
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "word", "version"}).AddRow(id, "zamek", 1))
+	mock.ExpectQuery("SELECT id, english_word, language, version, part_of_speech, gender, aspect, aspect_partner, usage_note FROM translations WHERE polish_word_id = \\$1 AND deleted_at IS NULL AND \\(visibility IN \\('public', 'shared'\\) OR owner = 'ania'\\) ORDER BY id").
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "english_word", "language", "version", "part_of_speech", "gender", "aspect", "aspect_partner", "usage_note"}).
+			AddRow(removedID, "padlock", "en", 1, nil, nil, nil, nil, nil).
+			AddRow(translationID, "bolt", "en", 3, nil, nil, nil, nil, nil))
+	mock.ExpectQuery("SELECT COALESCE\\(t.owner = \\$2, false\\)(.|\n)*FOR UPDATE OF t").
+		WithArgs(removedID, "ania").
+		WillReturnRows(sqlmock.NewRows([]string{"owned"}).AddRow(true))
+	mock.ExpectExec("UPDATE example_sentences SET deleted_at = now\\(\\) WHERE translation_id = ANY\\(\\$1::int\\[\\]\\) AND deleted_at IS NULL").
+		WithArgs(pq.Array([]string{removedID})).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("UPDATE translations SET deleted_at = now\\(\\) WHERE polish_word_id = \\$1 AND id = ANY\\(\\$2::int\\[\\]\\) AND deleted_at IS NULL").
+		WithArgs(id, pq.Array([]string{removedID})).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO translations \\(english_word, language, polish_word_id, part_of_speech, gender, aspect, aspect_partner, usage_note, visibility\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, \\$6, \\$7, \\$8, COALESCE\\(\\$9, \\(SELECT visibility FROM lexemes WHERE id = \\$3\\)\\)\\) RETURNING id, version").
+		WithArgs(newWord, "en", id, nil, nil, nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow("13", 1))
+	mock.ExpectExec("UPDATE translations SET english_word = \\$1, language = \\$2, part_of_speech = \\$3, gender = \\$4, aspect = \\$5, aspect_partner = \\$6, usage_note = \\$7, cefr_level = COALESCE\\(\\$8, cefr_level\\), frequency_rank = NULLIF\\(COALESCE\\(\\$9, frequency_rank\\), 0\\), visibility = COALESCE\\(\\$10, visibility\\), version = version \\+ 1 WHERE id = \\$11 AND version = \\$12").
+		WithArgs(updatedWord, "en", nil, nil, nil, nil, nil, nil, nil, nil, translationID, version).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	edits := &model.EditPolishWordInput{
+		Translations: []*model.EditTranslationInput{
+			{EnglishWord: &newWord},
+			{ID: &translationID, EnglishWord: &updatedWord, Version: &version},
+		},
+		Remove:  []string{removedID},
+		Version: 1,
+	}
+
+	pw, err := repo.UpdatePolishWord(ctx, &id, nil, edits)
+	require.NoError(t, err)
+	require.Len(t, pw.Translations, 2)
+	assert.Equal(t, translationID, pw.Translations[0].ID)
+	assert.Equal(t, updatedWord, pw.Translations[0].EnglishWord)
+	assert.Equal(t, 4, pw.Translations[0].Version)
+	assert.Equal(t, "13", pw.Translations[1].ID)
+	assert.Equal(t, newWord, pw.Translations[1].EnglishWord)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdatePolishWordUnknownTranslationID(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &PolishWordRepositoryDB{
+		DB: db,
+	}
+
+	ctx := context.Background()
+	id := "1"
+	otherTranslationID := "99"
+	updatedWord := "lock"
+	version := 1
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, word, version FROM lexemes WHERE id = \\$1").
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "word", "version"}).AddRow(id, "zamek", 1))
+	mock.ExpectQuery("SELECT id, english_word, language, version, part_of_speech, gender, aspect, aspect_partner, usage_note FROM translations WHERE polish_word_id = \\$1 AND deleted_at IS NULL AND visibility = 'public' ORDER BY id").
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "english_word", "language", "version", "part_of_speech", "gender", "aspect", "aspect_partner", "usage_note"}).AddRow("12", "bolt", "en", 1, nil, nil, nil, nil, nil))
+	mock.ExpectRollback()
+
+	edits := &model.EditPolishWordInput{
+		Translations: []*model.EditTranslationInput{
+			{ID: &otherTranslationID, EnglishWord: &updatedWord, Version: &version},
+		},
+		Version: 1,
+	}
+
+	_, err = repo.UpdatePolishWord(ctx, &id, nil, edits)
+
+	var notFound *apperror.NotFoundError
+	require.ErrorAs(t, err, &notFound)
+	assert.Equal(t, "translation", notFound.Entity)
+	assert.Equal(t, otherTranslationID, notFound.Value)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateTranslationRejectsEditAndRemoveOfSameSentence(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &TranslationRepositoryDB{
+		DB: db,
+	}
+
+	ctx := context.Background()
+	id := "1"
+	sentenceID := "5"
+	newSentencePl := "Zamek jest stary."
+	version := 1
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, english_word, language, polish_word_id, version, part_of_speech, gender, aspect, aspect_partner, usage_note FROM translations WHERE id = \\$1").
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "english_word", "language", "polish_word_id", "version", "part_of_speech", "gender", "aspect", "aspect_partner", "usage_note"}).
+			AddRow(id, "castle", "en", "1", 1, nil, nil, nil, nil, nil))
+	mock.ExpectRollback()
+
+	edits := model.EditTranslationInput{
+		ExampleSentences: []*model.EditExampleSentenceInput{
+			{ID: &sentenceID, SentencePl: &newSentencePl, Version: &version},
+		},
+		Remove: []string{sentenceID},
+	}
+
+	_, err = repo.UpdateTranslation(ctx, id, edits)
+
+	var validation *apperror.ValidationError
+	require.ErrorAs(t, err, &validation)
+	assert.Equal(t, "remove", validation.Field)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImportBatchReportsCreatedMergedSkippedAndFailed(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &ImportRepositoryDB{
+		DB: db,
+	}
+
+	ctx := context.Background()
+	a1 := model.CefrLevelA1
+	cat := []*model.AddTranslationInput{{EnglishWord: "cat", CefrLevel: &a1}}
+	records := []ImportRecord{
+		{Line: 1, Input: model.AddPolishWordInput{Word: "kot", CefrLevel: &a1, FrequencyRank: ptr(812), Translations: cat}},
+		{Line: 2, Input: model.AddPolishWordInput{Word: ""}},
+		{Line: 3, Input: model.AddPolishWordInput{Word: "dom", Translations: []*model.AddTranslationInput{{
+			EnglishWord:      "house",
+			ExampleSentences: []*model.AddExampleSentenceInput{{SentencePl: "To mój dom.", SentenceEn: "This is my house."}},
+		}}}},
+		{Line: 4, Input: model.AddPolishWordInput{Word: "kot", Translations: cat}},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO lexemes \\(word, ipa, syllabification, stressed_syllable, cefr_level, frequency_rank, visibility\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, \\$6, \\$7\\), \\(\\$8, .*\\) ON CONFLICT \\(tenant, language, word\\) WHERE deleted_at IS NULL DO UPDATE SET word = EXCLUDED.word, cefr_level = COALESCE\\(EXCLUDED.cefr_level, lexemes.cefr_level\\), frequency_rank = COALESCE\\(EXCLUDED.frequency_rank, lexemes.frequency_rank\\) RETURNING id, word, tenant <> '', visibility, \\(xmax = 0\\)").
+		WithArgs("kot", nil, nil, nil, &a1, ptr(812), "public", "dom", nil, nil, nil, nil, nil, "public").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "word", "private", "visibility", "inserted"}).
+			AddRow("1", "kot", false, "public", true).
+			AddRow("2", "dom", false, "shared", false))
+	mock.ExpectQuery("INSERT INTO translations \\(english_word, language, polish_word_id, part_of_speech, gender, aspect, aspect_partner, usage_note, cefr_level, frequency_rank, visibility\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, \\$6, \\$7, \\$8, \\$9, \\$10, \\$11\\), \\(\\$12, .*\\) ON CONFLICT .* cefr_level = COALESCE\\(EXCLUDED.cefr_level, translations.cefr_level\\)").
+		WithArgs("cat", "en", "1", nil, nil, nil, nil, nil, &a1, nil, "public", "house", "en", "2", nil, nil, nil, nil, nil, nil, nil, "shared").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "polish_word_id", "language", "english_word", "private", "visibility", "inserted"}).
+			AddRow("10", "1", "en", "cat", false, "public", true).
+			AddRow("11", "2", "en", "house", false, "shared", false))
+	mock.ExpectQuery("INSERT INTO example_sentences \\(sentence_pl, sentence_en, translation_id, visibility\\) VALUES \\(\\$1, \\$2, \\$3, \\$4\\) ON CONFLICT").
+		WithArgs("To mój dom.", "This is my house.", "11", "shared").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "translation_id", "sentence_pl", "sentence_en", "private", "visibility", "inserted"}).
+			AddRow("20", "11", "To mój dom.", "This is my house.", false, "shared", true))
+	mock.ExpectCommit()
+
+	results, err := repo.ImportBatch(ctx, records)
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+
+	assert.Equal(t, model.ImportStatusCreated, results[0].Status)
+	assert.Equal(t, model.ImportStatusFailed, results[1].Status)
+	assert.Equal(t, "word must not be empty", *results[1].Error)
+	assert.Equal(t, model.ImportStatusMerged, results[2].Status)
+	assert.Equal(t, model.ImportStatusSkipped, results[3].Status)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidateImportRecordChecksLevels(t *testing.T) {
+
+	var validation *apperror.ValidationError
+
+	unknown := model.CefrLevel("D1")
+	err := validateImportRecord(model.AddPolishWordInput{Word: "kot", CefrLevel: &unknown}, &model.Pronunciation{})
+	require.ErrorAs(t, err, &validation)
+	assert.Equal(t, "cefrLevel", validation.Field)
+
+	err = validateImportRecord(model.AddPolishWordInput{Word: "kot", Translations: []*model.AddTranslationInput{{EnglishWord: "cat", FrequencyRank: ptr(0)}}}, &model.Pronunciation{})
+	require.ErrorAs(t, err, &validation)
+	assert.Equal(t, "frequencyRank", validation.Field)
+}
+
+func TestImportBatchMergesSentenceTexts(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &ImportRepositoryDB{
+		DB: db,
+	}
+
+	house := func(texts ...*model.SentenceTextInput) model.AddPolishWordInput {
+		return model.AddPolishWordInput{Word: "dom", Translations: []*model.AddTranslationInput{{
+			EnglishWord:      "house",
+			ExampleSentences: []*model.AddExampleSentenceInput{{SentencePl: "To mój dom.", SentenceEn: "This is my house.", Texts: texts}},
+		}}}
+	}
+	records := []ImportRecord{
+		{Line: 1, Input: house(&model.SentenceTextInput{Language: "de", Text: "Das ist mein Haus."})},
+		{Line: 2, Input: house(&model.SentenceTextInput{Language: "fr", Text: "C'est ma maison."})},
+		{Line: 3, Input: house(&model.SentenceTextInput{Language: "en", Text: "It is my house."})},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO lexemes").
+		WithArgs("dom", nil, nil, nil, nil, nil, "public").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "word", "private", "visibility", "inserted"}).AddRow("2", "dom", false, "public", false))
+	mock.ExpectQuery("INSERT INTO translations").
+		WithArgs("house", "en", "2", nil, nil, nil, nil, nil, nil, nil, "public").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "polish_word_id", "language", "english_word", "private", "visibility", "inserted"}).AddRow("11", "2", "en", "house", false, "public", false))
+	mock.ExpectQuery("INSERT INTO example_sentences").
+		WithArgs("To mój dom.", "This is my house.", "11", "public").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "translation_id", "sentence_pl", "sentence_en", "private", "visibility", "inserted"}).AddRow("20", "11", "To mój dom.", "This is my house.", false, "public", false))
+	mock.ExpectQuery("INSERT INTO sentence_texts \\(example_sentence_id, language, text\\) VALUES \\(\\$1, \\$2, \\$3\\), \\(\\$4, \\$5, \\$6\\) ON CONFLICT \\(example_sentence_id, language\\) DO NOTHING").
+		WithArgs("20", "de", "Das ist mein Haus.", "20", "fr", "C'est ma maison.").
+		WillReturnRows(sqlmock.NewRows([]string{"example_sentence_id", "language"}).AddRow("20", "fr"))
+	mock.ExpectCommit()
+
+	results, err := repo.ImportBatch(context.Background(), records)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	assert.Equal(t, model.ImportStatusSkipped, results[0].Status)
+	assert.Equal(t, model.ImportStatusMerged, results[1].Status)
+	assert.Equal(t, model.ImportStatusFailed, results[2].Status)
+	assert.Equal(t, "the en text is set with sentencePl or sentenceEn", *results[2].Error)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImportBatchRetriesRecordsOneByOneAfterFailure(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &ImportRepositoryDB{
+		DB: db,
+	}
+
+	ctx := context.Background()
+	records := []ImportRecord{
+		{Line: 1, Input: model.AddPolishWordInput{Word: "kot"}},
+		{Line: 2, Input: model.AddPolishWordInput{Word: "pies"}},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO lexemes").
+		WithArgs("kot", nil, nil, nil, nil, nil, "public", "pies", nil, nil, nil, nil, nil, "public").
+		WillReturnError(&pq.Error{Code: pqStringDataTruncation})
+	mock.ExpectRollback()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO lexemes").
+		WithArgs("kot", nil, nil, nil, nil, nil, "public").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "word", "private", "visibility", "inserted"}).AddRow("1", "kot", false, "public", true))
+	mock.ExpectCommit()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO lexemes").
+		WithArgs("pies", nil, nil, nil, nil, nil, "public").
+		WillReturnError(&pq.Error{Code: pqStringDataTruncation})
+	mock.ExpectRollback()
+
+	results, err := repo.ImportBatch(ctx, records)
+	require.NoError(t, err)
+
+	assert.Equal(t, model.ImportStatusCreated, results[0].Status)
+	assert.Equal(t, model.ImportStatusFailed, results[1].Status)
+	assert.Equal(t, "value is too long", *results[1].Error)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImportBatchGeneratesMissingPronunciation(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &ImportRepositoryDB{DB: db, Pronunciation: pronunciation.Rules{}}
+	ipa := "kɔːt"
+	records := []ImportRecord{
+		{Line: 1, Input: model.AddPolishWordInput{Word: "zamek"}},
+		{Line: 2, Input: model.AddPolishWordInput{Word: "kot", Pronunciation: &model.PronunciationInput{Ipa: &ipa}}},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO lexemes").
+		WithArgs("zamek", "ˈza.mɛk", "za-mek", 1, nil, nil, "public", "kot", "kɔːt", "kot", 1, nil, nil, "public").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "word", "private", "visibility", "inserted"}).
+			AddRow("1", "zamek", false, "public", true).
+			AddRow("2", "kot", false, "public", true))
+	mock.ExpectCommit()
+
+	results, err := repo.ImportBatch(context.Background(), records)
+	require.NoError(t, err)
+
+	assert.Equal(t, model.ImportStatusCreated, results[0].Status)
+	assert.Equal(t, model.ImportStatusCreated, results[1].Status)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImportBatchKeepsPrivateRecordsApartFromSharedOnes(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &ImportRepositoryDB{
+		DB: db,
+	}
+
+	private := model.VisibilityPrivate
+	records := []ImportRecord{
+		{Line: 1, Input: model.AddPolishWordInput{Word: "kot", Translations: []*model.AddTranslationInput{{EnglishWord: "cat"}}}},
+		{Line: 2, Input: model.AddPolishWordInput{Word: "kot", Visibility: &private, Translations: []*model.AddTranslationInput{{
+			EnglishWord:      "cat",
+			ExampleSentences: []*model.AddExampleSentenceInput{{SentencePl: "Mój kot śpi.", SentenceEn: "My cat sleeps."}},
+		}}}},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO lexemes").
+		WithArgs("kot", nil, nil, nil, nil, nil, "public", "kot", nil, nil, nil, nil, nil, "private").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "word", "private", "visibility", "inserted"}).
+			AddRow("1", "kot", false, "public", false).
+			AddRow("2", "kot", true, "private", true))
+	mock.ExpectQuery("INSERT INTO translations").
+		WithArgs("cat", "en", "1", nil, nil, nil, nil, nil, nil, nil, "public", "cat", "en", "2", nil, nil, nil, nil, nil, nil, nil, "private").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "polish_word_id", "language", "english_word", "private", "visibility", "inserted"}).
+			AddRow("10", "1", "en", "cat", false, "public", false).
+			AddRow("11", "2", "en", "cat", true, "private", true))
+	mock.ExpectQuery("INSERT INTO example_sentences").
+		WithArgs("Mój kot śpi.", "My cat sleeps.", "11", "private").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "translation_id", "sentence_pl", "sentence_en", "private", "visibility", "inserted"}).
+			AddRow("20", "11", "Mój kot śpi.", "My cat sleeps.", true, "private", true))
+	mock.ExpectCommit()
+
+	results, err := repo.ImportBatch(context.Background(), records)
+	require.NoError(t, err)
+
+	assert.Equal(t, model.ImportStatusSkipped, results[0].Status)
+	assert.Equal(t, model.ImportStatusCreated, results[1].Status)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestForEachEntryGroupsRowsByWordAndTranslation(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &ExportRepositoryDB{
+		DB: db,
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT p.id, p.word, p.ipa, p.syllabification, p.stressed_syllable, p.cefr_level, p.frequency_rank, p.visibility, t.id, t.english_word, t.language, t.part_of_speech, t.gender, t.aspect, t.aspect_partner, t.usage_note, t.cefr_level, t.frequency_rank, t.visibility, es.sentence_pl, es.sentence_en, es.visibility, \\(SELECT json_agg.* FROM sentence_texts st .*FROM lexemes p LEFT JOIN translations t .* AND t.visibility = 'public' .* AND p.visibility = 'public'").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "word", "ipa", "syllabification", "stressed_syllable", "cefr_level", "frequency_rank", "visibility", "id", "english_word", "language", "part_of_speech", "gender", "aspect", "aspect_partner", "usage_note", "cefr_level", "frequency_rank", "visibility", "sentence_pl", "sentence_en", "visibility", "json_agg"}).
+			AddRow("1", "zamek", "ˈza.mɛk", "za-mek", 1, "B1", 1520, "public", "10", "castle", "en", "NOUN", "MASCULINE_INANIMATE", nil, nil, nil, "B1", nil, "public", "Zamek stoi.", "The castle stands.", "public", `[{"language": "de", "text": "Die Burg steht."}]`).
+			AddRow("1", "zamek", "ˈza.mɛk", "za-mek", 1, "B1", 1520, "public", "10", "castle", "en", "NOUN", "MASCULINE_INANIMATE", nil, nil, nil, "B1", nil, "public", "Stary zamek.", "An old castle.", "public", nil).
+			AddRow("1", "zamek", "ˈza.mɛk", "za-mek", 1, "B1", 1520, "public", "11", "lock", "en", nil, nil, nil, nil, nil, nil, nil, "public", nil, nil, nil, nil).
+			AddRow("2", "dom", nil, nil, nil, nil, nil, "public", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil))
+	mock.ExpectRollback()
+
+	var entries []*model.AddPolishWordInput
+	err = repo.ForEachEntry(context.Background(), func(entry *model.AddPolishWordInput) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Len(t, entries, 2)
+	assert.Equal(t, "zamek", entries[0].Word)
+	require.NotNil(t, entries[0].Pronunciation)
+	assert.Equal(t, "za-mek", *entries[0].Pronunciation.Syllabification)
+	assert.Equal(t, model.CefrLevelB1, *entries[0].CefrLevel)
+	assert.Equal(t, 1520, *entries[0].FrequencyRank)
+	require.Len(t, entries[0].Translations, 2)
+	require.Len(t, entries[0].Translations[0].ExampleSentences, 2)
+	assert.Equal(t, []*model.SentenceTextInput{{Language: "de", Text: "Die Burg steht."}}, entries[0].Translations[0].ExampleSentences[0].Texts)
+	assert.Empty(t, entries[0].Translations[0].ExampleSentences[1].Texts)
+	assert.Equal(t, model.GenderMasculineInanimate, *entries[0].Translations[0].Gender)
+	assert.Equal(t, model.CefrLevelB1, *entries[0].Translations[0].CefrLevel)
+	assert.Nil(t, entries[0].Translations[0].FrequencyRank)
+	assert.Equal(t, "lock", entries[0].Translations[1].EnglishWord)
+	assert.Nil(t, entries[0].Translations[1].CefrLevel)
+	assert.Empty(t, entries[0].Translations[1].ExampleSentences)
+	assert.Equal(t, "dom", entries[1].Word)
+	assert.Nil(t, entries[1].Pronunciation)
+	assert.Nil(t, entries[1].CefrLevel)
+	assert.Empty(t, entries[1].Translations)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestForEachEntryDumpsEveryEntryForAdmins(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &ExportRepositoryDB{
+		DB: db,
+	}
+
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Name: "admin", Roles: []auth.Role{auth.RoleAdmin}})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("LEFT JOIN translations t ON t.polish_word_id = p.id AND t.deleted_at IS NULL AND true LEFT JOIN example_sentences es ON es.translation_id = t.id AND es.deleted_at IS NULL AND true WHERE p.language = 'pl' AND p.deleted_at IS NULL AND true ORDER BY").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "word", "ipa", "syllabification", "stressed_syllable", "cefr_level", "frequency_rank", "visibility", "id", "english_word", "language", "part_of_speech", "gender", "aspect", "aspect_partner", "usage_note", "cefr_level", "frequency_rank", "visibility", "sentence_pl", "sentence_en", "visibility", "json_agg"}).
+			AddRow("1", "zamek", nil, nil, nil, nil, nil, "shared", "10", "castle", "en", nil, nil, nil, nil, nil, nil, nil, "shared", "Zamek stoi.", "The castle stands.", "shared", nil).
+			AddRow("2", "zamek", nil, nil, nil, nil, nil, "private", "11", "lock", "en", nil, nil, nil, nil, nil, nil, nil, "private", "Mój zamek.", "My lock.", "private", nil).
+			AddRow("3", "dom", nil, nil, nil, nil, nil, "public", "12", "house", "en", nil, nil, nil, nil, nil, nil, nil, "shared", nil, nil, nil, nil))
+	mock.ExpectRollback()
+
+	var entries []*model.AddPolishWordInput
+	err = repo.ForEachEntry(ctx, func(entry *model.AddPolishWordInput) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Len(t, entries, 3)
+	assert.Equal(t, model.VisibilityShared, *entries[0].Visibility)
+	assert.Nil(t, entries[0].Translations[0].Visibility)
+	assert.Nil(t, entries[0].Translations[0].ExampleSentences[0].Visibility)
+	assert.Equal(t, model.VisibilityPrivate, *entries[1].Visibility)
+	assert.Nil(t, entries[1].Translations[0].Visibility)
+	assert.Nil(t, entries[2].Visibility)
+	assert.Equal(t, model.VisibilityShared, *entries[2].Translations[0].Visibility)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddPolishWordGeneratesMissingPronunciation(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &PolishWordRepositoryDB{DB: db, TranslationRepo: &TranslationRepositoryDB{DB: db}, Pronunciation: pronunciation.Rules{}}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO lexemes \\(word, ipa, syllabification, stressed_syllable, visibility\\)").
+		WithArgs("zamek", "ˈza.mɛk", "za-mek", 2, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version", "ipa", "syllabification", "stressed_syllable"}).AddRow("1", 1, "ˈza.mɛk", "za-mek", 2))
+	mock.ExpectCommit()
+
+	pw, err := repo.AddPolishWord(context.Background(), model.AddPolishWordInput{
+		Word:          "zamek",
+		Translations:  []*model.AddTranslationInput{},
+		Pronunciation: &model.PronunciationInput{StressedSyllable: ptr(2)},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, pw.Pronunciation)
+	assert.Equal(t, "ˈza.mɛk", *pw.Pronunciation.Ipa)
+	assert.Equal(t, 2, *pw.Pronunciation.StressedSyllable)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdatePolishWordEditsPronunciationOnly(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &PolishWordRepositoryDB{DB: db, Pronunciation: pronunciation.Rules{}}
+	id := "1"
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, word, version FROM lexemes WHERE id = \\$1").
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "word", "version"}).AddRow(id, "zamek", 3))
+	mock.ExpectQuery("SELECT ipa, syllabification, stressed_syllable FROM lexemes WHERE id = \\$1").
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"ipa", "syllabification", "stressed_syllable"}).AddRow("ˈza.mɛk", "za-mek", 1))
+	mock.ExpectExec("UPDATE lexemes SET word = \\$1, ipa = \\$2, syllabification = \\$3, stressed_syllable = \\$4").
+		WithArgs("zamek", nil, "za-mek", 2, nil, nil, nil, id, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	pw, err := repo.UpdatePolishWord(context.Background(), &id, nil, &model.EditPolishWordInput{
+		Pronunciation: &model.PronunciationInput{Ipa: ptr(""), StressedSyllable: ptr(2)},
+		Version:       3,
+	})
+	require.NoError(t, err)
+	assert.Nil(t, pw.Pronunciation.Ipa)
+	assert.Equal(t, 2, *pw.Pronunciation.StressedSyllable)
+	assert.Equal(t, 4, pw.Version)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidatePronunciation(t *testing.T) {
+
+	tests := []struct {
+		name          string
+		pronunciation model.Pronunciation
+		field         string
+	}{
+		{name: "complete", pronunciation: model.Pronunciation{Ipa: ptr("ˈza.mɛk"), Syllabification: ptr("Za-mek"), StressedSyllable: ptr(1)}},
+		{name: "stress without syllabification", pronunciation: model.Pronunciation{StressedSyllable: ptr(3)}},
+		{name: "syllabification of another word", pronunciation: model.Pronunciation{Syllabification: ptr("za-mki")}, field: "syllabification"},
+		{name: "empty syllable", pronunciation: model.Pronunciation{Syllabification: ptr("za--mek")}, field: "syllabification"},
+		{name: "stress past the last syllable", pronunciation: model.Pronunciation{Syllabification: ptr("za-mek"), StressedSyllable: ptr(3)}, field: "stressedSyllable"},
+		{name: "stress below one", pronunciation: model.Pronunciation{StressedSyllable: ptr(-1)}, field: "stressedSyllable"},
+		{name: "ipa too long", pronunciation: model.Pronunciation{Ipa: ptr(strings.Repeat("a", maxIPALength+1))}, field: "ipa"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePronunciation("zamek", &tt.pronunciation)
+			if tt.field == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			var validation *apperror.ValidationError
+			require.ErrorAs(t, err, &validation)
+			assert.Equal(t, tt.field, validation.Field)
+		})
+	}
+}
+
+func TestAttachPolishWordAudioReturnsReplacedKey(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &AudioRepositoryDB{DB: db}
+	audio := &model.Audio{Key: "new.mp3", ContentType: "audio/mpeg", Size: 2048}
+	word := "zamek"
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM lexemes WHERE word = \\$1").
+		WithArgs(word).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1"))
+	mock.ExpectQuery("SELECT word, version FROM lexemes WHERE id = \\$1 AND deleted_at IS NULL AND visibility = 'public' FOR UPDATE").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"word", "version"}).AddRow(word, 2))
+	mock.ExpectQuery("SELECT blob_key FROM audio_recordings WHERE polish_word_id = \\$1").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"blob_key"}).AddRow("old.mp3"))
+	mock.ExpectExec("INSERT INTO audio_recordings \\(polish_word_id, blob_key, content_type, size\\) VALUES \\(\\$1, \\$2, \\$3, \\$4\\) ON CONFLICT \\(polish_word_id\\) DO UPDATE").
+		WithArgs("1", "new.mp3", "audio/mpeg", 2048).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	pw, replaced, err := repo.AttachPolishWordAudio(context.Background(), nil, &word, audio)
+	require.NoError(t, err)
+	assert.Equal(t, "old.mp3", replaced)
+	assert.Equal(t, "1", pw.ID)
+	assert.Equal(t, audio, pw.Audio)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAttachExampleSentenceAudioNotFound(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &AudioRepositoryDB{DB: db}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT sentence_pl, sentence_en, translation_id, version FROM example_sentences WHERE id = \\$1 AND deleted_at IS NULL AND visibility = 'public' FOR UPDATE").
+		WithArgs("7").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	_, replaced, err := repo.AttachExampleSentenceAudio(context.Background(), "7", &model.Audio{Key: "new.mp3", ContentType: "audio/mpeg", Size: 10})
+
+	var notFound *apperror.NotFoundError
+	require.ErrorAs(t, err, &notFound)
+	assert.Equal(t, "example sentence", notFound.Entity)
+	assert.Empty(t, replaced)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNormalizeLanguage(t *testing.T) {
+
+	tests := []struct {
+		tag      string
+		expected string
+	}{
+		{"pl", "pl"},
+		{"EN", "en"},
+		{"pt-br", "pt-BR"},
+		{"SR-latn-rs", "sr-Latn-RS"},
+		{"es-419", "es-419"},
+	}
+
+	for _, tt := range tests {
+		normalized, err := normalizeLanguage("language", tt.tag)
+		require.NoError(t, err, tt.tag)
+		assert.Equal(t, tt.expected, normalized)
+	}
+
+	for _, tag := range []string{"", "p", "polish", "en_US", "en-", "de--DE"} {
+		var validation *apperror.ValidationError
+		_, err := normalizeLanguage("language", tag)
+		require.ErrorAs(t, err, &validation, tag)
+	}
+}
+
+func TestAddLexemeStoresSentenceTextsByLanguage(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	exampleSentenceRepo := &ExampleSentenceRepositoryDB{DB: db}
+	repo := &LexemeRepositoryDB{
+		DB:              db,
+		TranslationRepo: &TranslationRepositoryDB{DB: db, ExampleSentenceRepo: exampleSentenceRepo},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO lexemes \\(language, word\\) VALUES \\(\\$1, \\$2\\) ON CONFLICT \\(tenant, language, word\\)").
+		WithArgs("de", "Hund").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow("7", 1))
+	mock.ExpectQuery("INSERT INTO translations").
+		WithArgs("dog", "en", "7", nil, nil, nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version", "part_of_speech", "gender", "aspect", "aspect_partner", "usage_note"}).AddRow("8", 1, nil, nil, nil, nil, nil))
+	mock.ExpectQuery("INSERT INTO example_sentences").
+		WithArgs("Der Hund bellt.", "The dog barks.", "8", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow("9", 1))
+	mock.ExpectQuery("SELECT t.id, t.english_word, t.language, t.version").
+		WithArgs("8").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "english_word", "language", "version", "part_of_speech", "gender", "aspect", "aspect_partner", "usage_note", "id", "word", "version"}).
+			AddRow("8", "dog", "en", 1, nil, nil, nil, nil, nil, "7", "Hund", 1))
+	mock.ExpectCommit()
+
+	lexeme, err := repo.AddLexeme(context.Background(), model.AddLexemeInput{
+		Language: "DE",
+		Lemma:    "Hund",
+		Translations: []*model.AddLexemeTranslationInput{{
+			Language: "en",
+			Text:     "dog",
+			ExampleSentences: []*model.AddLexemeExampleSentenceInput{{
+				Texts: []*model.SentenceTextInput{
+					{Language: "en", Text: "The dog barks."},
+					{Language: "de", Text: "Der Hund bellt."},
+				},
+			}},
+		}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "de", lexeme.Language)
+	require.Len(t, lexeme.Translations, 1)
+	assert.Equal(t, "en", lexeme.Translations[0].Language)
+	require.Len(t, lexeme.Translations[0].ExampleSentences, 1)
+	assert.Equal(t, "Der Hund bellt.", lexeme.Translations[0].ExampleSentences[0].SentencePl)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLexemeTranslationInputValidatesLanguages(t *testing.T) {
+
+	var validation *apperror.ValidationError
+
+	_, err := lexemeTranslationInput("de", &model.AddLexemeTranslationInput{Language: "DE", Text: "Hund"})
+	require.ErrorAs(t, err, &validation)
+	assert.Equal(t, "language", validation.Field)
+
+	_, err = lexemeTranslationInput("de", &model.AddLexemeTranslationInput{
+		Language: "en",
+		Text:     "dog",
+		ExampleSentences: []*model.AddLexemeExampleSentenceInput{{
+			Texts: []*model.SentenceTextInput{{Language: "de", Text: "Der Hund bellt."}, {Language: "fr", Text: "Le chien aboie."}},
+		}},
+	})
+	require.ErrorAs(t, err, &validation)
+	assert.Equal(t, "texts", validation.Field)
+
+	translation, err := lexemeTranslationInput("de", &model.AddLexemeTranslationInput{
+		Language: "en",
+		Text:     "dog",
+		ExampleSentences: []*model.AddLexemeExampleSentenceInput{{
+			Texts: []*model.SentenceTextInput{{Language: "de", Text: "Der Hund bellt."}, {Language: "en", Text: "The dog barks."}, {Language: "FR", Text: "Le chien aboie."}},
+		}},
+	})
+	require.NoError(t, err)
+	require.Len(t, translation.ExampleSentences, 1)
+	assert.Equal(t, []*model.SentenceTextInput{{Language: "fr", Text: "Le chien aboie."}}, translation.ExampleSentences[0].Texts)
+}
+
+func TestAddSymmetricWordRelationStoresBothDirections(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &RelationRepositoryDB{DB: db}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM lexemes WHERE word = \\$1").
+		WithArgs("duży").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1"))
+	mock.ExpectQuery("SELECT id FROM lexemes WHERE word = \\$1").
+		WithArgs("mały").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("2"))
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs("1", "2", model.RelationTypeSynonym).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery("SELECT word, version FROM lexemes WHERE id = \\$1").
+		WithArgs("2").
+		WillReturnRows(sqlmock.NewRows([]string{"word", "version"}).AddRow("mały", 1))
+	mock.ExpectQuery("INSERT INTO word_relations").
+		WithArgs("1", "2", model.RelationTypeAntonym).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("10"))
+	mock.ExpectExec("INSERT INTO word_relations").
+		WithArgs("2", "1", model.RelationTypeAntonym).
+		WillReturnResult(sqlmock.NewResult(11, 1))
+	mock.ExpectCommit()
+
+	relation, err := repo.AddWordRelation(context.Background(), model.AddWordRelationInput{
+		PolishWord:  ptr("duży"),
+		RelatedWord: ptr("mały"),
+		Type:        model.RelationTypeAntonym,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "10", relation.ID)
+	assert.Equal(t, "mały", relation.Word.Word)
+	assert.False(t, relation.Inverse)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddDirectedWordRelationRejectsCycles(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &RelationRepositoryDB{DB: db}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("LOCK TABLE word_relations").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("WITH RECURSIVE reachable").
+		WithArgs("2", "1", model.RelationTypeHypernym).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	_, err = repo.AddWordRelation(context.Background(), model.AddWordRelationInput{
+		PolishWordID:  ptr("1"),
+		RelatedWordID: ptr("2"),
+		Type:          model.RelationTypeHypernym,
+	})
+
+	var validation *apperror.ValidationError
+	require.ErrorAs(t, err, &validation)
+	assert.Equal(t, "relatedWord", validation.Field)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddWordRelationRejectsSelfLinks(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &RelationRepositoryDB{DB: db}
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	_, err = repo.AddWordRelation(context.Background(), model.AddWordRelationInput{
+		PolishWordID:  ptr("1"),
+		RelatedWordID: ptr("1"),
+		Type:          model.RelationTypeSynonym,
+	})
+
+	var validation *apperror.ValidationError
+	require.ErrorAs(t, err, &validation)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAllPolishWordsRequiresEveryTag(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &PolishWordRepositoryDB{DB: db}
+
+	mock.ExpectQuery("SELECT id, word, version FROM lexemes WHERE language = 'pl' AND deleted_at IS NULL AND visibility = 'public' AND \\(SELECT COUNT\\(\\*\\) FROM polish_word_tags pwt .* = ANY\\(\\$1::text\\[\\]\\)\\) = 2").
+		WithArgs(pq.Array([]string{"kitchen", "it"})).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "word", "version"}).AddRow("1", "czajnik", 1))
+
+	words, err := repo.GetAllPolishWords(context.Background(), &model.PolishWordFilter{Tags: []string{"Kitchen", " IT", "kitchen"}}, nil)
+	require.NoError(t, err)
+	require.Len(t, words, 1)
+	assert.Equal(t, "czajnik", words[0].Word)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddTagsToTranslation(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &TagRepositoryDB{DB: db}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM translations WHERE id = \\$1").
+		WithArgs("5").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("5"))
+	mock.ExpectExec("INSERT INTO tags \\(name\\)").
+		WithArgs(pq.Array([]string{"Travel", "IT"})).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO translation_tags \\(translation_id, tag_id\\)").
+		WithArgs("5", pq.Array([]string{"travel", "it"})).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery("FROM translation_tags x").
+		WithArgs(pq.Array([]string{"5"})).
+		WillReturnRows(sqlmock.NewRows([]string{"translation_id", "id", "name"}).
+			AddRow("5", "2", "IT").
+			AddRow("5", "1", "travel"))
+	mock.ExpectCommit()
+
+	tags, err := repo.AddTags(context.Background(), model.TagTargetInput{TranslationID: ptr("5")}, []string{"Travel", "IT", "travel "})
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Tag{{ID: "2", Name: "IT"}, {ID: "1", Name: "travel"}}, tags)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTagNamesAreValidated(t *testing.T) {
+
+	var validation *apperror.ValidationError
+
+	_, err := tagNames(nil)
+	require.ErrorAs(t, err, &validation)
+
+	_, err = tagNames([]string{"kitchen", "  "})
+	require.ErrorAs(t, err, &validation)
+	assert.Equal(t, "tags", validation.Field)
+}
+
+func TestGetAllPolishWordsFiltersAndSortsByLevel(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &PolishWordRepositoryDB{DB: db}
+
+	mock.ExpectQuery("SELECT id, word, version FROM lexemes WHERE language = 'pl' AND deleted_at IS NULL AND visibility = 'public' AND cefr_level = ANY\\(\\$1::text\\[\\]\\) AND frequency_rank <= \\$2 ORDER BY frequency_rank ASC NULLS LAST, id").
+		WithArgs(pq.Array([]string{"A1", "A2"}), 1000).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "word", "version"}).
+			AddRow("2", "być", 1).
+			AddRow("1", "dom", 1))
+
+	orderBy := model.PolishWordOrderFrequencyRank
+	words, err := repo.GetAllPolishWords(context.Background(), &model.PolishWordFilter{
+		CefrLevels:       []model.CefrLevel{model.CefrLevelA1, model.CefrLevelA2},
+		MaxFrequencyRank: ptr(1000),
+	}, &orderBy)
+	require.NoError(t, err)
+	require.Len(t, words, 2)
+	assert.Equal(t, "być", words[0].Word)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateTranslationClearsFrequencyRank(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &TranslationRepositoryDB{DB: db}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, english_word, language, polish_word_id, version").
+		WithArgs("2").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "english_word", "language", "polish_word_id", "version", "part_of_speech", "gender", "aspect", "aspect_partner", "usage_note"}).
+			AddRow("2", "house", "en", "1", 1, nil, nil, nil, nil, nil))
+	mock.ExpectExec("UPDATE translations SET english_word = \\$1").
+		WithArgs("house", "en", nil, nil, nil, nil, nil, model.CefrLevelA1, 0, nil, "2", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT word, version FROM lexemes WHERE id = \\$1").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"word", "version"}).AddRow("dom", 1))
+	mock.ExpectCommit()
+
+	cefrLevel := model.CefrLevelA1
+	translation, err := repo.UpdateTranslation(context.Background(), "2", model.EditTranslationInput{
+		CefrLevel:     &cefrLevel,
+		FrequencyRank: ptr(0),
+		Version:       ptr(1),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, &cefrLevel, translation.CefrLevel)
+	assert.Nil(t, translation.FrequencyRank)
+	assert.Equal(t, 2, translation.Version)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	assert.Error(t, validateFrequencyRank(ptr(-1), true))
+	assert.Error(t, validateFrequencyRank(ptr(0), false))
+}
+
+func TestDeleteTranslationMovesItToTrashWithItsSentences(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &TranslationRepositoryDB{DB: db}
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Name: "ania", Roles: []auth.Role{auth.RoleEditor}})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT COALESCE\\(t.owner = \\$2, false\\)").
+		WithArgs("2", "ania").
+		WillReturnRows(sqlmock.NewRows([]string{"owned"}).AddRow(true))
+	mock.ExpectQuery("SELECT id, sentence_pl, sentence_en, version FROM example_sentences WHERE translation_id = \\$1 AND deleted_at IS NULL").
+		WithArgs("2").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "sentence_pl", "sentence_en", "version"}).AddRow("5", "Mam dom.", "I have a house.", 1))
+	mock.ExpectExec("UPDATE example_sentences SET deleted_at = now\\(\\) WHERE translation_id = ANY\\(\\$1::int\\[\\]\\) AND deleted_at IS NULL").
+		WithArgs(pq.Array([]string{"2"})).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("UPDATE translations SET deleted_at = now\\(\\) WHERE id = \\$1 AND deleted_at IS NULL AND \\(visibility IN \\('public', 'shared'\\) OR owner = 'ania'\\) RETURNING id").
+		WithArgs("2").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "english_word", "language", "polish_word_id", "version", "part_of_speech", "gender", "aspect", "aspect_partner", "usage_note"}).
+			AddRow("2", "house", "en", "1", 1, nil, nil, nil, nil, nil))
+	mock.ExpectQuery("SELECT word, version FROM lexemes WHERE id = \\$1").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"word", "version"}).AddRow("dom", 1))
+	mock.ExpectCommit()
+
+	translation, err := repo.DeleteTranslation(ctx, "2")
+	require.NoError(t, err)
+	assert.Equal(t, "house", translation.EnglishWord)
+	require.Len(t, translation.ExampleSentences, 1)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRestorePolishWordBringsBackWhatWasDeletedWithIt(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &TrashRepositoryDB{DB: db, PolishWordRepo: &PolishWordRepositoryDB{DB: db}}
+	deletedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT deleted_at FROM lexemes WHERE id = \\$1 AND deleted_at IS NOT NULL AND visibility = 'public' FOR UPDATE").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"deleted_at"}).AddRow(deletedAt))
+	mock.ExpectExec("UPDATE example_sentences es SET deleted_at = NULL FROM translations t WHERE es.translation_id = t.id AND t.polish_word_id = \\$1 AND es.deleted_at = \\$2").
+		WithArgs("1", deletedAt).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("UPDATE translations SET deleted_at = NULL WHERE polish_word_id = \\$1 AND deleted_at = \\$2").
+		WithArgs("1", deletedAt).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("UPDATE lexemes SET deleted_at = NULL WHERE id = \\$1 AND deleted_at = \\$2").
+		WithArgs("1", deletedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT id, word, version FROM lexemes WHERE id = \\$1 AND language = 'pl' AND deleted_at IS NULL").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "word", "version"}).AddRow("1", "dom", 1))
+	mock.ExpectCommit()
+
+	entry, err := repo.Restore(context.Background(), "polishword:1")
+	require.NoError(t, err)
+	require.IsType(t, &model.PolishWord{}, entry)
+	assert.Equal(t, "dom", entry.(*model.PolishWord).Word)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRestoreTranslationRequiresLivePolishWord(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &TrashRepositoryDB{DB: db}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT t.deleted_at, p.deleted_at IS NOT NULL FROM translations t").
+		WithArgs("2").
+		WillReturnRows(sqlmock.NewRows([]string{"deleted_at", "deleted"}).AddRow(time.Now(), true))
+	mock.ExpectRollback()
+
+	_, err = repo.Restore(context.Background(), "translation:2")
+	var validation *apperror.ValidationError
+	require.ErrorAs(t, err, &validation)
+
+	_, err = repo.Restore(context.Background(), "polishword:abc")
+	require.ErrorAs(t, err, &validation)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRevertPolishWordWritesNewVersion(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &PolishWordRepositoryDB{DB: db}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, word, version FROM lexemes WHERE id = \\$1 AND language = 'pl' AND deleted_at IS NULL").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "word", "version"}).AddRow("1", "zamak", 3))
+	mock.ExpectQuery("SELECT snapshot, changed_at FROM polish_word_history WHERE entity_id = \\$1").
+		WithArgs("1", 1, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"snapshot", "changed_at"}).
+			AddRow(`{"id": 1, "word": "zamek", "version": 1, "ipa": "ˈza.mɛk", "cefr_level": "A2", "frequency_rank": null}`, time.Now()))
+	mock.ExpectExec("UPDATE lexemes SET word = \\$1, ipa = \\$2, syllabification = \\$3, stressed_syllable = \\$4, cefr_level = \\$5, frequency_rank = \\$6, version = version \\+ 1 WHERE id = \\$7 AND version = \\$8").
+		WithArgs("zamek", "ˈza.mɛk", nil, nil, model.CefrLevelA2, nil, "1", 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	pw, err := repo.RevertPolishWord(context.Background(), "1", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "zamek", pw.Word)
+	assert.Equal(t, 4, pw.Version)
+	assert.Equal(t, "ˈza.mɛk", *pw.Pronunciation.Ipa)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPolishWordAsOfVersionReturnsPastTranslations(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &PolishWordRepositoryDB{DB: db}
+	changedAt := time.Date(2026, 9, 1, 8, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT snapshot, changed_at FROM polish_word_history").
+		WithArgs("1", 2, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"snapshot", "changed_at"}).
+			AddRow(`{"id": 1, "word": "zamek", "version": 2, "deleted_at": null}`, changedAt))
+	mock.ExpectQuery("FROM translation_history WHERE \\(snapshot ->> 'polish_word_id'\\)::int = ANY\\(\\$1::int\\[\\]\\) AND changed_at <= \\$2").
+		WithArgs(pq.Array([]string{"1"}), changedAt).
+		WillReturnRows(sqlmock.NewRows([]string{"snapshots"}).
+			AddRow(`[{"id": 7, "english_word": "castle", "language": "en", "version": 1, "part_of_speech": "NOUN"}]`))
+	mock.ExpectQuery("FROM example_sentence_history WHERE \\(snapshot ->> 'translation_id'\\)::int = ANY\\(\\$1::int\\[\\]\\)").
+		WithArgs(pq.Array([]string{"7"}), changedAt).
+		WillReturnRows(sqlmock.NewRows([]string{"snapshots"}).
+			AddRow(`[{"id": 9, "translation_id": 7, "sentence_pl": "Zamek stoi na wzgórzu.", "sentence_en": "The castle stands on a hill.", "version": 1}]`))
+	mock.ExpectCommit()
+
+	pw, err := repo.GetPolishWordAsOf(context.Background(), ptr("1"), nil, ptr(2), nil)
+	require.NoError(t, err)
+	assert.True(t, pw.Past)
+	assert.Equal(t, 2, pw.Version)
+	require.Len(t, pw.Translations, 1)
+	assert.Equal(t, "castle", pw.Translations[0].EnglishWord)
+	assert.Equal(t, model.PartOfSpeechNoun, *pw.Translations[0].PartOfSpeech)
+	require.Len(t, pw.Translations[0].ExampleSentences, 1)
+	assert.Equal(t, "Zamek stoi na wzgórzu.", pw.Translations[0].ExampleSentences[0].SentencePl)
+
+	_, err = repo.GetPolishWordAsOf(context.Background(), ptr("1"), nil, ptr(2), &changedAt)
+	var validation *apperror.ValidationError
+	require.ErrorAs(t, err, &validation)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRevisionChangesUseAPIFieldNames(t *testing.T) {
+	changes, err := fieldChanges([]byte(`{"word": {"from": "zamak", "to": "zamek"}, "stressed_syllable": {"from": null, "to": 1}}`))
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+
+	assert.Equal(t, "stressedSyllable", changes[0].Field)
+	assert.Nil(t, changes[0].From)
+	assert.Equal(t, "1", *changes[0].To)
+	assert.Equal(t, "word", changes[1].Field)
+	assert.Equal(t, "zamak", *changes[1].From)
+}
+
+func TestRunInTxRecordsRevisionAuthor(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT set_config\\('app.author', \\$1, true\\)").
+		WithArgs("ania").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err = RunInTx(WithAuthor(context.Background(), "ania"), db, func(ctx context.Context) error { return nil })
+	require.NoError(t, err)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRunInTxKeepsOwnerApartFromAuthor(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT set_config\\('app.author', \\$1, true\\)").
+		WithArgs("import").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SELECT set_config\\('app.owner', \\$1, true\\)").
+		WithArgs("ania").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	ctx := WithOwner(WithAuthor(context.Background(), "import"), "ania")
+	err = RunInTx(ctx, db, func(ctx context.Context) error { return nil })
+	require.NoError(t, err)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetChangesIncludesEntriesWithoutHistory(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &AuditRepositoryDB{DB: db}
+	historyColumns := []string{"entity_id", "before", "after"}
+
+	// addTags creates a tag and links it to word 12, and unlinks tag 4.
+	for _, table := range []string{"polish_word_history", "translation_history", "example_sentence_history"} {
+		mock.ExpectQuery("FROM " + table + "\\s+WHERE change_id = \\$1").
+			WithArgs("change-1").
+			WillReturnRows(sqlmock.NewRows(historyColumns))
+	}
+	mock.ExpectQuery("DELETE FROM entry_changes WHERE change_id = \\$1 RETURNING id, entity, entity_id, before, after").
+		WithArgs("change-1").
+		WillReturnRows(sqlmock.NewRows([]string{"entity", "entity_id", "before", "after"}).
+			AddRow("polish_word_tag", "12:3", nil, `{"polish_word_id": 12, "tag_id": 3}`).
+			AddRow("polish_word_tag", "12:4", `{"polish_word_id": 12, "tag_id": 4}`, nil).
+			AddRow("tag", "3", nil, `{"id": 3, "name": "castles"}`))
+
+	changes, err := repo.GetChanges(context.Background(), "change-1")
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]json.RawMessage{
+		"polish_word_tag:12:4": json.RawMessage(`{"polish_word_id": 12, "tag_id": 4}`),
+	}, changes.Before)
+	assert.Len(t, changes.After, 3)
+	assert.JSONEq(t, `{"id": 3, "name": "castles"}`, string(changes.After["tag:3"]))
+	assert.Nil(t, changes.After["polish_word_tag:12:4"])
+	assert.Contains(t, changes.After, "polish_word_tag:12:4")
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAuditLogFiltersNewestFirstAfterCursor(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &AuditRepositoryDB{DB: db}
+
+	occurredAt := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "occurred_at", "operation", "operation_name", "arguments", "affected_ids", "before", "after", "actor", "client_ip", "request_id", "error"}
+
+	mock.ExpectQuery("SELECT "+auditLogColumns+" FROM audit_log WHERE actor = \\$1 AND affected_ids @> ARRAY\\[\\$2::text\\] AND id < \\$3 ORDER BY id DESC LIMIT \\$4").
+		WithArgs("ania", "polish_word:12", 9, 2).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("8", occurredAt, "updatePolishWord", "Fix", `{"id": "12"}`, "{polish_word:12}", `{}`, `{}`, "ania", "127.0.0.1", "req-2", nil).
+			AddRow("5", occurredAt, "addPolishWord", nil, `{}`, "{polish_word:12}", `{}`, `{}`, "ania", nil, "req-1", nil))
+
+	after := encodeCursor(auditLogCursorPrefix, "9")
+	result, err := repo.GetAuditLog(context.Background(), &model.AuditLogFilter{Actor: ptr("ania"), AffectedID: ptr("polish_word:12")}, ptr(1), &after)
+	require.NoError(t, err)
+
+	require.Len(t, result.Edges, 1)
+	assert.Equal(t, "8", result.Edges[0].Node.ID)
+	assert.Equal(t, []string{"polish_word:12"}, result.Edges[0].Node.AffectedIds)
+	assert.Equal(t, "Fix", *result.Edges[0].Node.OperationName)
+	assert.True(t, result.PageInfo.HasNextPage)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordAuditStoresChangesByEntry(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &AuditRepositoryDB{DB: db}
+
+	mock.ExpectExec("INSERT INTO audit_log").
+		WithArgs("deletePolishWord", nil, []byte(`{"id":"12"}`), "{\"polish_word:12\"}",
+			[]byte(`{"polish_word:12":{"id":12}}`), []byte(`{"polish_word:12":{"deleted_at":"now"}}`), nil, nil, "req-1", nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = repo.RecordAudit(context.Background(), &AuditRecord{
+		Operation:   "deletePolishWord",
+		Arguments:   []byte(`{"id":"12"}`),
+		AffectedIDs: []string{"polish_word:12"},
+		Changes: &Changes{
+			Before: map[string]json.RawMessage{"polish_word:12": []byte(`{"id":12}`)},
+			After:  map[string]json.RawMessage{"polish_word:12": []byte(`{"deleted_at":"now"}`)},
+		},
+		RequestID: "req-1",
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRunInTxTagsRevisionsWithChangeID(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT set_config\\('app.change_id', \\$1, true\\)").
+		WithArgs("req-1/addPolishWord").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err = RunInTx(WithChangeID(context.Background(), "req-1/addPolishWord"), db, func(ctx context.Context) error { return nil })
+	require.NoError(t, err)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateAPIKeyStoresOnlyItsHash(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &APIKeyRepositoryDB{DB: db}
+	key := "dict_Zm9vYmFyYmF6cXV4"
+	createdAt := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("INSERT INTO api_keys \\(name, prefix, key_hash, roles\\)").
+		WithArgs("importer", "dict_Zm9vYm", auth.HashAPIKey(key), "{\"editor\"}").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "prefix", "roles", "created_at", "last_used_at", "revoked_at"}).
+			AddRow("1", "importer", "dict_Zm9vYm", "{editor}", createdAt, nil, nil))
+
+	apiKey, err := repo.CreateAPIKey(context.Background(), "importer", key, []auth.Role{auth.RoleEditor})
+	require.NoError(t, err)
+	assert.Equal(t, []auth.Role{auth.RoleEditor}, apiKey.Roles)
+	assert.Nil(t, apiKey.RevokedAt)
+
+	_, err = repo.CreateAPIKey(context.Background(), "importer", key, []auth.Role{"owner"})
+	var validationErr *apperror.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPrincipalForAPIKeyIgnoresRevokedKeys(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &APIKeyRepositoryDB{DB: db}
+
+	mock.ExpectQuery("UPDATE api_keys SET last_used_at = now\\(\\) WHERE key_hash = \\$1 AND revoked_at IS NULL RETURNING name, roles").
+		WithArgs("live").
+		WillReturnRows(sqlmock.NewRows([]string{"name", "roles"}).AddRow("ania", "{editor,admin}"))
+	mock.ExpectQuery("UPDATE api_keys SET last_used_at").
+		WithArgs("revoked").
+		WillReturnError(sql.ErrNoRows)
+
+	principal, err := repo.PrincipalForAPIKey(context.Background(), "live")
+	require.NoError(t, err)
+	assert.Equal(t, &auth.Principal{Name: "ania", Roles: []auth.Role{auth.RoleEditor, auth.RoleAdmin}}, principal)
+
+	principal, err = repo.PrincipalForAPIKey(context.Background(), "revoked")
+	require.NoError(t, err)
+	assert.Nil(t, principal)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEditorCannotDeleteWordWithEntriesOfOthers(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &PolishWordRepositoryDB{DB: db}
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Name: "ania", Roles: []auth.Role{auth.RoleEditor}})
+	id := "12"
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT COALESCE\\(p.owner = \\$2, false\\)(.|\n)*FOR UPDATE OF p").
+		WithArgs(id, "ania").
+		WillReturnRows(sqlmock.NewRows([]string{"owned"}).AddRow(false))
+	mock.ExpectRollback()
+
+	_, err = repo.DeletePolishWord(ctx, &id, nil)
+
+	var forbidden *apperror.ForbiddenError
+	require.ErrorAs(t, err, &forbidden)
+	assert.Equal(t, "not allowed to delete polish word 12", err.Error())
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEditorCannotRemoveTranslationsOfOthersThroughUpdate(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &PolishWordRepositoryDB{DB: db}
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Name: "ania", Roles: []auth.Role{auth.RoleEditor}})
+	id := "1"
+	removedID := "11"
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, word, version FROM lexemes WHERE id = \\$1").
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "word", "version"}).AddRow(id, "zamek", 1))
+	mock.ExpectQuery("SELECT id, english_word, language, version, (.|\n)* FROM translations WHERE polish_word_id = \\$1").
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "english_word", "language", "version", "part_of_speech", "gender", "aspect", "aspect_partner", "usage_note"}).
+			AddRow(removedID, "padlock", "en", 1, nil, nil, nil, nil, nil))
+	mock.ExpectQuery("SELECT COALESCE\\(t.owner = \\$2, false\\)(.|\n)*FOR UPDATE OF t").
+		WithArgs(removedID, "ania").
+		WillReturnRows(sqlmock.NewRows([]string{"owned"}).AddRow(false))
+	mock.ExpectRollback()
+
+	_, err = repo.UpdatePolishWord(ctx, &id, nil, &model.EditPolishWordInput{Remove: []string{removedID}, Version: 1})
+
+	var forbidden *apperror.ForbiddenError
+	require.ErrorAs(t, err, &forbidden)
+	assert.Equal(t, "not allowed to delete translation 11", err.Error())
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAdminDeletesWithoutOwnershipCheck(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &ExampleSentenceRepositoryDB{DB: db}
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Name: "root", Roles: []auth.Role{auth.RoleAdmin}})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE example_sentences SET deleted_at = now\\(\\) WHERE id = \\$1").
+		WithArgs("5").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	_, err = repo.DeleteExampleSentence(ctx, "5")
+
+	var notFound *apperror.NotFoundError
+	require.ErrorAs(t, err, &notFound)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSinglePolishWordByIDSkipsOtherLanguages(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &PolishWordRepositoryDB{DB: db}
+	id := "21"
+
+	mock.ExpectQuery("SELECT id, word, version FROM lexemes WHERE id = \\$1 AND language = 'pl' AND deleted_at IS NULL").
+		WithArgs(id).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.GetSinglePolishWord(context.Background(), &id, nil)
+
+	var notFound *apperror.NotFoundError
+	require.ErrorAs(t, err, &notFound)
+	assert.Equal(t, id, notFound.Value)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetMyWordsPrefersTheCallersPrivateWords(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &PolishWordRepositoryDB{DB: db}
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Name: "o'neill"})
+
+	mock.ExpectQuery("SELECT DISTINCT ON \\(word\\) id, word, version FROM lexemes WHERE language = 'pl' AND deleted_at IS NULL AND \\(visibility IN \\('public', 'shared'\\) OR owner = 'o''neill'\\) ORDER BY word, tenant <> '' DESC").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "word", "version"}).
+			AddRow("4", "dom", 1).
+			AddRow("9", "zamek", 2))
+
+	words, err := repo.GetMyWords(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*model.PolishWord{{ID: "4", Word: "dom", Version: 1}, {ID: "9", Word: "zamek", Version: 2}}, words)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEditorCannotChangeVisibilityOfEntriesOfOthers(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &ExampleSentenceRepositoryDB{DB: db}
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Name: "ania", Roles: []auth.Role{auth.RoleEditor}})
+	private := model.VisibilityPrivate
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT sentence_pl, sentence_en, translation_id, version FROM example_sentences WHERE id = \\$1 AND deleted_at IS NULL AND \\(visibility IN \\('public', 'shared'\\) OR owner = 'ania'\\)").
+		WithArgs("5").
+		WillReturnRows(sqlmock.NewRows([]string{"sentence_pl", "sentence_en", "translation_id", "version"}).AddRow("Kot śpi.", "The cat sleeps.", "2", 1))
+	mock.ExpectQuery("SELECT COALESCE\\(owner = \\$2, false\\) FROM example_sentences WHERE id = \\$1 FOR UPDATE").
+		WithArgs("5", "ania").
+		WillReturnRows(sqlmock.NewRows([]string{"owned"}).AddRow(false))
+	mock.ExpectRollback()
+
+	_, err = repo.UpdateExampleSentence(ctx, "5", model.EditExampleSentenceInput{Visibility: &private, Version: ptr(1)})
+
+	var forbidden *apperror.ForbiddenError
+	require.ErrorAs(t, err, &forbidden)
+	assert.Equal(t, "not allowed to change the visibility of example sentence 5", err.Error())
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVisibilityWiderThanParentIsAValidationError(t *testing.T) {
+
+	err := dbError(fmt.Errorf("failed to commit transaction: %w", &pq.Error{Code: pqCheckViolation, Constraint: "chk_visibility_within_parent"}))
+
+	var validation *apperror.ValidationError
+	require.ErrorAs(t, err, &validation)
+	assert.Equal(t, "visibility", validation.Field)
+
+	var internal *apperror.InternalError
+	require.ErrorAs(t, dbError(&pq.Error{Code: pqCheckViolation, Constraint: "chk_polish_word_visibility"}), &internal)
+}
+
+func TestAddExampleSentenceStoresFurtherTexts(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &ExampleSentenceRepositoryDB{DB: db}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO example_sentences").
+		WithArgs("Kot śpi.", "The cat sleeps.", "2", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow("3", 1))
+	mock.ExpectQuery("SELECT l.language, t.language FROM example_sentences es").
+		WithArgs("3").
+		WillReturnRows(sqlmock.NewRows([]string{"language", "language"}).AddRow("pl", "en"))
+	mock.ExpectQuery("INSERT INTO sentence_texts \\(example_sentence_id, language, text\\) VALUES \\(\\$1, \\$2, \\$3\\), \\(\\$4, \\$5, \\$6\\) ON CONFLICT \\(example_sentence_id, language\\) DO NOTHING").
+		WithArgs("3", "de", "Die Katze schläft.", "3", "pt-BR", "O gato dorme.").
+		WillReturnRows(sqlmock.NewRows([]string{"example_sentence_id", "language"}).AddRow("3", "de").AddRow("3", "pt-BR"))
+	mock.ExpectQuery("SELECT t.id, t.english_word, t.language, t.version").
+		WithArgs("2").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "english_word", "language", "version", "part_of_speech", "gender", "aspect", "aspect_partner", "usage_note", "id", "word", "version"}).
+			AddRow("2", "cat", "en", 1, nil, nil, nil, nil, nil, "1", "kot", 1))
+	mock.ExpectCommit()
+
+	es, err := repo.AddExampleSentence(context.Background(), "2", model.AddExampleSentenceInput{
+		SentencePl: "Kot śpi.",
+		SentenceEn: "The cat sleeps.",
+		Texts: []*model.SentenceTextInput{
+			{Language: "de", Text: "Die Katze schläft."},
+			{Language: "PT-br", Text: "O gato dorme."},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "3", es.ID)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateExampleSentenceSetsAndRemovesTexts(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &ExampleSentenceRepositoryDB{DB: db}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT sentence_pl, sentence_en, translation_id, version FROM example_sentences WHERE id = \\$1").
+		WithArgs("3").
+		WillReturnRows(sqlmock.NewRows([]string{"sentence_pl", "sentence_en", "translation_id", "version"}).AddRow("Kot śpi.", "The cat sleeps.", "2", 1))
+	mock.ExpectExec("UPDATE example_sentences SET sentence_pl = \\$1").
+		WithArgs("Kot śpi.", "The cat sleeps.", nil, "3", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT l.language, t.language FROM example_sentences es").
+		WithArgs("3").
+		WillReturnRows(sqlmock.NewRows([]string{"language", "language"}).AddRow("pl", "en"))
+	mock.ExpectExec("INSERT INTO sentence_texts \\(example_sentence_id, language, text\\) VALUES \\(\\$1, \\$2, \\$3\\) ON CONFLICT \\(example_sentence_id, language\\) DO UPDATE SET text = EXCLUDED.text").
+		WithArgs("3", "de", "Die Katze schläft.").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM sentence_texts WHERE example_sentence_id = \\$1 AND language = \\$2").
+		WithArgs("3", "fr").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT t.id, t.english_word, t.language, t.version").
+		WithArgs("2").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "english_word", "language", "version", "part_of_speech", "gender", "aspect", "aspect_partner", "usage_note", "id", "word", "version"}).
+			AddRow("2", "cat", "en", 1, nil, nil, nil, nil, nil, "1", "kot", 1))
+	mock.ExpectCommit()
+
+	es, err := repo.UpdateExampleSentence(context.Background(), "3", model.EditExampleSentenceInput{
+		Texts:       []*model.SentenceTextInput{{Language: "de", Text: "Die Katze schläft."}},
+		RemoveTexts: []string{"FR"},
+		Version:     ptr(1),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, es.Version)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFurtherSentenceTextsRejectsTheSentencePair(t *testing.T) {
+
+	var validation *apperror.ValidationError
+
+	_, err := furtherSentenceTexts("pl", "en", []*model.SentenceTextInput{{Language: "EN", Text: "The cat sleeps."}})
+	require.ErrorAs(t, err, &validation)
+	assert.Equal(t, "texts", validation.Field)
+	assert.Equal(t, "the en text is set with sentencePl or sentenceEn", validation.Message)
+
+	_, err = furtherSentenceTexts("pl", "en", []*model.SentenceTextInput{{Language: "de", Text: "Die Katze schläft."}, {Language: "DE", Text: "Die Katze ruht."}})
+	require.ErrorAs(t, err, &validation)
+	assert.Equal(t, "an example sentence has more than one de text", validation.Message)
+
+	_, err = furtherSentenceTexts("pl", "en", []*model.SentenceTextInput{{Language: "de", Text: " "}})
+	require.ErrorAs(t, err, &validation)
+	assert.Equal(t, "the de text must not be empty", validation.Message)
+}
+
+func TestGetTextsByExampleSentenceIDsPutsTheSentencePairFirst(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &ExampleSentenceRepositoryDB{DB: db}
+
+	mock.ExpectQuery("SELECT st.example_sentence_id, st.language, st.text FROM sentence_texts st .* ORDER BY st.example_sentence_id, st.language = l.language DESC, st.language = t.language DESC, st.language").
+		WithArgs(pq.Array([]string{"3", "4"})).
+		WillReturnRows(sqlmock.NewRows([]string{"example_sentence_id", "language", "text"}).
+			AddRow("3", "pl", "Kot śpi.").
+			AddRow("3", "en", "The cat sleeps.").
+			AddRow("3", "de", "Die Katze schläft."))
+
+	texts, err := repo.GetTextsByExampleSentenceIDs(context.Background(), []string{"3", "4"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []*model.SentenceText{
+		{Language: "pl", Text: "Kot śpi."},
+		{Language: "en", Text: "The cat sleeps."},
+		{Language: "de", Text: "Die Katze schläft."},
+	}, texts["3"])
+	assert.Empty(t, texts["4"])
+	assert.NotNil(t, texts["4"])
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRemoveWordRelationOnlyFindsVisibleWords(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &RelationRepositoryDB{DB: db}
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Name: "ania", Roles: []auth.Role{auth.RoleEditor}})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("DELETE FROM word_relations WHERE id = \\$1 AND polish_word_id IN \\(SELECT id FROM lexemes WHERE \\(visibility IN \\('public', 'shared'\\) OR owner = 'ania'\\)\\) AND related_word_id IN \\(SELECT id FROM lexemes WHERE \\(visibility IN \\('public', 'shared'\\) OR owner = 'ania'\\)\\) RETURNING").
+		WithArgs("4").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	_, err = repo.RemoveWordRelation(ctx, "4")
+
+	var notFound *apperror.NotFoundError
+	require.ErrorAs(t, err, &notFound)
+	assert.Equal(t, "word relation", notFound.Entity)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// Polish text is matched against the query as typed ($1) and against the query
+// with every word replaced by its lemma ($3), so "psów" finds "pies". Like the
+// rest of the Polish–English API, search only covers Polish lexemes and their
+// English translations. The %s stands for the condition that leaves out hits
+// the caller may not see; an entry that is visible has a visible parent too.
+var searchQueriesByScope = map[model.SearchScope]string{
+	model.SearchScopePolishWords: `
+		SELECT 'POLISH_WORDS' AS scope, p.id,
+			ts_rank(to_tsvector('polish', p.word), q) AS score,
+			ts_headline('polish', p.word, q) AS snippet
+		FROM lexemes p, (websearch_to_tsquery('polish', $1) || websearch_to_tsquery('polish', $3)) q
+		WHERE p.language = 'pl' AND p.deleted_at IS NULL AND %s AND to_tsvector('polish', p.word) @@ q`,
+
+	model.SearchScopeTranslations: `
+		SELECT 'TRANSLATIONS' AS scope, t.id,
+			ts_rank(to_tsvector('english', t.english_word), q) AS score,
+			ts_headline('english', t.english_word, q) AS snippet
+		FROM translations t, websearch_to_tsquery('english', $1) q
+		WHERE t.language = 'en' AND t.deleted_at IS NULL AND %s AND to_tsvector('english', t.english_word) @@ q`,
+
+	model.SearchScopeExampleSentences: `
+		SELECT 'EXAMPLE_SENTENCES' AS scope, es.id,
+			ts_rank(to_tsvector('polish', es.sentence_pl), qpl) + ts_rank(to_tsvector('english', es.sentence_en), qen) AS score,
+			CASE WHEN to_tsvector('polish', es.sentence_pl) @@ qpl
+				THEN ts_headline('polish', es.sentence_pl, qpl)
+				ELSE ts_headline('english', es.sentence_en, qen)
+			END AS snippet
+		FROM example_sentences es
+			JOIN translations t ON t.id = es.translation_id
+			JOIN lexemes p ON p.id = t.polish_word_id,
+			(websearch_to_tsquery('polish', $1) || websearch_to_tsquery('polish', $3)) qpl,
+			websearch_to_tsquery('english', $1) qen
+		WHERE p.language = 'pl' AND t.language = 'en'
+		AND es.deleted_at IS NULL AND %s
+		AND (to_tsvector('polish', es.sentence_pl) @@ qpl OR to_tsvector('english', es.sentence_en) @@ qen)`,
+}
+
+// searchAliasesByScope names the table of the hits of each scope, whose
+// visibility the %s in its query is about.
+var searchAliasesByScope = map[model.SearchScope]string{
+	model.SearchScopePolishWords:      "p",
+	model.SearchScopeTranslations:     "t",
+	model.SearchScopeExampleSentences: "es",
+}
+
+// buildSearchQuery also reports whether the query uses the lemmatized query
+// text, which must then be passed as $3.
+func buildSearchQuery(ctx context.Context, scope []model.SearchScope) (string, bool, error) {
+	if len(scope) == 0 {
+		scope = model.AllSearchScope
+	}
+
+	var parts []string
+	lemmatized := false
+	seen := map[model.SearchScope]bool{}
+	for _, s := range scope {
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+
+		part, ok := searchQueriesByScope[s]
+		if !ok {
+			return "", false, validationError("scope", fmt.Sprintf("unsupported search scope %q", s))
+		}
+		parts = append(parts, fmt.Sprintf(part, visibleTo(ctx, searchAliasesByScope[s])))
+		lemmatized = lemmatized || s != model.SearchScopeTranslations
+	}
+
+	return "SELECT scope, id, score, snippet FROM (" + strings.Join(parts, " UNION ALL ") + ") hits ORDER BY score DESC, scope, id LIMIT $2", lemmatized, nil
+}
+
+func resolveSearchLimit(limit *int) (int, error) {
+	if limit == nil {
+		return defaultSearchLimit, nil
+	}
+	if *limit < 0 {
+		return 0, validationError("limit", "limit must not be negative")
+	}
+	if *limit > maxSearchLimit {
+		return maxSearchLimit, nil
+	}
+	return *limit, nil
+}
+
+// loadSearchResults loads the entries of one scope with a single query and
+// returns them by id. The search query has already left out Polish words in
+// other languages, so the plain lookups by id are enough here.
+func (sr *SearchRepositoryDB) loadSearchResults(ctx context.Context, scope model.SearchScope, ids []string) (map[string]model.SearchResult, error) {
+	results := make(map[string]model.SearchResult, len(ids))
+
+	switch scope {
+	case model.SearchScopePolishWords:
+		polishWords, err := sr.PolishWordRepo.GetPolishWordsByIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for id, pw := range polishWords {
+			results[id] = pw
+		}
+	case model.SearchScopeTranslations:
+		translations, err := sr.TranslationRepo.GetTranslationsByIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for id, translation := range translations {
+			results[id] = translation
+		}
+	case model.SearchScopeExampleSentences:
+		exampleSentences, err := sr.ExampleSentenceRepo.GetExampleSentencesByIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for id, es := range exampleSentences {
+			results[id] = es
+		}
+	default:
+		return nil, fmt.Errorf("unsupported search scope %q", scope)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/lemmatizer"
+)
+
+type SearchRepositoryDB struct {
+	DB                  *sql.DB
+	PolishWordRepo      *PolishWordRepositoryDB
+	TranslationRepo     *TranslationRepositoryDB
+	ExampleSentenceRepo *ExampleSentenceRepositoryDB
+	Lemmatizer          lemmatizer.Lemmatizer
+}
+
+func (sr *SearchRepositoryDB) Search(ctx context.Context, query string, scope []model.SearchScope, limit *int) ([]*model.SearchHit, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, validationError("query", "search query must not be empty")
+	}
+
+	resultLimit, err := resolveSearchLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	searchQuery, lemmatized, err := buildSearchQuery(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []any{query, resultLimit}
+	if lemmatized {
+		args = append(args, lemmatizer.Normalize(sr.Lemmatizer, query))
+	}
+
+	rows, err := conn(ctx, sr.DB).QueryContext(ctx, searchQuery, args...)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	type rawHit struct {
+		scope model.SearchScope
+		id    string
+		hit   *model.SearchHit
+	}
+
+	var rawHits []rawHit
+	for rows.Next() {
+		raw := rawHit{hit: &model.SearchHit{}}
+		if err := rows.Scan(&raw.scope, &raw.id, &raw.hit.Score, &raw.hit.Snippet); err != nil {
+			return nil, dbError(err)
+		}
+		rawHits = append(rawHits, raw)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, dbError(err)
+	}
+
+	var scopes []model.SearchScope
+	idsByScope := map[model.SearchScope][]string{}
+	for _, raw := range rawHits {
+		if _, ok := idsByScope[raw.scope]; !ok {
+			scopes = append(scopes, raw.scope)
+		}
+		idsByScope[raw.scope] = append(idsByScope[raw.scope], raw.id)
+	}
+
+	resultsByScope := make(map[model.SearchScope]map[string]model.SearchResult, len(scopes))
+	for _, s := range scopes {
+		results, err := sr.loadSearchResults(ctx, s, idsByScope[s])
+		if err != nil {
+			return nil, dbError(err)
+		}
+		resultsByScope[s] = results
+	}
+
+	// A hit deleted between the two queries has nothing left to show.
+	hits := []*model.SearchHit{}
+	for _, raw := range rawHits {
+		result, ok := resultsByScope[raw.scope][raw.id]
+		if !ok {
+			continue
+		}
+		raw.hit.Result = result
+
+		hits = append(hits, raw.hit)
+	}
+
+	return hits, nil
+}
@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+)
+
+type SearchRepositoryInterface interface {
+	Search(ctx context.Context, query string, scope []model.SearchScope, limit *int) ([]*model.SearchHit, error)
+}
@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+)
+
+// sentenceTextKey names one text of a sentence in sentence_texts.
+type sentenceTextKey struct {
+	exampleSentenceID string
+	language          string
+}
+
+type sentenceTextRow struct {
+	key  sentenceTextKey
+	text string
+}
+
+// furtherSentenceTexts checks the texts a sentence has besides sentencePl and
+// sentenceEn. Those two are the texts in the languages of the lexeme and the
+// translation, so texts must not repeat either language.
+func furtherSentenceTexts(lexemeLanguage, translationLanguage string, texts []*model.SentenceTextInput) ([]*model.SentenceText, error) {
+	further := make([]*model.SentenceText, 0, len(texts))
+	seen := make(map[string]bool, len(texts))
+
+	for _, text := range texts {
+		if text == nil {
+			return nil, validationError("texts", "texts must not contain null entries")
+		}
+		language, err := normalizeLanguage("texts", text.Language)
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(text.Text) == "" {
+			return nil, validationError("texts", fmt.Sprintf("the %s text must not be empty", language))
+		}
+		if language == lexemeLanguage || language == translationLanguage {
+			return nil, validationError("texts", fmt.Sprintf("the %s text is set with sentencePl or sentenceEn", language))
+		}
+		if seen[language] {
+			return nil, validationError("texts", fmt.Sprintf("an example sentence has more than one %s text", language))
+		}
+		seen[language] = true
+		further = append(further, &model.SentenceText{Language: language, Text: text.Text})
+	}
+
+	return further, nil
+}
+
+// sentenceLanguages returns the languages of the lexeme and the translation a
+// sentence belongs to.
+func sentenceLanguages(ctx context.Context, db DBTX, exampleSentenceID string) (string, string, error) {
+	var lexemeLanguage, translationLanguage string
+
+	err := db.QueryRowContext(ctx, `
+		SELECT l.language, t.language
+		FROM example_sentences es
+		JOIN translations t ON t.id = es.translation_id
+		JOIN lexemes l ON l.id = t.polish_word_id
+		WHERE es.id = $1`, exampleSentenceID).
+		Scan(&lexemeLanguage, &translationLanguage)
+	if err != nil {
+		return "", "", notFoundOr(err, "example sentence", "id", exampleSentenceID)
+	}
+
+	return lexemeLanguage, translationLanguage, nil
+}
+
+// addSentenceTexts stores the further texts of a sentence that was just
+// upserted. Like the rest of an upsert, a language the sentence already has
+// keeps its text.
+func addSentenceTexts(ctx context.Context, db DBTX, exampleSentenceID string, texts []*model.SentenceTextInput) error {
+	if len(texts) == 0 {
+		return nil
+	}
+
+	lexemeLanguage, translationLanguage, err := sentenceLanguages(ctx, db, exampleSentenceID)
+	if err != nil {
+		return err
+	}
+
+	further, err := furtherSentenceTexts(lexemeLanguage, translationLanguage, texts)
+	if err != nil {
+		return err
+	}
+
+	rows := make([]sentenceTextRow, len(further))
+	for i, text := range further {
+		rows[i] = sentenceTextRow{key: sentenceTextKey{exampleSentenceID: exampleSentenceID, language: text.Language}, text: text.Text}
+	}
+
+	if _, err := insertSentenceTexts(ctx, db, rows); err != nil {
+		return dbError(err)
+	}
+	return nil
+}
+
+// editSentenceTexts sets and removes further texts of an existing sentence.
+func editSentenceTexts(ctx context.Context, db DBTX, exampleSentenceID string, texts []*model.SentenceTextInput, remove []string) error {
+	if len(texts) == 0 && len(remove) == 0 {
+		return nil
+	}
+
+	lexemeLanguage, translationLanguage, err := sentenceLanguages(ctx, db, exampleSentenceID)
+	if err != nil {
+		return err
+	}
+
+	further, err := furtherSentenceTexts(lexemeLanguage, translationLanguage, texts)
+	if err != nil {
+		return err
+	}
+
+	removed := make([]string, 0, len(remove))
+	for _, tag := range remove {
+		language, err := normalizeLanguage("removeTexts", tag)
+		if err != nil {
+			return err
+		}
+		if language == lexemeLanguage || language == translationLanguage {
+			return validationError("removeTexts", fmt.Sprintf("the %s text of an example sentence cannot be removed", language))
+		}
+		for _, text := range further {
+			if text.Language == language {
+				return validationError("removeTexts", fmt.Sprintf("the %s text cannot be set and removed in the same update", language))
+			}
+		}
+		removed = append(removed, language)
+	}
+
+	for _, text := range further {
+		_, err := db.ExecContext(ctx,
+			"INSERT INTO sentence_texts (example_sentence_id, language, text) VALUES ($1, $2, $3) ON CONFLICT (example_sentence_id, language) DO UPDATE SET text = EXCLUDED.text",
+			exampleSentenceID, text.Language, text.Text)
+		if err != nil {
+			return dbError(err)
+		}
+	}
+
+	for _, language := range removed {
+		if _, err := db.ExecContext(ctx, "DELETE FROM sentence_texts WHERE example_sentence_id = $1 AND language = $2", exampleSentenceID, language); err != nil {
+			return dbError(err)
+		}
+	}
+
+	return nil
+}
+
+// insertSentenceTexts adds texts with one multi-row statement per chunk and
+// reports the ones that were new. DO NOTHING returns no row for a language a
+// sentence already has, which therefore keeps its text.
+func insertSentenceTexts(ctx context.Context, db DBTX, texts []sentenceTextRow) (map[sentenceTextKey]bool, error) {
+	inserted := make(map[sentenceTextKey]bool, len(texts))
+
+	for chunk := range slices.Chunk(texts, importChunkSize) {
+		args := make([]any, 0, 3*len(chunk))
+		for _, text := range chunk {
+			args = append(args, text.key.exampleSentenceID, text.key.language, text.text)
+		}
+
+		rows, err := db.QueryContext(ctx,
+			"INSERT INTO sentence_texts (example_sentence_id, language, text) VALUES "+valuesPlaceholders(len(chunk), 3)+
+				" ON CONFLICT (example_sentence_id, language) DO NOTHING RETURNING example_sentence_id, language", args...)
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			var key sentenceTextKey
+			if err := rows.Scan(&key.exampleSentenceID, &key.language); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			inserted[key] = true
+		}
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return inserted, nil
+}
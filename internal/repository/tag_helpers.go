@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/lib/pq"
+)
+
+const maxTagNameLength = 100
+
+// tagLink names the table that links tags to one kind of entry.
+type tagLink struct {
+	table  string
+	column string
+	id     string
+}
+
+var (
+	polishWordTags  = tagLink{table: "polish_word_tags", column: "polish_word_id"}
+	translationTags = tagLink{table: "translation_tags", column: "translation_id"}
+)
+
+// tagNames trims the names and drops the ones repeated in another case. The
+// first spelling of a new tag is the one that is stored.
+func tagNames(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, validationError("tags", "at least one tag must be given")
+	}
+
+	seen := map[string]bool{}
+	var names []string
+	for _, tag := range tags {
+		name := strings.TrimSpace(tag)
+		if name == "" {
+			return nil, validationError("tags", "tag names cannot be empty")
+		}
+		if utf8.RuneCountInString(name) > maxTagNameLength {
+			return nil, validationError("tags", fmt.Sprintf("tag names can be at most %d characters long", maxTagNameLength))
+		}
+
+		key := strings.ToLower(name)
+		if !seen[key] {
+			seen[key] = true
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+// tagKeys returns the names as they are compared against LOWER(tags.name).
+func tagKeys(names []string) []string {
+	keys := make([]string, len(names))
+	for i, name := range names {
+		keys[i] = strings.ToLower(strings.TrimSpace(name))
+	}
+	return keys
+}
+
+func resolveTagTarget(ctx context.Context, db DBTX, target model.TagTargetInput) (tagLink, error) {
+	if target.TranslationID != nil {
+		if target.PolishWordID != nil || target.PolishWord != nil {
+			return tagLink{}, validationError("target", "either a Polish word or a translation must be given, not both")
+		}
+
+		var translationID string
+		err := db.QueryRowContext(ctx, "SELECT id FROM translations WHERE id = $1 AND "+notDeleted+" AND "+visibleTo(ctx, ""), *target.TranslationID).Scan(&translationID)
+		if err != nil {
+			return tagLink{}, notFoundOr(err, "translation", "id", *target.TranslationID)
+		}
+
+		link := translationTags
+		link.id = translationID
+		return link, nil
+	}
+
+	if target.PolishWordID == nil && target.PolishWord == nil {
+		return tagLink{}, validationError("target", "either a Polish word or a translation must be given")
+	}
+
+	polishWordID, err := getTargetPolishWordID(ctx, db, target.PolishWordID, target.PolishWord)
+	if err != nil {
+		return tagLink{}, err
+	}
+
+	// Ids given directly are checked here rather than left to the foreign key,
+	// so that a missing word is reported as not found.
+	if _, err := prepareWordWithId(ctx, db, polishWordID); err != nil {
+		return tagLink{}, err
+	}
+
+	link := polishWordTags
+	link.id = *polishWordID
+	return link, nil
+}
+
+func getTargetTags(ctx context.Context, db DBTX, link tagLink) ([]*model.Tag, error) {
+	tags, err := getTagsByOwnerIDs(ctx, db, link, []string{link.id})
+	if err != nil {
+		return nil, err
+	}
+	return tags[link.id], nil
+}
+
+func getTagsByOwnerIDs(ctx context.Context, db DBTX, link tagLink, ownerIDs []string) (map[string][]*model.Tag, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT x.`+link.column+`, g.id, g.name
+		FROM `+link.table+` x
+		JOIN tags g ON g.id = x.tag_id
+		WHERE x.`+link.column+` = ANY($1::int[])
+		ORDER BY LOWER(g.name)`, pq.Array(ownerIDs))
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	tags := make(map[string][]*model.Tag, len(ownerIDs))
+	for _, ownerID := range ownerIDs {
+		tags[ownerID] = []*model.Tag{}
+	}
+
+	for rows.Next() {
+		var ownerID string
+		var tag model.Tag
+		if err := rows.Scan(&ownerID, &tag.ID, &tag.Name); err != nil {
+			return nil, dbError(err)
+		}
+		tags[ownerID] = append(tags[ownerID], &tag)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, dbError(err)
+	}
+
+	return tags, nil
+}
+
+// tagFilterCondition matches the lexemes rows tagged as match asks,
+// numbering its placeholder after the len(args) arguments already bound.
+func tagFilterCondition(tags []string, match *model.TagMatch, args []any) (string, []any) {
+	seen := map[string]bool{}
+	var keys []string
+	for _, key := range tagKeys(tags) {
+		if key != "" && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	if len(keys) == 0 {
+		return "", args
+	}
+
+	args = append(args, pq.Array(keys))
+	if match != nil && *match == model.TagMatchAny {
+		return fmt.Sprintf(`EXISTS (SELECT 1 FROM polish_word_tags pwt JOIN tags g ON g.id = pwt.tag_id
+			WHERE pwt.polish_word_id = lexemes.id AND LOWER(g.name) = ANY($%d::text[]))`, len(args)), args
+	}
+
+	return fmt.Sprintf(`(SELECT COUNT(*) FROM polish_word_tags pwt JOIN tags g ON g.id = pwt.tag_id
+		WHERE pwt.polish_word_id = lexemes.id AND LOWER(g.name) = ANY($%d::text[])) = %d`, len(args), len(keys)), args
+}
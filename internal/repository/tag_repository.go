@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/lib/pq"
+)
+
+type TagRepositoryDB struct {
+	DB *sql.DB
+}
+
+func (tr *TagRepositoryDB) AddTags(ctx context.Context, target model.TagTargetInput, tags []string) ([]*model.Tag, error) {
+	names, err := tagNames(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return inTx(ctx, tr.DB, func(ctx context.Context) ([]*model.Tag, error) {
+		link, err := resolveTagTarget(ctx, conn(ctx, tr.DB), target)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = conn(ctx, tr.DB).ExecContext(ctx,
+			"INSERT INTO tags (name) SELECT unnest($1::text[]) ON CONFLICT ((LOWER(name))) DO NOTHING", pq.Array(names))
+		if err != nil {
+			return nil, dbError(err)
+		}
+
+		_, err = conn(ctx, tr.DB).ExecContext(ctx, `
+			INSERT INTO `+link.table+` (`+link.column+`, tag_id)
+			SELECT $1, id FROM tags WHERE LOWER(name) = ANY($2::text[])
+			ON CONFLICT DO NOTHING`, link.id, pq.Array(tagKeys(names)))
+		if err != nil {
+			return nil, dbError(err)
+		}
+
+		return getTargetTags(ctx, conn(ctx, tr.DB), link)
+	})
+}
+
+// RemoveTags leaves the tags themselves in place, so curated topics keep
+// existing while they are not used.
+func (tr *TagRepositoryDB) RemoveTags(ctx context.Context, target model.TagTargetInput, tags []string) ([]*model.Tag, error) {
+	names, err := tagNames(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return inTx(ctx, tr.DB, func(ctx context.Context) ([]*model.Tag, error) {
+		link, err := resolveTagTarget(ctx, conn(ctx, tr.DB), target)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = conn(ctx, tr.DB).ExecContext(ctx, `
+			DELETE FROM `+link.table+` x USING tags g
+			WHERE g.id = x.tag_id AND x.`+link.column+` = $1 AND LOWER(g.name) = ANY($2::text[])`,
+			link.id, pq.Array(tagKeys(names)))
+		if err != nil {
+			return nil, dbError(err)
+		}
+
+		return getTargetTags(ctx, conn(ctx, tr.DB), link)
+	})
+}
+
+func (tr *TagRepositoryDB) GetTagUsage(ctx context.Context) ([]*model.TagUsage, error) {
+	rows, err := conn(ctx, tr.DB).QueryContext(ctx, `
+		SELECT g.id, g.name,
+			(SELECT COUNT(*) FROM polish_word_tags x JOIN lexemes p ON p.id = x.polish_word_id
+				WHERE x.tag_id = g.id AND p.deleted_at IS NULL AND `+visibleTo(ctx, "p")+`),
+			(SELECT COUNT(*) FROM translation_tags x JOIN translations t ON t.id = x.translation_id
+				WHERE x.tag_id = g.id AND t.deleted_at IS NULL AND `+visibleTo(ctx, "t")+`)
+		FROM tags g
+		ORDER BY LOWER(g.name)`)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	usage := []*model.TagUsage{}
+	for rows.Next() {
+		tagUsage := model.TagUsage{Tag: &model.Tag{}}
+		if err := rows.Scan(&tagUsage.Tag.ID, &tagUsage.Tag.Name, &tagUsage.PolishWordCount, &tagUsage.TranslationCount); err != nil {
+			return nil, dbError(err)
+		}
+		usage = append(usage, &tagUsage)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, dbError(err)
+	}
+
+	return usage, nil
+}
+
+func (tr *TagRepositoryDB) GetTagsByPolishWordIDs(ctx context.Context, polishWordIDs []string) (map[string][]*model.Tag, error) {
+	return getTagsByOwnerIDs(ctx, conn(ctx, tr.DB), polishWordTags, polishWordIDs)
+}
+
+func (tr *TagRepositoryDB) GetTagsByTranslationIDs(ctx context.Context, translationIDs []string) (map[string][]*model.Tag, error) {
+	return getTagsByOwnerIDs(ctx, conn(ctx, tr.DB), translationTags, translationIDs)
+}
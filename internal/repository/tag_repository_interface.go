@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+)
+
+type TagRepositoryInterface interface {
+	AddTags(ctx context.Context, target model.TagTargetInput, tags []string) ([]*model.Tag, error)
+	RemoveTags(ctx context.Context, target model.TagTargetInput, tags []string) ([]*model.Tag, error)
+	GetTagUsage(ctx context.Context) ([]*model.TagUsage, error)
+	GetTagsByPolishWordIDs(ctx context.Context, polishWordIDs []string) (map[string][]*model.Tag, error)
+	GetTagsByTranslationIDs(ctx context.Context, translationIDs []string) (map[string][]*model.Tag, error)
+}
@@ -3,36 +3,37 @@ package repository
 import (
 	"context"
 	"fmt"
+	"unicode/utf8"
 
 	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
 )
 
-func (tr *TranslationRepositoryDB) getTargetPolishWordID(ctx context.Context, polishWordID *string, polishWord *string) (*string, error) {
+func getTargetPolishWordID(ctx context.Context, db DBTX, polishWordID *string, polishWord *string) (*string, error) {
 
 	var targetPolishWordID string
 
 	if polishWordID != nil {
 		targetPolishWordID = *polishWordID
 	} else if polishWord != nil {
-		err := tr.DB.QueryRowContext(ctx, "SELECT id FROM polish_words WHERE word = $1", *polishWord).Scan(&targetPolishWordID)
+		err := db.QueryRowContext(ctx, "SELECT id FROM lexemes WHERE word = $1 AND "+polishLexemesOnly+" AND "+visibleTo(ctx, "")+" ORDER BY "+privateFirst+" LIMIT 1", *polishWord).Scan(&targetPolishWordID)
 		if err != nil {
-			return nil, err
+			return nil, notFoundOr(err, "polish word", "word", *polishWord)
 		}
 	} else {
-		return nil, fmt.Errorf("either polishWordID or polishWord word must be provided")
+		return nil, validationError("", "either polishWordID or polishWord word must be provided")
 	}
 
 	return &targetPolishWordID, nil
 }
 
-func (tr *TranslationRepositoryDB) prepareWordWithId(ctx context.Context, targetPolishWordID *string) (*model.PolishWord, error) {
+func prepareWordWithId(ctx context.Context, db DBTX, targetPolishWordID *string) (*model.PolishWord, error) {
 
 	var word string
 	var version int
-	err := tr.DB.QueryRowContext(ctx, "SELECT word, version FROM polish_words WHERE id = $1", *targetPolishWordID).Scan(&word, &version)
+	err := db.QueryRowContext(ctx, "SELECT word, version FROM lexemes WHERE id = $1 AND "+polishLexemesOnly+" AND "+visibleTo(ctx, ""), *targetPolishWordID).Scan(&word, &version)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch polish word for id %s: %w", *targetPolishWordID, err)
+		return nil, notFoundOr(err, "polish word", "id", *targetPolishWordID)
 	}
 
 	return &model.PolishWord{
@@ -41,3 +42,115 @@ func (tr *TranslationRepositoryDB) prepareWordWithId(ctx context.Context, target
 		Version: version,
 	}, nil
 }
+
+const (
+	translationGrammarColumns          = "part_of_speech, gender, aspect, aspect_partner, usage_note"
+	qualifiedTranslationGrammarColumns = "t.part_of_speech, t.gender, t.aspect, t.aspect_partner, t.usage_note"
+
+	maxUsageNoteLength = 500
+)
+
+// translationGrammarFields returns scan destinations matching
+// translationGrammarColumns.
+func translationGrammarFields(t *model.Translation) []any {
+	return []any{&t.PartOfSpeech, &t.Gender, &t.Aspect, &t.AspectPartner, &t.UsageNote}
+}
+
+func translationGrammarValues(t *model.Translation) []any {
+	return []any{t.PartOfSpeech, t.Gender, t.Aspect, t.AspectPartner, t.UsageNote}
+}
+
+func translationGrammarFromInput(input *model.AddTranslationInput) *model.Translation {
+	return &model.Translation{
+		PartOfSpeech:  input.PartOfSpeech,
+		Gender:        input.Gender,
+		Aspect:        input.Aspect,
+		AspectPartner: input.AspectPartner,
+		UsageNote:     input.UsageNote,
+	}
+}
+
+// validateTranslationGrammar checks that gender is only set on nouns and
+// aspect only on verbs, so a translation cannot carry contradictory metadata.
+func validateTranslationGrammar(t *model.Translation) error {
+	if t.PartOfSpeech != nil && !t.PartOfSpeech.IsValid() {
+		return validationError("partOfSpeech", fmt.Sprintf("unknown part of speech %q", *t.PartOfSpeech))
+	}
+
+	if t.Gender != nil {
+		if !t.Gender.IsValid() {
+			return validationError("gender", fmt.Sprintf("unknown gender %q", *t.Gender))
+		}
+		if t.PartOfSpeech == nil || *t.PartOfSpeech != model.PartOfSpeechNoun {
+			return validationError("gender", "gender can only be set on nouns")
+		}
+	}
+
+	if t.Aspect != nil {
+		if !t.Aspect.IsValid() {
+			return validationError("aspect", fmt.Sprintf("unknown aspect %q", *t.Aspect))
+		}
+		if t.PartOfSpeech == nil || *t.PartOfSpeech != model.PartOfSpeechVerb {
+			return validationError("aspect", "aspect can only be set on verbs")
+		}
+	}
+
+	if t.AspectPartner != nil {
+		if t.Aspect == nil {
+			return validationError("aspectPartner", "aspectPartner needs an aspect")
+		}
+		if err := validateWord("aspectPartner", *t.AspectPartner); err != nil {
+			return err
+		}
+	}
+
+	if t.UsageNote != nil && utf8.RuneCountInString(*t.UsageNote) > maxUsageNoteLength {
+		return validationError("usageNote", fmt.Sprintf("usageNote must be at most %d characters long", maxUsageNoteLength))
+	}
+
+	return nil
+}
+
+// applyTranslationGrammarEdits copies the grammar fields set in edits onto t.
+// Fields that stop applying after a part of speech change are cleared, and
+// empty strings clear the free-text fields.
+func applyTranslationGrammarEdits(t *model.Translation, edits *model.EditTranslationInput) {
+	if edits.PartOfSpeech != nil {
+		t.PartOfSpeech = edits.PartOfSpeech
+		if *t.PartOfSpeech != model.PartOfSpeechNoun {
+			t.Gender = nil
+		}
+		if *t.PartOfSpeech != model.PartOfSpeechVerb {
+			t.Aspect = nil
+			t.AspectPartner = nil
+		}
+	}
+
+	if edits.Gender != nil {
+		t.Gender = edits.Gender
+	}
+
+	if edits.Aspect != nil {
+		t.Aspect = edits.Aspect
+	}
+
+	if edits.AspectPartner != nil {
+		t.AspectPartner = emptyToNil(*edits.AspectPartner)
+	}
+
+	if edits.UsageNote != nil {
+		t.UsageNote = emptyToNil(*edits.UsageNote)
+	}
+}
+
+func hasTranslationGrammarEdits(edits *model.EditTranslationInput) bool {
+	return edits.PartOfSpeech != nil || edits.Gender != nil || edits.Aspect != nil ||
+		edits.AspectPartner != nil || edits.UsageNote != nil
+}
+
+func emptyToNil(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -6,146 +6,283 @@ import (
 	"fmt"
 
 	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/lib/pq"
 )
 
+const translationUpsertConflict = "ON CONFLICT (tenant, polish_word_id, language, english_word) WHERE deleted_at IS NULL DO UPDATE SET english_word = EXCLUDED.english_word"
+
 type TranslationRepositoryDB struct {
 	DB                  *sql.DB
 	ExampleSentenceRepo *ExampleSentenceRepositoryDB
 }
 
 func (tr *TranslationRepositoryDB) AddTranslation(ctx context.Context, polishWordID *string, polishWord *string, translation *model.AddTranslationInput) (*model.Translation, error) {
+	return inTx(ctx, tr.DB, func(ctx context.Context) (*model.Translation, error) {
+		targetPolishWordID, err := getTargetPolishWordID(ctx, conn(ctx, tr.DB), polishWordID, polishWord)
 
-	targetPolishWordID, err := tr.getTargetPolishWordID(ctx, polishWordID, polishWord)
+		if err != nil {
+			return nil, err
+		}
 
-	if err != nil {
-		return nil, err
-	}
+		language, err := translationLanguage(translation.Language)
+		if err != nil {
+			return nil, err
+		}
 
-	newTranslation := &model.Translation{
-		EnglishWord:      translation.EnglishWord,
-		ExampleSentences: []*model.ExampleSentence{},
-	}
+		newTranslation := &model.Translation{
+			EnglishWord:      translation.EnglishWord,
+			Language:         language,
+			PartOfSpeech:     translation.PartOfSpeech,
+			Gender:           translation.Gender,
+			Aspect:           translation.Aspect,
+			AspectPartner:    translation.AspectPartner,
+			UsageNote:        translation.UsageNote,
+			ExampleSentences: []*model.ExampleSentence{},
+			PolishWordID:     *targetPolishWordID,
+		}
 
-	err = tr.DB.QueryRowContext(ctx, `
-		
-			INSERT INTO translations (english_word, polish_word_id)
-			VALUES ($1, $2)
-			ON CONFLICT (polish_word_id, english_word) DO UPDATE SET english_word = EXCLUDED.english_word
-			RETURNING id, version
-		
-	`,
-		newTranslation.EnglishWord, *targetPolishWordID).Scan(&newTranslation.ID, &newTranslation.Version)
+		if err := validateTranslationGrammar(newTranslation); err != nil {
+			return nil, err
+		}
+		if err := validateFrequencyRank(translation.FrequencyRank, false); err != nil {
+			return nil, err
+		}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to upsert translation: %w", err)
-	}
+		// An existing translation keeps its grammar and visibility; the
+		// returned columns reflect what is stored. A new one is as visible as
+		// its word unless told otherwise.
+		err = conn(ctx, tr.DB).QueryRowContext(ctx, `
 
-	if polishWord != nil {
-		newTranslation.PolishWord = &model.PolishWord{
-			ID:   *targetPolishWordID,
-			Word: *polishWord,
-		}
-	} else {
-		newTranslation.PolishWord, err = tr.prepareWordWithId(ctx, targetPolishWordID)
+				INSERT INTO translations (english_word, language, polish_word_id, `+translationGrammarColumns+`, visibility)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, COALESCE($9, (SELECT visibility FROM lexemes WHERE id = $3)))
+				`+translationUpsertConflict+`
+				RETURNING id, version, `+translationGrammarColumns+`
+
+		`,
+			append(append([]any{newTranslation.EnglishWord, language, *targetPolishWordID}, translationGrammarValues(newTranslation)...), visibilityValue(translation.Visibility))...).
+			Scan(append([]any{&newTranslation.ID, &newTranslation.Version}, translationGrammarFields(newTranslation)...)...)
 
 		if err != nil {
+			return nil, missingReferenceOr(fmt.Errorf("failed to upsert translation: %w", err), "polish word", "id", *targetPolishWordID)
+		}
+
+		if err := setLevel(ctx, conn(ctx, tr.DB), "translations", newTranslation.ID, translation.CefrLevel, translation.FrequencyRank); err != nil {
 			return nil, err
 		}
-	}
+		newTranslation.CefrLevel = translation.CefrLevel
+		newTranslation.FrequencyRank = translation.FrequencyRank
+
+		if polishWord != nil {
+			newTranslation.PolishWord = &model.PolishWord{
+				ID:   *targetPolishWordID,
+				Word: *polishWord,
+			}
+		} else {
+			newTranslation.PolishWord, err = prepareWordWithId(ctx, conn(ctx, tr.DB), targetPolishWordID)
+
+			if err != nil {
+				return nil, err
+			}
+		}
 
-	for _, es := range translation.ExampleSentences {
-		newExampleSentence, err := tr.ExampleSentenceRepo.AddExampleSentence(ctx, newTranslation.ID, *es)
+		for _, es := range translation.ExampleSentences {
+			newExampleSentence, err := tr.ExampleSentenceRepo.AddExampleSentence(ctx, newTranslation.ID, *es)
 
-		if err != nil {
-			return nil, err
+			if err != nil {
+				return nil, err
+			}
+			newTranslation.ExampleSentences = append(newTranslation.ExampleSentences, newExampleSentence)
 		}
-		newTranslation.ExampleSentences = append(newTranslation.ExampleSentences, newExampleSentence)
-	}
-	return newTranslation, nil
+		return newTranslation, nil
+	})
 }
 
 func (tr *TranslationRepositoryDB) DeleteTranslation(ctx context.Context, id string) (*model.Translation, error) {
-	var deletedTranslation model.Translation
-	deletedTranslation.PolishWord = &model.PolishWord{}
+	return inTx(ctx, tr.DB, func(ctx context.Context) (*model.Translation, error) {
+		var deletedTranslation model.Translation
+		deletedTranslation.PolishWord = &model.PolishWord{}
 
-	exampleSentences, err := GetCurrentExampleSentencesFromDB(ctx, tr.DB, id)
+		if err := authorizeDelete(ctx, conn(ctx, tr.DB), ownsTranslationTree, "translation", id); err != nil {
+			return nil, err
+		}
 
-	if err != nil {
-		return nil, err
-	}
+		exampleSentences, err := GetCurrentExampleSentencesFromDB(ctx, conn(ctx, tr.DB), id)
 
-	deletedTranslation.ExampleSentences = exampleSentences
+		if err != nil {
+			return nil, err
+		}
 
-	err = tr.DB.QueryRowContext(ctx, "DELETE FROM translations WHERE id = $1 RETURNING id, english_word, polish_word_id, version", id).
-		Scan(&deletedTranslation.ID, &deletedTranslation.EnglishWord, &deletedTranslation.PolishWord.ID, &deletedTranslation.Version)
+		deletedTranslation.ExampleSentences = exampleSentences
 
-	if err != nil {
-		return nil, err
-	}
+		if err := trashExampleSentencesOf(ctx, conn(ctx, tr.DB), []string{id}); err != nil {
+			return nil, err
+		}
 
-	var fetchedPolishWord string
-	var fetchedPolishWordVersion int
-	err = tr.DB.QueryRowContext(ctx, "SELECT word, version FROM polish_words WHERE id = $1", deletedTranslation.PolishWord.ID).Scan(&fetchedPolishWord, &fetchedPolishWordVersion)
+		err = conn(ctx, tr.DB).QueryRowContext(ctx, "UPDATE translations SET deleted_at = now() WHERE id = $1 AND "+notDeleted+" AND "+visibleTo(ctx, "")+" RETURNING id, english_word, language, polish_word_id, version, "+translationGrammarColumns, id).
+			Scan(append([]any{&deletedTranslation.ID, &deletedTranslation.EnglishWord, &deletedTranslation.Language, &deletedTranslation.PolishWord.ID, &deletedTranslation.Version},
+				translationGrammarFields(&deletedTranslation)...)...)
 
-	if err != nil {
-		return nil, err
-	}
-	deletedTranslation.PolishWord.Word = fetchedPolishWord
-	deletedTranslation.PolishWord.Version = fetchedPolishWordVersion
+		if err != nil {
+			return nil, notFoundOr(err, "translation", "id", id)
+		}
+		deletedTranslation.PolishWordID = deletedTranslation.PolishWord.ID
+
+		var fetchedPolishWord string
+		var fetchedPolishWordVersion int
+		err = conn(ctx, tr.DB).QueryRowContext(ctx, "SELECT word, version FROM lexemes WHERE id = $1", deletedTranslation.PolishWord.ID).Scan(&fetchedPolishWord, &fetchedPolishWordVersion)
+
+		if err != nil {
+			return nil, err
+		}
+		deletedTranslation.PolishWord.Word = fetchedPolishWord
+		deletedTranslation.PolishWord.Version = fetchedPolishWordVersion
 
-	return &deletedTranslation, nil
+		return &deletedTranslation, nil
+	})
 }
 
 func (tr *TranslationRepositoryDB) UpdateTranslation(ctx context.Context, id string, edits model.EditTranslationInput) (*model.Translation, error) {
-	var translation model.Translation
-	translation.PolishWord = &model.PolishWord{}
+	if edits.ID != nil && *edits.ID != id {
+		return nil, validationError("id", "edits id does not match the translation being updated")
+	}
 
-	err := tr.DB.QueryRowContext(ctx, "SELECT id, english_word, polish_word_id, version FROM translations WHERE id = $1", id).
-		Scan(&translation.ID, &translation.EnglishWord, &translation.PolishWord.ID, &translation.Version)
+	return inTx(ctx, tr.DB, func(ctx context.Context) (*model.Translation, error) {
+		var translation model.Translation
+		translation.PolishWord = &model.PolishWord{}
 
-	if err != nil {
-		return nil, err
-	}
+		err := conn(ctx, tr.DB).QueryRowContext(ctx, "SELECT id, english_word, language, polish_word_id, version, "+translationGrammarColumns+" FROM translations WHERE id = $1 AND "+notDeleted+" AND "+visibleTo(ctx, ""), id).
+			Scan(append([]any{&translation.ID, &translation.EnglishWord, &translation.Language, &translation.PolishWord.ID, &translation.Version},
+				translationGrammarFields(&translation)...)...)
 
-	if err := UpdateSingleTranslation(ctx, tr.DB, &translation, &edits); err != nil {
-		return nil, err
-	}
+		if err != nil {
+			return nil, notFoundOr(err, "translation", "id", id)
+		}
 
-	err = tr.DB.QueryRowContext(ctx, "SELECT word, version FROM polish_words WHERE id = $1", translation.PolishWord.ID).
-		Scan(&translation.PolishWord.Word, &translation.PolishWord.Version)
+		translation.PolishWordID = translation.PolishWord.ID
 
-	if err != nil {
-		return nil, err
-	}
+		if err := UpdateSingleTranslation(ctx, conn(ctx, tr.DB), &translation, &edits); err != nil {
+			return nil, err
+		}
 
-	return &translation, nil
+		err = conn(ctx, tr.DB).QueryRowContext(ctx, "SELECT word, version FROM lexemes WHERE id = $1", translation.PolishWord.ID).
+			Scan(&translation.PolishWord.Word, &translation.PolishWord.Version)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &translation, nil
+	})
 }
 
 func (tr *TranslationRepositoryDB) GetSingleTranslationByID(ctx context.Context, id string) (*model.Translation, error) {
 	var translation model.Translation
-	translation.PolishWord = &model.PolishWord{}
 
-	err := tr.DB.QueryRowContext(ctx, "SELECT id, english_word, polish_word_id, version FROM translations WHERE id = $1", id).
-		Scan(&translation.ID, &translation.EnglishWord, &translation.PolishWord.ID, &translation.Version)
+	err := conn(ctx, tr.DB).QueryRowContext(ctx, "SELECT id, english_word, language, polish_word_id, version, "+translationGrammarColumns+" FROM translations WHERE id = $1 AND "+notDeleted+" AND "+visibleTo(ctx, ""), id).
+		Scan(append([]any{&translation.ID, &translation.EnglishWord, &translation.Language, &translation.PolishWordID, &translation.Version},
+			translationGrammarFields(&translation)...)...)
 
 	if err != nil {
-		return nil, err
+		return nil, notFoundOr(err, "translation", "id", id)
 	}
 
-	err = tr.DB.QueryRowContext(ctx, "SELECT word, version FROM polish_words WHERE id = $1", translation.PolishWord.ID).
-		Scan(&translation.PolishWord.Word, &translation.PolishWord.Version)
+	return &translation, nil
+}
 
+func (tr *TranslationRepositoryDB) GetTranslationsByIDs(ctx context.Context, ids []string) (map[string]*model.Translation, error) {
+	rows, err := conn(ctx, tr.DB).QueryContext(ctx,
+		"SELECT id, english_word, language, polish_word_id, version, "+translationGrammarColumns+" FROM translations WHERE id = ANY($1::int[]) AND "+notDeleted+" AND "+visibleTo(ctx, ""), pq.Array(ids))
 	if err != nil {
-		return nil, err
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	translations := make(map[string]*model.Translation, len(ids))
+	for rows.Next() {
+		var translation model.Translation
+		if err := rows.Scan(append([]any{&translation.ID, &translation.EnglishWord, &translation.Language, &translation.PolishWordID, &translation.Version},
+			translationGrammarFields(&translation)...)...); err != nil {
+			return nil, dbError(err)
+		}
+		translations[translation.ID] = &translation
 	}
 
-	examples, err := GetCurrentExampleSentencesFromDB(ctx, tr.DB, translation.ID)
+	if err = rows.Err(); err != nil {
+		return nil, dbError(err)
+	}
 
+	return translations, nil
+}
+
+func (tr *TranslationRepositoryDB) GetTranslationsByPolishWordIDs(ctx context.Context, polishWordIDs []string) (map[string][]*model.Translation, error) {
+	rows, err := conn(ctx, tr.DB).QueryContext(ctx,
+		"SELECT id, english_word, language, polish_word_id, version, "+translationGrammarColumns+" FROM translations WHERE polish_word_id = ANY($1::int[]) AND "+notDeleted+" AND "+visibleTo(ctx, "")+" ORDER BY id",
+		pq.Array(polishWordIDs))
 	if err != nil {
-		return nil, err
+		return nil, dbError(err)
 	}
+	defer rows.Close()
 
-	translation.ExampleSentences = examples
+	translations := make(map[string][]*model.Translation, len(polishWordIDs))
+	for _, polishWordID := range polishWordIDs {
+		translations[polishWordID] = []*model.Translation{}
+	}
 
-	return &translation, nil
+	for rows.Next() {
+		var translation model.Translation
+		if err := rows.Scan(append([]any{&translation.ID, &translation.EnglishWord, &translation.Language, &translation.PolishWordID, &translation.Version},
+			translationGrammarFields(&translation)...)...); err != nil {
+			return nil, dbError(err)
+		}
+		translations[translation.PolishWordID] = append(translations[translation.PolishWordID], &translation)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, dbError(err)
+	}
+
+	return translations, nil
+}
+
+// GetTranslationsByEnglishWord only finds English translations of Polish words.
+func (tr *TranslationRepositoryDB) GetTranslationsByEnglishWord(ctx context.Context, englishWord string, partOfSpeech *model.PartOfSpeech) ([]*model.Translation, error) {
+	rows, err := conn(ctx, tr.DB).QueryContext(ctx, `
+		SELECT t.id, t.english_word, t.language, t.version, `+qualifiedTranslationGrammarColumns+`, p.id, p.word, p.version
+		FROM translations t
+		JOIN lexemes p ON t.polish_word_id = p.id
+		WHERE lower(t.english_word) = lower($1)
+		AND ($2::text IS NULL OR t.part_of_speech = $2)
+		AND t.language = 'en' AND p.language = 'pl'
+		AND t.deleted_at IS NULL AND `+visibleTo(ctx, "t")+`
+		ORDER BY t.id`, englishWord, partOfSpeech)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	translations := []*model.Translation{}
+	for rows.Next() {
+		translation := &model.Translation{PolishWord: &model.PolishWord{}}
+		dest := append([]any{&translation.ID, &translation.EnglishWord, &translation.Language, &translation.Version}, translationGrammarFields(translation)...)
+		dest = append(dest, &translation.PolishWord.ID, &translation.PolishWord.Word, &translation.PolishWord.Version)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, dbError(err)
+		}
+		translation.PolishWordID = translation.PolishWord.ID
+		translations = append(translations, translation)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, dbError(err)
+	}
+
+	return translations, nil
+}
+
+func (tr *TranslationRepositoryDB) GetLevelsByTranslationIDs(ctx context.Context, ids []string) (map[string]*Level, error) {
+	return getLevelsByIDs(ctx, conn(ctx, tr.DB), "translations", ids)
+}
+
+func (tr *TranslationRepositoryDB) GetAccessByTranslationIDs(ctx context.Context, ids []string) (map[string]*Access, error) {
+	return getAccessByIDs(ctx, conn(ctx, tr.DB), "translations", ids)
 }
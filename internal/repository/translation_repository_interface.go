@@ -11,4 +11,9 @@ type TranslationRepositoryInterface interface {
 	DeleteTranslation(ctx context.Context, id string) (*model.Translation, error)
 	UpdateTranslation(ctx context.Context, id string, edits model.EditTranslationInput) (*model.Translation, error)
 	GetSingleTranslationByID(ctx context.Context, id string) (*model.Translation, error)
+	GetTranslationsByEnglishWord(ctx context.Context, englishWord string, partOfSpeech *model.PartOfSpeech) ([]*model.Translation, error)
+	GetTranslationsByIDs(ctx context.Context, ids []string) (map[string]*model.Translation, error)
+	GetTranslationsByPolishWordIDs(ctx context.Context, polishWordIDs []string) (map[string][]*model.Translation, error)
+	GetLevelsByTranslationIDs(ctx context.Context, ids []string) (map[string]*Level, error)
+	GetAccessByTranslationIDs(ctx context.Context, ids []string) (map[string]*Access, error)
 }
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/lib/pq"
+)
+
+// Deleting moves an entry and its live children to the trash by setting
+// deleted_at. now() is fixed for a transaction, so everything deleted
+// together shares one deleted_at, which is how restore finds it again. A live
+// row always has a live parent.
+
+const (
+	trashPolishWordPrefix      = "polishword:"
+	trashTranslationPrefix     = "translation:"
+	trashExampleSentencePrefix = "examplesentence:"
+)
+
+var trashPrefixes = map[model.TrashKind]string{
+	model.TrashKindPolishWord:      trashPolishWordPrefix,
+	model.TrashKindTranslation:     trashTranslationPrefix,
+	model.TrashKindExampleSentence: trashExampleSentencePrefix,
+}
+
+// trashExampleSentences moves the live example sentences of translationID
+// with the given ids to the trash.
+func trashExampleSentences(ctx context.Context, db DBTX, translationID string, ids []string) error {
+	_, err := db.ExecContext(ctx,
+		"UPDATE example_sentences SET deleted_at = now() WHERE translation_id = $1 AND id = ANY($2::int[]) AND "+notDeleted,
+		translationID, pq.Array(ids))
+	return err
+}
+
+// trashExampleSentencesOf moves every live example sentence of the
+// translations to the trash.
+func trashExampleSentencesOf(ctx context.Context, db DBTX, translationIDs []string) error {
+	_, err := db.ExecContext(ctx,
+		"UPDATE example_sentences SET deleted_at = now() WHERE translation_id = ANY($1::int[]) AND "+notDeleted,
+		pq.Array(translationIDs))
+	return err
+}
+
+// trashTranslations moves the live translations of polishWordID with the
+// given ids to the trash together with their example sentences.
+func trashTranslations(ctx context.Context, db DBTX, polishWordID string, ids []string) error {
+	if err := trashExampleSentencesOf(ctx, db, ids); err != nil {
+		return err
+	}
+
+	_, err := db.ExecContext(ctx,
+		"UPDATE translations SET deleted_at = now() WHERE polish_word_id = $1 AND id = ANY($2::int[]) AND "+notDeleted,
+		polishWordID, pq.Array(ids))
+	return err
+}
+
+// trashTranslationsOf moves every live translation of polishWordID to the
+// trash together with their example sentences.
+func trashTranslationsOf(ctx context.Context, db DBTX, polishWordID string) error {
+	_, err := db.ExecContext(ctx, `
+		UPDATE example_sentences es SET deleted_at = now()
+		FROM translations t
+		WHERE es.translation_id = t.id AND t.polish_word_id = $1
+		AND t.deleted_at IS NULL AND es.deleted_at IS NULL`, polishWordID)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx,
+		"UPDATE translations SET deleted_at = now() WHERE polish_word_id = $1 AND "+notDeleted, polishWordID)
+	return err
+}
+
+func trashEntryID(kind model.TrashKind, id string) string {
+	return trashPrefixes[kind] + id
+}
+
+// parseTrashEntryID splits a trash entry id into its kind and row id.
+func parseTrashEntryID(id string) (model.TrashKind, string, error) {
+	for kind, prefix := range trashPrefixes {
+		rowID, ok := strings.CutPrefix(id, prefix)
+		if !ok {
+			continue
+		}
+		if n, err := strconv.Atoi(rowID); err == nil && n > 0 {
+			return kind, strconv.Itoa(n), nil
+		}
+	}
+
+	return "", "", validationError("id", "not a trash entry id")
+}
+
+func (tr *TrashRepositoryDB) restorePolishWord(ctx context.Context, entryID string, id string) (model.DictionaryEntry, error) {
+	var deletedAt time.Time
+	err := conn(ctx, tr.DB).QueryRowContext(ctx,
+		"SELECT deleted_at FROM lexemes WHERE id = $1 AND deleted_at IS NOT NULL AND "+visibleTo(ctx, "")+" FOR UPDATE", id).Scan(&deletedAt)
+	if err != nil {
+		return nil, notFoundOr(err, "trash entry", "id", entryID)
+	}
+
+	for _, query := range []string{
+		`UPDATE example_sentences es SET deleted_at = NULL
+		FROM translations t
+		WHERE es.translation_id = t.id AND t.polish_word_id = $1 AND es.deleted_at = $2`,
+		"UPDATE translations SET deleted_at = NULL WHERE polish_word_id = $1 AND deleted_at = $2",
+		"UPDATE lexemes SET deleted_at = NULL WHERE id = $1 AND deleted_at = $2",
+	} {
+		if _, err := conn(ctx, tr.DB).ExecContext(ctx, query, id, deletedAt); err != nil {
+			return nil, dbError(err)
+		}
+	}
+
+	pw, err := tr.PolishWordRepo.GetSinglePolishWord(ctx, &id, nil)
+	if err != nil {
+		return nil, err
+	}
+	return pw, nil
+}
+
+func (tr *TrashRepositoryDB) restoreTranslation(ctx context.Context, entryID string, id string) (model.DictionaryEntry, error) {
+	var deletedAt time.Time
+	var polishWordDeleted bool
+	err := conn(ctx, tr.DB).QueryRowContext(ctx, `
+		SELECT t.deleted_at, p.deleted_at IS NOT NULL
+		FROM translations t
+		JOIN lexemes p ON p.id = t.polish_word_id
+		WHERE t.id = $1 AND t.deleted_at IS NOT NULL AND `+visibleTo(ctx, "t")+`
+		FOR UPDATE OF t`, id).Scan(&deletedAt, &polishWordDeleted)
+	if err != nil {
+		return nil, notFoundOr(err, "trash entry", "id", entryID)
+	}
+	if polishWordDeleted {
+		return nil, validationError("id", "the polish word of this translation is in the trash; restore it first")
+	}
+
+	for _, query := range []string{
+		"UPDATE example_sentences SET deleted_at = NULL WHERE translation_id = $1 AND deleted_at = $2",
+		"UPDATE translations SET deleted_at = NULL WHERE id = $1 AND deleted_at = $2",
+	} {
+		if _, err := conn(ctx, tr.DB).ExecContext(ctx, query, id, deletedAt); err != nil {
+			return nil, dbError(err)
+		}
+	}
+
+	translation, err := tr.TranslationRepo.GetSingleTranslationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return translation, nil
+}
+
+func (tr *TrashRepositoryDB) restoreExampleSentence(ctx context.Context, entryID string, id string) (model.DictionaryEntry, error) {
+	var translationDeleted bool
+	err := conn(ctx, tr.DB).QueryRowContext(ctx, `
+		SELECT t.deleted_at IS NOT NULL
+		FROM example_sentences es
+		JOIN translations t ON t.id = es.translation_id
+		WHERE es.id = $1 AND es.deleted_at IS NOT NULL AND `+visibleTo(ctx, "es")+`
+		FOR UPDATE OF es`, id).Scan(&translationDeleted)
+	if err != nil {
+		return nil, notFoundOr(err, "trash entry", "id", entryID)
+	}
+	if translationDeleted {
+		return nil, validationError("id", "the translation of this example sentence is in the trash; restore it first")
+	}
+
+	if _, err := conn(ctx, tr.DB).ExecContext(ctx,
+		"UPDATE example_sentences SET deleted_at = NULL WHERE id = $1", id); err != nil {
+		return nil, dbError(err)
+	}
+
+	es, err := tr.ExampleSentenceRepo.GetSingleExampleSentence(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return es, nil
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+)
+
+type TrashRepositoryDB struct {
+	DB                  *sql.DB
+	PolishWordRepo      *PolishWordRepositoryDB
+	TranslationRepo     *TranslationRepositoryDB
+	ExampleSentenceRepo *ExampleSentenceRepositoryDB
+}
+
+// GetTrash lists the entries that were deleted on their own. Children deleted
+// along with their parent share its deleted_at and are only counted. Entries
+// the caller may not see are left out.
+func (tr *TrashRepositoryDB) GetTrash(ctx context.Context) ([]*model.TrashEntry, error) {
+	rows, err := conn(ctx, tr.DB).QueryContext(ctx, `
+		SELECT 'POLISH_WORD', p.id, p.word, p.deleted_at,
+			(SELECT COUNT(*) FROM translations t
+				WHERE t.polish_word_id = p.id AND t.deleted_at = p.deleted_at)
+			+ (SELECT COUNT(*) FROM example_sentences es JOIN translations t ON t.id = es.translation_id
+				WHERE t.polish_word_id = p.id AND es.deleted_at = p.deleted_at)
+		FROM lexemes p
+		WHERE p.deleted_at IS NOT NULL AND `+visibleTo(ctx, "p")+`
+		UNION ALL
+		SELECT 'TRANSLATION', t.id, t.english_word, t.deleted_at,
+			(SELECT COUNT(*) FROM example_sentences es
+				WHERE es.translation_id = t.id AND es.deleted_at = t.deleted_at)
+		FROM translations t JOIN lexemes p ON p.id = t.polish_word_id
+		WHERE t.deleted_at IS NOT NULL AND p.deleted_at IS DISTINCT FROM t.deleted_at AND `+visibleTo(ctx, "t")+`
+		UNION ALL
+		SELECT 'EXAMPLE_SENTENCE', es.id, es.sentence_pl, es.deleted_at, 0
+		FROM example_sentences es JOIN translations t ON t.id = es.translation_id
+		WHERE es.deleted_at IS NOT NULL AND t.deleted_at IS DISTINCT FROM es.deleted_at AND `+visibleTo(ctx, "es")+`
+		ORDER BY 4 DESC, 1, 2`)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	entries := []*model.TrashEntry{}
+	for rows.Next() {
+		var entry model.TrashEntry
+		var id string
+		var deletedAt time.Time
+		if err := rows.Scan(&entry.Kind, &id, &entry.Text, &deletedAt, &entry.Dependents); err != nil {
+			return nil, dbError(err)
+		}
+
+		entry.ID = trashEntryID(entry.Kind, id)
+		entry.DeletedAt = deletedAt.UTC().Format(time.RFC3339)
+		entries = append(entries, &entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, dbError(err)
+	}
+
+	return entries, nil
+}
+
+// Restore brings back a trash entry and the children deleted along with it.
+// A translation or example sentence whose parent is still in the trash cannot
+// be restored on its own.
+func (tr *TrashRepositoryDB) Restore(ctx context.Context, id string) (model.DictionaryEntry, error) {
+	kind, rowID, err := parseTrashEntryID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return inTx(ctx, tr.DB, func(ctx context.Context) (model.DictionaryEntry, error) {
+		switch kind {
+		case model.TrashKindPolishWord:
+			return tr.restorePolishWord(ctx, id, rowID)
+		case model.TrashKindTranslation:
+			return tr.restoreTranslation(ctx, id, rowID)
+		default:
+			return tr.restoreExampleSentence(ctx, id, rowID)
+		}
+	})
+}
+
+// Purge removes the entries deleted before deletedBefore for good.
+func (tr *TrashRepositoryDB) Purge(ctx context.Context, deletedBefore time.Time) (*PurgeReport, error) {
+	return inTx(ctx, tr.DB, func(ctx context.Context) (*PurgeReport, error) {
+		var report PurgeReport
+
+		// Children go first so that the cascade does not remove them uncounted.
+		for _, target := range []struct {
+			table string
+			count *int
+		}{
+			{"example_sentences", &report.ExampleSentences},
+			{"translations", &report.Translations},
+			{"lexemes", &report.PolishWords},
+		} {
+			result, err := conn(ctx, tr.DB).ExecContext(ctx,
+				"DELETE FROM "+target.table+" WHERE deleted_at < $1", deletedBefore)
+			if err != nil {
+				return nil, dbError(err)
+			}
+
+			purged, err := result.RowsAffected()
+			if err != nil {
+				return nil, dbError(err)
+			}
+			*target.count = int(purged)
+		}
+
+		return &report, nil
+	})
+}
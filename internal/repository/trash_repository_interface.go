@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+)
+
+// PurgeReport counts the rows removed from the trash for good.
+type PurgeReport struct {
+	PolishWords      int
+	Translations     int
+	ExampleSentences int
+}
+
+type TrashRepositoryInterface interface {
+	GetTrash(ctx context.Context) ([]*model.TrashEntry, error)
+	Restore(ctx context.Context, id string) (model.DictionaryEntry, error)
+	Purge(ctx context.Context, deletedBefore time.Time) (*PurgeReport, error)
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// DBTX is implemented by both *sql.DB and *sql.Tx, so helpers can run either
+// on their own or as part of a surrounding transaction.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type txContextKey struct{}
+
+type authorContextKey struct{}
+
+type ownerContextKey struct{}
+
+type changeIDContextKey struct{}
+
+// WithAuthor returns a context whose transactions record author as the
+// author of the revisions they write.
+func WithAuthor(ctx context.Context, author string) context.Context {
+	return context.WithValue(ctx, authorContextKey{}, author)
+}
+
+// WithOwner returns a context whose transactions make owner the owner of the
+// entries they create. Without one, new entries have no owner.
+func WithOwner(ctx context.Context, owner string) context.Context {
+	return context.WithValue(ctx, ownerContextKey{}, owner)
+}
+
+// WithChangeID returns a context whose transactions tag the revisions they
+// write with changeID, so that they can be found by GetChanges.
+func WithChangeID(ctx context.Context, changeID string) context.Context {
+	return context.WithValue(ctx, changeIDContextKey{}, changeID)
+}
+
+// txSettings are passed to the history triggers and the owner column
+// defaults as transaction-local settings.
+var txSettings = []struct {
+	name string
+	key  any
+}{
+	{"app.author", authorContextKey{}},
+	{"app.owner", ownerContextKey{}},
+	{"app.change_id", changeIDContextKey{}},
+}
+
+// UnitOfWork groups repository calls into a single transaction. The
+// transaction travels in the context, so every repository and helper that
+// receives that context joins it instead of autocommitting.
+type UnitOfWork struct {
+	DB *sql.DB
+}
+
+func (uow *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return RunInTx(ctx, uow.DB, fn)
+}
+
+// RunInTx runs fn in a new transaction that is committed when fn succeeds and
+// rolled back otherwise. When ctx already carries a transaction, fn joins it
+// and the outermost caller decides whether to commit.
+func RunInTx(ctx context.Context, db *sql.DB, fn func(ctx context.Context) error) (err error) {
+	if _, ok := txFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	for _, setting := range txSettings {
+		if value, ok := ctx.Value(setting.key).(string); ok && value != "" {
+			if _, err = tx.ExecContext(ctx, "SELECT set_config('"+setting.name+"', $1, true)", value); err != nil {
+				return fmt.Errorf("failed to set %s: %w", setting.name, err)
+			}
+		}
+	}
+
+	if err = fn(context.WithValue(ctx, txContextKey{}, tx)); err != nil {
+		return err
+	}
+
+	// Deferred constraints are checked on commit, so its errors are
+	// translated like those of any other statement.
+	if err = tx.Commit(); err != nil {
+		return dbError(fmt.Errorf("failed to commit transaction: %w", err))
+	}
+
+	return nil
+}
+
+func inTx[T any](ctx context.Context, db *sql.DB, fn func(ctx context.Context) (T, error)) (T, error) {
+	var result T
+	err := RunInTx(ctx, db, func(ctx context.Context) error {
+		var err error
+		result, err = fn(ctx)
+		return err
+	})
+	if err != nil {
+		var zeroValue T
+		return zeroValue, err
+	}
+	return result, nil
+}
+
+// conn returns the transaction carried by ctx, or db when there is none.
+func conn(ctx context.Context, db *sql.DB) DBTX {
+	if tx, ok := txFromContext(ctx); ok {
+		return tx
+	}
+	return db
+}
+
+func txFromContext(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := ctx.Value(txContextKey{}).(*sql.Tx)
+	return tx, ok
+}
@@ -2,37 +2,80 @@ package repository
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/apperror"
 	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
 )
 
-func UpdateSingleTranslation(ctx context.Context, db *sql.DB, translation *model.Translation, editTr *model.EditTranslationInput) error {
+func UpdateSingleTranslation(ctx context.Context, db DBTX, translation *model.Translation, editTr *model.EditTranslationInput) error {
 
-	if editTr.EnglishWord != nil {
-		result, err := db.ExecContext(ctx, "UPDATE translations SET english_word = $1, version = version + 1 WHERE id = $2 AND version = $3",
-			*editTr.EnglishWord, translation.ID, translation.Version)
+	if editTr.EnglishWord != nil || editTr.Language != nil || hasTranslationGrammarEdits(editTr) || hasLevelEdits(editTr.CefrLevel, editTr.FrequencyRank) || editTr.Visibility != nil {
+		if editTr.Version == nil {
+			return validationError("version", "version is required when editing an existing translation")
+		}
+		if editTr.Visibility != nil {
+			if err := authorizeVisibilityChange(ctx, db, "translations", "translation", translation.ID); err != nil {
+				return err
+			}
+		}
 
-		if err != nil {
+		edited := *translation
+		if editTr.EnglishWord != nil {
+			edited.EnglishWord = *editTr.EnglishWord
+		}
+		if editTr.Language != nil {
+			language, err := normalizeLanguage("language", *editTr.Language)
+			if err != nil {
+				return err
+			}
+			edited.Language = language
+		}
+		applyTranslationGrammarEdits(&edited, editTr)
+
+		if err := validateTranslationGrammar(&edited); err != nil {
+			return err
+		}
+		if err := validateFrequencyRank(editTr.FrequencyRank, true); err != nil {
 			return err
 		}
 
+		result, err := db.ExecContext(ctx, `
+			UPDATE translations
+			SET english_word = $1, language = $2, part_of_speech = $3, gender = $4, aspect = $5, aspect_partner = $6, usage_note = $7,
+				cefr_level = COALESCE($8, cefr_level), frequency_rank = NULLIF(COALESCE($9, frequency_rank), 0),
+				visibility = COALESCE($10, visibility), version = version + 1
+			WHERE id = $11 AND version = $12`,
+			append(append([]any{edited.EnglishWord, edited.Language}, translationGrammarValues(&edited)...),
+				editTr.CefrLevel, editTr.FrequencyRank, visibilityValue(editTr.Visibility), translation.ID, *editTr.Version)...)
+
+		if err != nil {
+			return dbError(err)
+		}
+
 		rowsAffected, err := result.RowsAffected()
 		if err != nil {
 			return err
 		}
 
 		if rowsAffected == 0 {
-			return fmt.Errorf("this translation has been modified by a different process")
+			return versionConflict(ctx, db, "translations", "translation", translation.ID, *editTr.Version)
+		}
+
+		edited.CefrLevel = editTr.CefrLevel
+		if editTr.FrequencyRank != nil {
+			edited.FrequencyRank = nilIfZero(*editTr.FrequencyRank)
+		}
+		if editTr.Visibility != nil {
+			edited.Visibility = *editTr.Visibility
 		}
 
-		translation.EnglishWord = *editTr.EnglishWord
-		translation.Version++
+		*translation = edited
+		translation.Version = *editTr.Version + 1
 	}
 
-	if editTr.ExampleSentences != nil {
-		exampleSentencesForTranslation, err := UpdateExampleSentences(ctx, db, translation.ID, editTr.ExampleSentences)
+	if editTr.ExampleSentences != nil || editTr.Remove != nil {
+		exampleSentencesForTranslation, err := UpdateExampleSentences(ctx, db, translation.ID, editTr.ExampleSentences, editTr.Remove)
 
 		if err != nil {
 			return err
@@ -46,21 +89,60 @@ func UpdateSingleTranslation(ctx context.Context, db *sql.DB, translation *model
 
 func UpdateExampleSentences(
 	ctx context.Context,
-	db *sql.DB,
+	db DBTX,
 	translationID string,
 	editExamples []*model.EditExampleSentenceInput,
+	remove []string,
 ) ([]*model.ExampleSentence, error) {
 
+	editIDs := make([]*string, len(editExamples))
+	for i, editEs := range editExamples {
+		editIDs[i] = editEs.ID
+	}
+	if err := checkNestedEditIDs(editIDs, remove, "exampleSentences"); err != nil {
+		return nil, err
+	}
+
 	currentExampleSentencesFromDB, err := GetCurrentExampleSentencesFromDB(ctx, db, translationID)
 	if err != nil {
 		return nil, err
 	}
 
-	for i, editEs := range editExamples {
+	currentExampleSentencesFromDB, err = withoutRemoved(currentExampleSentencesFromDB,
+		func(es *model.ExampleSentence) string { return es.ID }, remove, "example sentence")
+	if err != nil {
+		return nil, err
+	}
 
-		if i < len(currentExampleSentencesFromDB) {
+	// Removing a sentence deletes it, so it takes the same rights as
+	// deleteExampleSentence.
+	for _, id := range remove {
+		if err := authorizeDelete(ctx, db, ownsExampleSentence, "example sentence", id); err != nil {
+			return nil, err
+		}
+	}
 
-			err := UpdateSingleExampleSentence(ctx, db, currentExampleSentencesFromDB[i], editEs)
+	if len(remove) > 0 {
+		if err := trashExampleSentences(ctx, db, translationID, remove); err != nil {
+			return nil, err
+		}
+	}
+
+	exampleSentencesByID := make(map[string]*model.ExampleSentence, len(currentExampleSentencesFromDB))
+	for _, es := range currentExampleSentencesFromDB {
+		exampleSentencesByID[es.ID] = es
+	}
+
+	for _, editEs := range editExamples {
+
+		if editEs.ID != nil {
+
+			es, ok := exampleSentencesByID[*editEs.ID]
+			if !ok {
+				return nil, &apperror.NotFoundError{Entity: "example sentence", Field: "id", Value: *editEs.ID}
+			}
+
+			err := UpdateSingleExampleSentence(ctx, db, es, editEs)
 			if err != nil {
 				return nil, err
 			}
@@ -81,11 +163,11 @@ func UpdateExampleSentences(
 
 func GetCurrentExampleSentencesFromDB(
 	ctx context.Context,
-	db *sql.DB,
+	db DBTX,
 	translationID string,
 ) ([]*model.ExampleSentence, error) {
 	rows, err := db.QueryContext(ctx,
-		"SELECT id, sentence_pl, sentence_en, version FROM example_sentences WHERE translation_id = $1 ORDER BY id", translationID)
+		"SELECT id, sentence_pl, sentence_en, version FROM example_sentences WHERE translation_id = $1 AND "+notDeleted+" AND "+visibleTo(ctx, "")+" ORDER BY id", translationID)
 
 	if err != nil {
 		return nil, err
@@ -94,7 +176,7 @@ func GetCurrentExampleSentencesFromDB(
 
 	var currentExampleSentencesFromDB []*model.ExampleSentence
 	for rows.Next() {
-		var es model.ExampleSentence
+		es := model.ExampleSentence{TranslationID: translationID}
 		if err := rows.Scan(&es.ID, &es.SentencePl, &es.SentenceEn, &es.Version); err != nil {
 			return nil, err
 		}
@@ -107,10 +189,14 @@ func GetCurrentExampleSentencesFromDB(
 
 func UpdateSingleExampleSentence(
 	ctx context.Context,
-	db *sql.DB,
+	db DBTX,
 	exampleSentence *model.ExampleSentence,
 	editEs *model.EditExampleSentenceInput,
 ) error {
+	if editEs.Version == nil {
+		return validationError("version", "version is required when editing an existing example sentence")
+	}
+
 	sentencePl := exampleSentence.SentencePl
 	sentenceEn := exampleSentence.SentenceEn
 
@@ -122,12 +208,18 @@ func UpdateSingleExampleSentence(
 		sentenceEn = *editEs.SentenceEn
 	}
 
+	if editEs.Visibility != nil {
+		if err := authorizeVisibilityChange(ctx, db, "example_sentences", "example sentence", exampleSentence.ID); err != nil {
+			return err
+		}
+	}
+
 	result, err := db.ExecContext(ctx,
-		"UPDATE example_sentences SET sentence_pl = $1, sentence_en = $2, version = version + 1 WHERE id = $3 AND version = $4",
-		sentencePl, sentenceEn, exampleSentence.ID, exampleSentence.Version)
+		"UPDATE example_sentences SET sentence_pl = $1, sentence_en = $2, visibility = COALESCE($3, visibility), version = version + 1 WHERE id = $4 AND version = $5",
+		sentencePl, sentenceEn, visibilityValue(editEs.Visibility), exampleSentence.ID, *editEs.Version)
 
 	if err != nil {
-		return err
+		return dbError(err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -136,12 +228,19 @@ func UpdateSingleExampleSentence(
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("this example sentence has been modified by a different process")
+		return versionConflict(ctx, db, "example_sentences", "example sentence", exampleSentence.ID, *editEs.Version)
+	}
+
+	if err := editSentenceTexts(ctx, db, exampleSentence.ID, editEs.Texts, editEs.RemoveTexts); err != nil {
+		return err
 	}
 
 	exampleSentence.SentencePl = sentencePl
 	exampleSentence.SentenceEn = sentenceEn
-	exampleSentence.Version++
+	if editEs.Visibility != nil {
+		exampleSentence.Visibility = *editEs.Visibility
+	}
+	exampleSentence.Version = *editEs.Version + 1
 
 	return nil
 
@@ -149,35 +248,88 @@ func UpdateSingleExampleSentence(
 
 func InsertExampleSentence(
 	ctx context.Context,
-	db *sql.DB,
+	db DBTX,
 	translationID string,
 	editEs *model.EditExampleSentenceInput) (*model.ExampleSentence, error) {
 
-	var newExampleSentenceID string
-	var newExampleSentenceVersion int
-	sentencePl := ""
-	sentenceEn := ""
-
-	if editEs.SentencePl != nil {
-		sentencePl = *editEs.SentencePl
+	if editEs.SentencePl == nil || editEs.SentenceEn == nil {
+		return nil, validationError("exampleSentences", "sentencePl and sentenceEn are required for inserting a new example sentence")
 	}
 
-	if editEs.SentenceEn != nil {
-		sentenceEn = *editEs.SentenceEn
-	}
+	var newExampleSentenceID string
+	var newExampleSentenceVersion int
+	sentencePl := *editEs.SentencePl
+	sentenceEn := *editEs.SentenceEn
 
 	err := db.QueryRowContext(ctx,
-		"INSERT INTO example_sentences (sentence_pl, sentence_en, translation_id) VALUES ($1, $2, $3) RETURNING id, version",
-		sentencePl, sentenceEn, translationID).Scan(&newExampleSentenceID, &newExampleSentenceVersion)
+		"INSERT INTO example_sentences (sentence_pl, sentence_en, translation_id, visibility) VALUES ($1, $2, $3, COALESCE($4, (SELECT visibility FROM translations WHERE id = $3))) RETURNING id, version",
+		sentencePl, sentenceEn, translationID, visibilityValue(editEs.Visibility)).Scan(&newExampleSentenceID, &newExampleSentenceVersion)
 
 	if err != nil {
+		return nil, missingReferenceOr(err, "translation", "id", translationID)
+	}
+
+	if len(editEs.RemoveTexts) > 0 {
+		return nil, validationError("removeTexts", "a new example sentence has no texts to remove")
+	}
+	if err := addSentenceTexts(ctx, db, newExampleSentenceID, editEs.Texts); err != nil {
 		return nil, err
 	}
 
 	return &model.ExampleSentence{
-		ID:         newExampleSentenceID,
-		SentencePl: sentencePl,
-		SentenceEn: sentenceEn,
-		Version:    newExampleSentenceVersion,
+		ID:            newExampleSentenceID,
+		SentencePl:    sentencePl,
+		SentenceEn:    sentenceEn,
+		Version:       newExampleSentenceVersion,
+		TranslationID: translationID,
 	}, nil
 }
+
+// checkNestedEditIDs rejects nested edits that name the same entry twice or
+// both edit and remove it.
+func checkNestedEditIDs(editIDs []*string, remove []string, field string) error {
+	edited := make(map[string]bool, len(editIDs))
+	for _, id := range editIDs {
+		if id == nil {
+			continue
+		}
+		if edited[*id] {
+			return validationError(field, fmt.Sprintf("id %s is edited more than once", *id))
+		}
+		edited[*id] = true
+	}
+
+	for _, id := range remove {
+		if edited[id] {
+			return validationError("remove", fmt.Sprintf("id %s cannot be edited and removed in the same update", id))
+		}
+	}
+
+	return nil
+}
+
+// withoutRemoved drops the entries listed in remove. Every removed id has to
+// belong to items, so an update cannot delete children of a different parent.
+func withoutRemoved[T any](items []T, itemID func(T) string, remove []string, entity string) ([]T, error) {
+	toRemove := make(map[string]bool, len(remove))
+	for _, id := range remove {
+		toRemove[id] = true
+	}
+
+	kept := make([]T, 0, len(items))
+	for _, item := range items {
+		if toRemove[itemID(item)] {
+			delete(toRemove, itemID(item))
+			continue
+		}
+		kept = append(kept, item)
+	}
+
+	for _, id := range remove {
+		if toRemove[id] {
+			return nil, &apperror.NotFoundError{Entity: entity, Field: "id", Value: id}
+		}
+	}
+
+	return kept, nil
+}
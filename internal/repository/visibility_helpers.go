@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/apperror"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/auth"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/model"
+	"github.com/lib/pq"
+)
+
+// Public entries are seen by everyone, shared ones by every identified caller
+// and private ones only by their owner, admins included. A private entry lives
+// in its owner's dictionary, so it may have the same text as an entry of the
+// shared dictionary; see migration 0019.
+
+// privateFirst orders the caller's private entry before a shared or public one
+// with the same text, so lookups by text find the caller's own version.
+const privateFirst = "tenant <> '' DESC"
+
+// Access is who owns an entry and who may see it.
+type Access struct {
+	Owner      *string
+	Visibility model.Visibility
+}
+
+// visibleTo restricts a query on lexemes, translations or
+// example_sentences to the entries the caller may see. alias qualifies the
+// columns when the table is aliased. The caller's name is quoted into the
+// condition rather than bound, so queries need not renumber their
+// placeholders.
+func visibleTo(ctx context.Context, alias string) string {
+	if alias != "" {
+		alias += "."
+	}
+
+	principal := auth.PrincipalFrom(ctx)
+	if principal == nil {
+		return alias + "visibility = 'public'"
+	}
+	return fmt.Sprintf("(%svisibility IN ('public', 'shared') OR %sowner = %s)", alias, alias, pq.QuoteLiteral(principal.Name))
+}
+
+// visibilityValue is the stored form of visibility, or nil to leave the
+// choice to the query.
+func visibilityValue(visibility *model.Visibility) any {
+	if visibility == nil {
+		return nil
+	}
+	return strings.ToLower(string(*visibility))
+}
+
+// authorizeVisibilityChange checks that the caller may change who sees the
+// entity with id: its owner may, and so may admins. The entry is locked until
+// the change is written.
+func authorizeVisibilityChange(ctx context.Context, db DBTX, table string, entity string, id string) error {
+	principal := auth.PrincipalFrom(ctx)
+	if principal.HasRole(auth.RoleAdmin) {
+		return nil
+	}
+
+	forbidden := &apperror.ForbiddenError{Action: "change the visibility of " + entity + " " + id}
+	if principal == nil {
+		return forbidden
+	}
+
+	var owned bool
+	err := db.QueryRowContext(ctx, "SELECT COALESCE(owner = $2, false) FROM "+table+" WHERE id = $1 FOR UPDATE", id, principal.Name).Scan(&owned)
+	if err != nil {
+		return notFoundOr(err, entity, "id", id)
+	}
+	if !owned {
+		return forbidden
+	}
+
+	return nil
+}
+
+func getAccessByIDs(ctx context.Context, db DBTX, table string, ids []string) (map[string]*Access, error) {
+	rows, err := db.QueryContext(ctx, "SELECT id, owner, visibility FROM "+table+" WHERE id = ANY($1::int[])", pq.Array(ids))
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	access := make(map[string]*Access, len(ids))
+	for rows.Next() {
+		var id, visibility string
+		var a Access
+		if err := rows.Scan(&id, &a.Owner, &visibility); err != nil {
+			return nil, dbError(err)
+		}
+		a.Visibility = model.Visibility(strings.ToUpper(visibility))
+		access[id] = &a
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, dbError(err)
+	}
+
+	return access, nil
+}
@@ -1,19 +1,31 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
 	"database/sql"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/joho/godotenv"
 
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/apperror"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/audio"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/audit"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/auth"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/blobstore"
 	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/database"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/exporter"
 	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/generated"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/loaders"
 	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/graph/resolver"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/lemmatizer"
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/pronunciation"
 	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/repository"
 )
 
@@ -30,12 +42,64 @@ func main() {
 
 	fmt.Println("Successfully connected to PostgreSQL")
 
+	ctx := context.Background()
+
+	if len(os.Args) > 1 {
+		// Revisions written by a command are recorded with its name as author.
+		// Entries it creates have no owner, unless a command gives one.
+		ctx = repository.WithAuthor(ctx, os.Args[1])
+
+		switch os.Args[1] {
+		case "migrate":
+			if err := runMigrate(ctx, db, os.Args[2:]); err != nil {
+				log.Fatalf("Migration failed: %v", err)
+			}
+			return
+		case "import":
+			if err := runImport(ctx, db, os.Args[2:]); err != nil {
+				log.Fatalf("Import failed: %v", err)
+			}
+			return
+		case "export":
+			if err := runExport(ctx, db, os.Args[2:]); err != nil {
+				log.Fatalf("Export failed: %v", err)
+			}
+			return
+		case "frequencies":
+			if err := runFrequencies(ctx, db, os.Args[2:]); err != nil {
+				log.Fatalf("Loading frequencies failed: %v", err)
+			}
+			return
+		case "purge":
+			if err := runPurge(ctx, db, os.Args[2:]); err != nil {
+				log.Fatalf("Purge failed: %v", err)
+			}
+			return
+		case "apikey":
+			if err := runAPIKey(ctx, db, os.Args[2:]); err != nil {
+				log.Fatalf("Managing API keys failed: %v", err)
+			}
+			return
+		default:
+			log.Fatalf("Unknown command %q", os.Args[1])
+		}
+	}
+
+	if err := checkMigrations(ctx, db); err != nil {
+		log.Fatalf("Refusing to start: %v", err)
+	}
+
 	startServer(db)
 }
 
 func startServer(db *sql.DB) {
 	port := os.Getenv("PORT")
 
+	dictionary, err := loadLemmatizer()
+	if err != nil {
+		log.Fatalf("Could not load the lemmatizer dictionary: %v", err)
+	}
+
 	exampleSentenceRepo := &repository.ExampleSentenceRepositoryDB{DB: db}
 
 	translationRepo := &repository.TranslationRepositoryDB{
@@ -46,17 +110,183 @@ func startServer(db *sql.DB) {
 	polishWordRepo := &repository.PolishWordRepositoryDB{
 		DB:              db,
 		TranslationRepo: translationRepo,
+		Lemmatizer:      dictionary,
+	}
+
+	importRepo := &repository.ImportRepositoryDB{DB: db}
+
+	// Pronunciations are generated for new words unless GENERATE_PRONUNCIATION=false.
+	if os.Getenv("GENERATE_PRONUNCIATION") != "false" {
+		polishWordRepo.Pronunciation = pronunciation.Rules{}
+		importRepo.Pronunciation = pronunciation.Rules{}
+	}
+
+	searchRepo := &repository.SearchRepositoryDB{
+		DB:                  db,
+		PolishWordRepo:      polishWordRepo,
+		TranslationRepo:     translationRepo,
+		ExampleSentenceRepo: exampleSentenceRepo,
+		Lemmatizer:          dictionary,
+	}
+
+	inflectionRepo := &repository.InflectionRepositoryDB{DB: db}
+
+	exportRepo := &repository.ExportRepositoryDB{DB: db}
+	audioRepo := &repository.AudioRepositoryDB{DB: db}
+	lexemeRepo := &repository.LexemeRepositoryDB{DB: db, TranslationRepo: translationRepo}
+	relationRepo := &repository.RelationRepositoryDB{DB: db}
+	tagRepo := &repository.TagRepositoryDB{DB: db}
+	trashRepo := &repository.TrashRepositoryDB{
+		DB:                  db,
+		PolishWordRepo:      polishWordRepo,
+		TranslationRepo:     translationRepo,
+		ExampleSentenceRepo: exampleSentenceRepo,
+	}
+	auditRepo := &repository.AuditRepositoryDB{DB: db}
+	apiKeyRepo := &repository.APIKeyRepositoryDB{DB: db}
+
+	authenticators, err := newAuthenticators(apiKeyRepo)
+	if err != nil {
+		log.Fatalf("Could not configure authentication: %v", err)
+	}
+
+	audioStore, err := newBlobStore()
+	if err != nil {
+		log.Fatalf("Could not configure the audio store: %v", err)
+	}
+
+	audioRecorder := &audio.Recorder{Store: audioStore, Repo: audioRepo}
+	if maxBytes := os.Getenv("AUDIO_MAX_BYTES"); maxBytes != "" {
+		audioRecorder.MaxSize, err = strconv.ParseInt(maxBytes, 10, 64)
+		if err != nil {
+			log.Fatalf("Invalid AUDIO_MAX_BYTES: %v", err)
+		}
 	}
 
+	audioURLs := &audio.URLSigner{Secret: audioURLSecret()}
+
 	srv := handler.NewDefaultServer(generated.NewExecutableSchema(generated.Config{Resolvers: &resolver.Resolver{
 		PolishWordRepo:      polishWordRepo,
 		TranslationRepo:     translationRepo,
 		ExampleSentenceRepo: exampleSentenceRepo,
-	}}))
+		InflectionRepo:      inflectionRepo,
+		SearchRepo:          searchRepo,
+		ImportRepo:          importRepo,
+		AudioRepo:           audioRepo,
+		LexemeRepo:          lexemeRepo,
+		RelationRepo:        relationRepo,
+		TagRepo:             tagRepo,
+		TrashRepo:           trashRepo,
+		AuditRepo:           auditRepo,
+		AudioRecorder:       audioRecorder,
+		AudioURLs:           audioURLs,
+	}, Directives: resolver.Directives}))
+	srv.SetErrorPresenter(apperror.Presenter)
+	srv.AroundFields((&audit.Recorder{Repo: auditRepo}).AroundFields)
 
 	http.Handle("/", playground.Handler("GraphQL playground", "/query"))
-	http.Handle("/query", srv)
+	http.Handle("/query", auth.Middleware(authenticators, audit.Middleware(withAuthor(
+		loaders.Middleware(polishWordRepo, translationRepo, exampleSentenceRepo, inflectionRepo, audioRepo, lexemeRepo, relationRepo, tagRepo, srv)))))
+	http.Handle("/export", auth.Middleware(authenticators, exporter.Handler(exportRepo)))
+	http.Handle(audio.RoutePrefix, audio.Handler(audioStore, audioURLs))
 
 	log.Printf("connect to http://localhost:%s/ for GraphQL playground", port)
 	log.Fatal(http.ListenAndServe(":"+port, nil))
 }
+
+// withAuthor records the caller as the author of the revisions a request
+// writes and the owner of the entries it creates.
+func withAuthor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p := auth.PrincipalFrom(r.Context()); p != nil {
+			r = r.WithContext(repository.WithOwner(repository.WithAuthor(r.Context(), p.Name), p.Name))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// newAuthenticators accepts API keys and, when a key to verify them is
+// configured, JWTs signed with HS256 or RS256.
+func newAuthenticators(apiKeys auth.APIKeyStore) ([]auth.Authenticator, error) {
+	authenticators := []auth.Authenticator{&auth.APIKeys{Store: apiKeys}}
+
+	jwt := &auth.JWT{Issuer: os.Getenv("JWT_ISSUER"), Audience: os.Getenv("JWT_AUDIENCE")}
+	if path := os.Getenv("JWT_HS256_SECRET_FILE"); path != "" {
+		secret, err := auth.ReadHMACSecret(path)
+		if err != nil {
+			return nil, err
+		}
+		jwt.HMACSecret = secret
+	}
+	if path := os.Getenv("JWT_RS256_PUBLIC_KEY_FILE"); path != "" {
+		key, err := auth.ReadRSAPublicKey(path)
+		if err != nil {
+			return nil, err
+		}
+		jwt.RSAPublicKey = key
+	}
+	if jwt.HMACSecret != nil || jwt.RSAPublicKey != nil {
+		authenticators = append(authenticators, jwt)
+	}
+
+	return authenticators, nil
+}
+
+// loadLemmatizer reads the dictionary named by LEMMATIZER_DICTIONARY, or the
+// bundled sample when it is not set.
+func loadLemmatizer() (*lemmatizer.Dictionary, error) {
+	path := os.Getenv("LEMMATIZER_DICTIONARY")
+	if path == "" {
+		return lemmatizer.Default()
+	}
+
+	dictionary, err := lemmatizer.LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Loaded %d word forms from %s", dictionary.Len(), path)
+	return dictionary, nil
+}
+
+// newBlobStore keeps audio in AUDIO_DIR by default, or in an S3-compatible
+// bucket with AUDIO_STORE=s3.
+func newBlobStore() (blobstore.BlobStore, error) {
+	switch os.Getenv("AUDIO_STORE") {
+	case "", "fs":
+		root := os.Getenv("AUDIO_DIR")
+		if root == "" {
+			root = "data/audio"
+		}
+		return &blobstore.FS{Root: root}, nil
+	case "s3":
+		store := &blobstore.S3{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		}
+		if store.Endpoint == "" || store.Region == "" || store.Bucket == "" {
+			return nil, fmt.Errorf("S3_ENDPOINT, S3_REGION and S3_BUCKET are required with AUDIO_STORE=s3")
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown AUDIO_STORE %q, use fs or s3", os.Getenv("AUDIO_STORE"))
+	}
+}
+
+// audioURLSecret reads the key that signs audio links. Without one a random
+// key is used, so links stop working when the server restarts.
+func audioURLSecret() []byte {
+	if secret := os.Getenv("AUDIO_URL_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+
+	log.Printf("AUDIO_URL_SECRET is not set, audio links will stop working after a restart")
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatalf("Could not generate an audio URL secret: %v", err)
+	}
+	return secret
+}
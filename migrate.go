@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/migrations"
+)
+
+const migrateUsage = "usage: migrate up | down [steps] | status | goto <version>"
+
+func runMigrate(ctx context.Context, db *sql.DB, args []string) error {
+	migrator, err := migrations.New(db)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	var done []migrations.Migration
+
+	switch args[0] {
+	case "up":
+		done, err = migrator.Up(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+		done, err = migrator.Down(ctx, steps)
+	case "goto":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		version, convErr := strconv.Atoi(args[1])
+		if convErr != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		done, err = migrator.Goto(ctx, version)
+	case "status":
+		return printMigrationStatus(ctx, migrator)
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	for _, migration := range done {
+		fmt.Printf("migrated %04d_%s\n", migration.Version, migration.Name)
+	}
+	if err == nil && len(done) == 0 {
+		fmt.Println("nothing to migrate")
+	}
+
+	return err
+}
+
+func printMigrationStatus(ctx context.Context, migrator *migrations.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+	}
+
+	return w.Flush()
+}
+
+// checkMigrations refuses to start the server on a database that is missing
+// migrations, when REQUIRE_MIGRATIONS is set to true.
+func checkMigrations(ctx context.Context, db *sql.DB) error {
+	if os.Getenv("REQUIRE_MIGRATIONS") != "true" {
+		return nil
+	}
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		return err
+	}
+
+	pending, err := migrator.Pending(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(pending) > 0 {
+		return fmt.Errorf("database is %d migration(s) behind, run \"migrate up\" first", len(pending))
+	}
+
+	return nil
+}
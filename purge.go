@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/grzegorzpapaj/graphql-dictionary-api/internal/repository"
+)
+
+func runPurge(ctx context.Context, db *sql.DB, args []string) error {
+	flags := flag.NewFlagSet("purge", flag.ContinueOnError)
+	olderThan := flags.Duration("older-than", 30*24*time.Hour, "remove entries that have been in the trash for longer than this")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 || *olderThan < 0 {
+		return errors.New("usage: purge [-older-than 720h]")
+	}
+
+	report, err := (&repository.TrashRepositoryDB{DB: db}).Purge(ctx, time.Now().Add(-*olderThan))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("purged %d polish words, %d translations and %d example sentences\n",
+		report.PolishWords, report.Translations, report.ExampleSentences)
+	return nil
+}